@@ -30,3 +30,12 @@ if [ ! -d "$PDF_DEST" ]; then
 fi
 
 cp -r "$PDF_DIR"/. "$PDF_DEST"/
+
+TPL_DIR="./server/problem_generator/templates"
+TPL_DEST="./build/server/problem_generator/templates"
+
+if [ ! -d "$TPL_DEST" ]; then
+    mkdir -p "$TPL_DEST"
+fi
+
+cp -r "$TPL_DIR"/. "$TPL_DEST"/
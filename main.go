@@ -7,6 +7,8 @@ import (
 	"net"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strings"
 	"syscall"
 	"time"
 
@@ -25,6 +27,18 @@ var (
 	ollama   = flag.String("ollama_url", "http://localhost:11434", "base URL of Ollama API")
 	model    = flag.String("model", "gemma3n:e4b", "model name to pass to Ollama")
 	webPort  = flag.String("web_port", ":8081", "port for Gin web UI")
+
+	templatesFile = flag.String("templates", "server/problem_generator/templates/templates.csv", "CSV of mad-lib problem templates")
+	templatesDB   = flag.String("templates_db", "", "SQLite database of templates, seeded from -templates when empty")
+	offline       = flag.Bool("offline", false, "build arithmetic problems from templates without Ollama")
+	hybrid        = flag.Bool("hybrid", false, "plan the math on the server and have the model only write the stories")
+	explain       = flag.Bool("explain", false, "have the model write a kid-friendly explanation of each worked solution in the answer key")
+	safetyFile    = flag.String("safety", "", "JSON file of extra blocked words, allowed phrases and scenario rules for the safety filter")
+	safetyLog     = flag.String("safety_log", "", "JSON-lines file rejected problems are appended to (default <out_dir>/rejections.jsonl)")
+	classifier    = flag.Bool("safety_classifier", false, "also ask the model whether each story is safe for a child")
+	themesFile    = flag.String("themes", "", "CSV theme dictionary (theme,emoji,words) to use instead of the built-in one")
+	history       = flag.Int("history", 50, "recent problems remembered per student so new worksheets do not repeat them; 0 disables")
+	agentName     = flag.String("agent", "json", "agent used when a request names none: json, csv or templates")
 )
 
 func main() {
@@ -43,10 +57,50 @@ func main() {
 		log.Fatalf("mkdir %s: %v", *outDir, err)
 	}
 
-	// agent := pg.NewCSVAgent()
-	agent := pg.NewJSONAgent()
+	fallback, err := loadTemplates(ctx)
+	if err != nil {
+		if *offline {
+			log.Fatalf("offline mode needs templates: %v", err)
+		}
+		log.Printf("templates unavailable, no offline fallback: %v", err)
+	}
 
-	svc := grpcSrv.NewServer(*ollama, *model, agent)
+	agents := grpcSrv.DefaultAgents(fallback)
+	if err := agents.SetDefault(*agentName); err != nil {
+		log.Fatalf("-agent: %v", err)
+	}
+	log.Printf("agents: %s (default %s)", strings.Join(agents.Names(), ", "), *agentName)
+
+	if *themesFile != "" {
+		themes, err := pg.LoadThemes(*themesFile)
+		if err != nil {
+			log.Fatalf("themes: %v", err)
+		}
+		pg.SetThemes(themes)
+	}
+
+	safety, rejections, err := loadSafety()
+	if err != nil {
+		log.Fatalf("safety filter: %v", err)
+	}
+
+	provenance, err := pg.NewProvenanceStore(filepath.Join(*outDir, "provenance"))
+	if err != nil {
+		log.Fatalf("provenance: %v", err)
+	}
+
+	svc := grpcSrv.NewServer(*ollama, *model, agents, grpcSrv.Options{
+		Fallback: fallback,
+		Offline:  *offline,
+		Hybrid:   *hybrid,
+		Explain:  *explain,
+		History:  pg.NewHistory(*history),
+
+		Safety:     safety,
+		Classifier: *classifier,
+		Rejections: rejections,
+		Provenance: provenance,
+	})
 
 	grpcServer := grpc.NewServer()
 	pb.RegisterGeneratorServer(grpcServer, svc)
@@ -85,3 +139,51 @@ func main() {
 	grpcServer.GracefulStop() // GracefulStop is blocking until all RPCs finish or timeout
 	log.Println("✅ Servers shut down.")
 }
+
+// loadTemplates reads the mad-lib templates used offline and as the Ollama
+// fallback, from SQLite when -templates_db is set and from the CSV otherwise.
+func loadTemplates(ctx context.Context) (*pg.TemplateAgent, error) {
+	file, err := pg.NewFileTemplateRepo(*templatesFile)
+	if err != nil && *templatesDB == "" {
+		return nil, err
+	}
+	if *templatesDB == "" {
+		return pg.NewTemplateAgent(file), nil
+	}
+
+	db, dbErr := pg.OpenSQLiteTemplateRepo(*templatesDB)
+	if dbErr != nil {
+		return nil, dbErr
+	}
+	if err == nil {
+		if err := db.Seed(ctx, file.All()); err != nil {
+			return nil, err
+		}
+	}
+	return pg.NewTemplateAgent(db), nil
+}
+
+// loadSafety builds the safety filter, with the -safety rules added to the
+// defaults, and opens the log rejected problems are written to.
+func loadSafety() (*pg.SafetyFilter, *pg.RejectionLog, error) {
+	config := pg.DefaultSafetyConfig()
+	if *safetyFile != "" {
+		var err error
+		if config, err = pg.LoadSafetyConfig(*safetyFile); err != nil {
+			return nil, nil, err
+		}
+	}
+	filter, err := pg.NewSafetyFilter(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	path := *safetyLog
+	if path == "" {
+		path = filepath.Join(*outDir, "rejections.jsonl")
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, nil, err
+	}
+	return filter, pg.NewRejectionLog(f), nil
+}
@@ -0,0 +1,128 @@
+package grpcsrv
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	pg "github.com/qjs/mathgen_gemma/server/problem_generator"
+	"github.com/qjs/mathgen_gemma/server/prompts"
+	pb "github.com/qjs/mathgen_gemma/server/proto"
+)
+
+// Agent is one way of turning a request into problems: a parser paired with
+// the prompt style whose replies it reads, or a generator that needs no
+// model at all. Agents are looked up by name in a Registry.
+type Agent struct {
+	Name string
+
+	Parser pg.Agent                                    // reads the model's reply
+	Style  func(req *pb.GenerateRequest) prompts.Style // the prompt Parser reads replies to
+
+	// Generate builds the set without the model, as templates do; when set,
+	// Parser and Style are not used.
+	Generate func(ctx context.Context, req *pb.GenerateRequest) (*pg.ProblemSet, error)
+
+	Kinds []string // the problem kinds it can make; empty for all
+}
+
+// Supports reports whether a can make problems of kind. Kinds the server
+// builds itself (equations, patterns, …) never reach an agent.
+func (a Agent) Supports(kind string) bool {
+	return len(a.Kinds) == 0 || pg.IsLocalKind(kind) || slices.Contains(a.Kinds, pg.NormalizeKind(kind))
+}
+
+// Registry holds the agents a server can use and the one it uses when a
+// request names none.
+type Registry struct {
+	agents map[string]Agent
+	names  []string
+	def    string
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{agents: map[string]Agent{}}
+}
+
+// Register adds a. The first agent registered is the default until
+// SetDefault picks another.
+func (r *Registry) Register(a Agent) error {
+	name := strings.ToLower(strings.TrimSpace(a.Name))
+	if name == "" {
+		return fmt.Errorf("agent has no name")
+	}
+	if _, dup := r.agents[name]; dup {
+		return fmt.Errorf("agent %q is already registered", name)
+	}
+	if a.Generate == nil && (a.Parser == nil || a.Style == nil) {
+		return fmt.Errorf("agent %q needs a parser and prompt style, or a generator", name)
+	}
+	a.Name = name
+	r.agents[name] = a
+	r.names = append(r.names, name)
+	if r.def == "" {
+		r.def = name
+	}
+	return nil
+}
+
+// Get returns the agent called name, or the default one for "".
+func (r *Registry) Get(name string) (Agent, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		name = r.def
+	}
+	a, ok := r.agents[name]
+	if !ok {
+		return Agent{}, fmt.Errorf("unknown agent %q: want one of %s", name, strings.Join(r.names, ", "))
+	}
+	return a, nil
+}
+
+// SetDefault makes the agent called name the one used when a request names
+// none.
+func (r *Registry) SetDefault(name string) error {
+	a, err := r.Get(name)
+	if err != nil {
+		return err
+	}
+	r.def = a.Name
+	return nil
+}
+
+// Names lists the registered agents in the order they were added.
+func (r *Registry) Names() []string { return slices.Clone(r.names) }
+
+// DefaultAgents registers the built-in agents: "json" (the default), "csv"
+// and, when templates are loaded, "templates".
+func DefaultAgents(templates *pg.TemplateAgent) *Registry {
+	r := NewRegistry()
+	agents := []Agent{
+		{ // the model writes JSON, with a prompt for every story kind
+			Name:   "json",
+			Parser: pg.NewJSONAgent(),
+			Style:  styleFor,
+		},
+		{ // the model writes CSV rows with the numbers in their own columns
+			Name:   "csv",
+			Parser: pg.NewCSVAgent(),
+			Style:  func(*pb.GenerateRequest) prompts.Style { return prompts.StyleProblemset },
+			Kinds:  []string{pg.KindArithmetic},
+		},
+	}
+	if templates != nil {
+		agents = append(agents, Agent{ // mad-lib templates filled in on the server, no model
+			Name:     "templates",
+			Generate: templates.Generate,
+			Kinds:    []string{pg.KindArithmetic},
+		})
+	}
+	for _, a := range agents {
+		if err := r.Register(a); err != nil {
+			panic(err) // the built-in agents are well formed
+		}
+	}
+	return r
+}
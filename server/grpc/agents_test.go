@@ -0,0 +1,79 @@
+package grpcsrv
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	pg "github.com/qjs/mathgen_gemma/server/problem_generator"
+	pb "github.com/qjs/mathgen_gemma/server/proto"
+)
+
+func TestRegistry(t *testing.T) {
+	generate := func(context.Context, *pb.GenerateRequest) (*pg.ProblemSet, error) { return &pg.ProblemSet{}, nil }
+	r := NewRegistry()
+	if _, err := r.Get(""); err == nil {
+		t.Error("Get on an empty registry succeeded")
+	}
+
+	tests := []struct {
+		agent Agent
+		err   bool
+	}{
+		{agent: Agent{Name: " Offline ", Generate: generate}},
+		{agent: Agent{Name: "json", Parser: pg.NewJSONAgent(), Style: styleFor}},
+		{agent: Agent{Name: "OFFLINE", Generate: generate}, err: true}, // already registered
+		{agent: Agent{Generate: generate}, err: true},                  // no name
+		{agent: Agent{Name: "half", Parser: pg.NewJSONAgent()}, err: true},
+	}
+	for _, tt := range tests {
+		if err := r.Register(tt.agent); (err != nil) != tt.err {
+			t.Errorf("Register(%q) error = %v, want error %v", tt.agent.Name, err, tt.err)
+		}
+	}
+	if got, want := r.Names(), []string{"offline", "json"}; !slices.Equal(got, want) {
+		t.Errorf("Names = %q, want %q", got, want)
+	}
+
+	if a, err := r.Get(""); err != nil || a.Name != "offline" {
+		t.Errorf("Get(\"\") = %q, %v; want the first agent registered", a.Name, err)
+	}
+	if err := r.SetDefault("JSON"); err != nil {
+		t.Fatal(err)
+	}
+	if a, err := r.Get(""); err != nil || a.Name != "json" {
+		t.Errorf("Get(\"\") after SetDefault = %q, %v; want json", a.Name, err)
+	}
+	if _, err := r.Get("gpt"); err == nil {
+		t.Error("Get(gpt) succeeded")
+	}
+	if err := r.SetDefault("gpt"); err == nil {
+		t.Error("SetDefault(gpt) succeeded")
+	}
+}
+
+func TestAgentSupports(t *testing.T) {
+	r := DefaultAgents(nil)
+	if got, want := r.Names(), []string{"json", "csv"}; !slices.Equal(got, want) {
+		t.Fatalf("DefaultAgents(nil) = %q, want %q", got, want)
+	}
+	tests := []struct {
+		agent, kind string
+		want        bool
+	}{
+		{"json", pg.KindComparison, true},
+		{"csv", pg.KindArithmetic, true},
+		{"csv", "", true},
+		{"csv", pg.KindMeasurement, false},
+		{"csv", pg.KindPattern, true}, // built on the server
+	}
+	for _, tt := range tests {
+		a, err := r.Get(tt.agent)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := a.Supports(tt.kind); got != tt.want {
+			t.Errorf("%s.Supports(%q) = %v, want %v", tt.agent, tt.kind, got, tt.want)
+		}
+	}
+}
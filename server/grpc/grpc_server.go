@@ -3,11 +3,13 @@ package grpcsrv
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"net/url"
 	"os"
+	"slices"
 	"time"
 
 	pdfgenerator "github.com/qjs/mathgen_gemma/server/pdf_generator"
@@ -19,6 +21,7 @@ import (
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 )
 
 // Server implements the Generator gRPC service using the Ollama Go SDK.
@@ -26,10 +29,30 @@ type Server struct {
 	pb.UnimplementedGeneratorServer
 	client *api.Client
 	model  string
-	agent  pg.Agent
+	agents *Registry
+	opts   Options
 }
 
-func NewServer(ollamaBaseURL, model string, agent pg.Agent) *Server {
+// Options are the optional ways a Server can generate problems.
+type Options struct {
+	Fallback *pg.TemplateAgent // used when Ollama fails; nil disables it
+	Offline  bool              // skip Ollama and always use Fallback
+	Hybrid   bool              // plan the math first and have the model only write stories, whatever the agent
+	Explain  bool              // have the model explain each worked solution for the answer key
+
+	Validators pg.Chain    // checks every set; nil means pg.DefaultChain
+	History    *pg.History // recent problems per student to avoid repeating; nil disables it
+
+	Safety     *pg.SafetyFilter // screens every problem; nil means pg.DefaultSafetyFilter
+	Classifier bool             // also ask the model whether each story is safe
+	Rejections *pg.RejectionLog // where rejected problems are recorded; nil only logs them
+
+	Provenance *pg.ProvenanceStore // where every set's provenance record is kept; nil keeps none
+}
+
+// NewServer returns a server that asks the model at ollamaBaseURL. Requests
+// pick one of agents by name; nil means DefaultAgents.
+func NewServer(ollamaBaseURL, model string, agents *Registry, opts Options) *Server {
 	base, err := url.Parse(ollamaBaseURL)
 	if err != nil {
 		log.Fatalf("invalid Ollama URL: %v", err)
@@ -40,22 +63,209 @@ func NewServer(ollamaBaseURL, model string, agent pg.Agent) *Server {
 	}
 
 	client := api.NewClient(base, httpClient)
+	if agents == nil {
+		agents = DefaultAgents(opts.Fallback)
+	}
+	if opts.Validators == nil {
+		opts.Validators = pg.DefaultChain()
+	}
+	if opts.Safety == nil {
+		opts.Safety = pg.DefaultSafetyFilter()
+	}
+	opts.Validators = opts.Validators.Replace(pg.SafetyValidator{Filter: opts.Safety})
 
 	return &Server{
 		client: client,
 		model:  model,
-		agent:  agent,
+		agents: agents,
+		opts:   opts,
 	}
 }
 
+// maxAttempts is how many times a set with validation errors is generated
+// before it is returned with its issues as is.
+const maxAttempts = 3
+
 // GenerateProblemSet queries Ollama for a JSON-formatted problem set and converts it to protobuf.
+// Every set goes through the validation chain and is regenerated while it
+// has errors. How the set was made is kept as its provenance record.
 func (s *Server) GenerateProblemSet(ctx context.Context, req *pb.GenerateRequest) (*pb.ProblemSet, error) {
+	rec := s.newProvenance("generate", req)
+	ps, err := s.generateSet(withProvenance(ctx, rec), req)
+	s.saveProvenance(rec, ps, err)
+	if err != nil {
+		return nil, err
+	}
+	return convertFromInternal(ps), nil
+}
+
+// generateSet makes, checks and finishes the set for req.
+func (s *Server) generateSet(ctx context.Context, req *pb.GenerateRequest) (*pg.ProblemSet, error) {
+	req = proto.Clone(req).(*pb.GenerateRequest)
+	if err := pg.ResolveOperations(req); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "operations: %v", err)
+	}
+	if err := pg.ResolveRegrouping(req); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "regrouping: %v", err)
+	}
+	if err := pg.ResolveLanguage(req); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "language: %v", err)
+	}
+	agent, err := s.agents.Get(req.Agent)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "agent: %v", err)
+	}
+	if !agent.Supports(req.Kind) {
+		return nil, status.Errorf(codes.InvalidArgument, "agent: %s cannot make %s problems", agent.Name, pg.NormalizeKind(req.Kind))
+	}
+	req.Agent = agent.Name // the set records which agent made it, for comparing them
+	if rec := provenanceOf(ctx); rec != nil {
+		rec.Options.Agent = agent.Name
+	}
+	var ps *pg.ProblemSet
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		var err error
+		if ps, err = s.generate(ctx, req); err != nil {
+			return nil, err
+		}
+		s.dedupe(ctx, req, ps)
+		s.screen(ctx, req, ps)
+		pg.Personalize(ps)
+		pg.NormalizeThemes(ps)
+		pg.ScoreReadability(ps)
+		pg.ScoreDifficulty(ps)
+		unsimplified := ps.Issues // left by generate; Validate replaces them
+		issues := append(s.opts.Validators.Validate(ps), unsimplified...)
+		ps.Issues = issues
+		provenanceOf(ctx).AddAttempt(attempt, issues)
+		if !pg.HasErrors(issues) {
+			break
+		}
+		for _, i := range issues {
+			log.Printf("attempt %d: %v", attempt, i)
+		}
+	}
+	pg.Solve(ps)
+	ps.Issues = append(ps.Issues, s.explain(ctx, ps)...)
+	pg.AddHints(ps)
+	provenanceOf(ctx).Renumber(pg.Arrange(ps, nil))
+	ps.Diversity = pg.Diversity(ps)
+	ps.Coverage = pg.InterestCoverage(ps)
+	s.opts.History.Add(ps)
+	return ps, nil
+}
+
+// dedupe replaces problems that repeat another one in ps, or one the
+// student was given recently, with problems from freshly generated sets.
+func (s *Server) dedupe(ctx context.Context, req *pb.GenerateRequest, ps *pg.ProblemSet) {
+	recent := s.opts.History.Recent(req.Name)
+	dups := pg.FindDuplicates(ps, recent)
+	for attempt := 1; attempt < maxAttempts && len(dups) > 0; attempt++ {
+		fresh, err := s.generate(ctx, req)
+		if err != nil {
+			log.Printf("dedupe: %v", err)
+			return
+		}
+		dups = pg.ReplaceDuplicates(ps, fresh, recent)
+	}
+	for _, d := range dups {
+		log.Printf("dedupe: %v", d)
+	}
+}
+
+// screen replaces every problem that fails the safety filter, or the
+// model's own safety check, with one from a freshly generated set. Problems
+// that cannot be replaced stay for the SafetyValidator to report.
+func (s *Server) screen(ctx context.Context, req *pb.GenerateRequest, ps *pg.ProblemSet) {
+	var rejected []int
+	for _, p := range ps.Problems {
+		if !s.safe(ctx, ps.MetaInfo, p) {
+			rejected = append(rejected, p.Index)
+		}
+	}
+	for attempt := 1; attempt < maxAttempts && len(rejected) > 0; attempt++ {
+		fresh, err := s.generate(ctx, req)
+		if err != nil {
+			log.Printf("screen: %v", err)
+			return
+		}
+		rejected = pg.ReplaceProblems(ps, fresh, rejected, func(p pg.Problem) bool {
+			return s.safe(ctx, ps.MetaInfo, p)
+		})
+	}
+}
+
+// safe checks p with the safety filter and, when enabled, the model, and
+// records why it was rejected.
+func (s *Server) safe(ctx context.Context, meta pg.GenerateRequest, p pg.Problem) bool {
+	source, reason := "filter", s.opts.Safety.CheckProblem(p, meta)
+	if reason == "" && s.opts.Classifier && !s.opts.Offline && !pg.IsLocalKind(p.Kind) {
+		out, err := s.chat(ctx, "safety", prompts.SafetyCheck(p.Text, meta.GradeLevel))
+		if err != nil {
+			log.Printf("problem %d: safety check: %v", p.Index, err)
+			return true
+		}
+		source, reason = "classifier", pg.ParseSafetyVerdict(out)
+	}
+	if reason == "" {
+		return true
+	}
+	log.Printf("problem %d rejected by %s: %s", p.Index, source, reason)
+	if err := s.opts.Rejections.Log(pg.Rejection{
+		Time:    time.Now(),
+		Student: meta.Name,
+		Grade:   meta.GradeLevel,
+		Index:   p.Index,
+		Text:    p.Text,
+		Source:  source,
+		Reason:  reason,
+	}); err != nil {
+		log.Printf("rejection log: %v", err)
+	}
+	return false
+}
+
+// generate makes one problem set for req, without validating it. The set's
+// Issues are only those of stories the model could not be asked to simplify.
+func (s *Server) generate(ctx context.Context, req *pb.GenerateRequest) (*pg.ProblemSet, error) {
+	// Some kinds (bare equations, …) are built entirely by the server.
+	if pg.IsLocalKind(req.Kind) {
+		ps, err := pg.GenerateLocal(req, nil)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "generate: %v", err)
+		}
+		return ps, nil
+	}
+	if s.opts.Offline && s.opts.Fallback != nil {
+		return s.fromTemplates(ctx, req)
+	}
+	agent, err := s.agents.Get(req.Agent)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "agent: %v", err)
+	}
+	if agent.Generate != nil {
+		ps, err := agent.Generate(ctx, req)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "%s: %v", agent.Name, err)
+		}
+		return ps, nil
+	}
+
 	//------------------------------------------------------------------
 	// 1. Build the prompt with our style
 	//------------------------------------------------------------------
 	pbldr := prompts.Builder{
-		Style: prompts.StyleProblemsetJSON,
-		Model: s.model,
+		Style:     agent.Style(req),
+		Model:     s.model,
+		Interests: pg.AssignInterests(req),
+	}
+	var plans []pg.Plan
+	if s.hybrid(req) {
+		var err error
+		if plans, err = pg.PlanProblems(req, nil); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "plan: %v", err)
+		}
+		pbldr.Style, pbldr.Plans = prompts.StyleHybridJSON, plans
 	}
 	prompt, err := pbldr.Build(req)
 	if err != nil {
@@ -65,6 +275,97 @@ func (s *Server) GenerateProblemSet(ctx context.Context, req *pb.GenerateRequest
 	//------------------------------------------------------------------
 	// 2. Ask Ollama
 	//------------------------------------------------------------------
+	responseText, err := s.chat(ctx, "generate", prompt)
+	if err != nil {
+		if s.opts.Fallback != nil && pg.NormalizeKind(req.Kind) == pg.KindArithmetic {
+			log.Printf("ollama unavailable (%v), using templates", err)
+			return s.fromTemplates(ctx, req)
+		}
+		return nil, status.Errorf(codes.Internal, "ollama resp: %v", err)
+	}
+	ps, err := parse(agent, responseText, plans, req)
+	if err != nil {
+		provenanceOf(ctx).ParseFailed(err)
+		if ps == nil {
+			return nil, status.Errorf(codes.Internal, "parse LLM output: %v", err)
+		}
+		// Some problems could be read: ask again for just the others.
+		var failed pg.ParseErrors
+		errors.As(err, &failed)
+		log.Printf("parse LLM output: %v", err)
+		if err := s.regenerate(ctx, req, agent, pbldr, plans, ps, failed.Indexes()); err != nil {
+			return nil, err
+		}
+	}
+	pg.ApplyInterests(ps, pbldr.Interests)
+	ps.Issues = s.simplify(ctx, ps)
+	return ps, nil
+}
+
+// parse reads the model's reply with the agent's parser or, in hybrid mode,
+// against the plans it was written for.
+func parse(agent Agent, out string, plans []pg.Plan, req *pb.GenerateRequest) (*pg.ProblemSet, error) {
+	if plans != nil {
+		return pg.ApplyPlans(out, plans, req)
+	}
+	return agent.Parser.Parse(out, req)
+}
+
+// regenerate asks the model again for just the missing problems of ps,
+// showing it the ones already written so it does not repeat them, and adds
+// those it writes. It fails when some are still missing after maxAttempts.
+func (s *Server) regenerate(ctx context.Context, req *pb.GenerateRequest, agent Agent, pbldr prompts.Builder, plans []pg.Plan, ps *pg.ProblemSet, missing []int) error {
+	for attempt := 1; attempt < maxAttempts && len(missing) > 0; attempt++ {
+		b := pbldr
+		b.Only, b.Written = missing, nil
+		for _, p := range ps.Problems {
+			b.Written = append(b.Written, p.Text)
+		}
+		if plans != nil { // the hybrid prompt lists just the missing plans
+			b.Plans = nil
+			for _, p := range plans {
+				if slices.Contains(missing, p.Index) {
+					b.Plans = append(b.Plans, p)
+				}
+			}
+		}
+		prompt, err := b.Build(req)
+		if err != nil {
+			return status.Errorf(codes.Internal, "prompt build: %v", err)
+		}
+		out, err := s.chat(ctx, "regenerate", prompt)
+		if err != nil {
+			return status.Errorf(codes.Internal, "ollama resp: %v", err)
+		}
+		fresh, err := parse(agent, out, b.Plans, req)
+		var failed pg.ParseErrors
+		if errors.As(err, &failed) {
+			// Only the missing slots were asked for; the reply lacking
+			// the others is no news.
+			if failed = failed.Only(missing); len(failed) == 0 {
+				err = nil
+			} else {
+				err = failed
+			}
+		}
+		if err != nil {
+			provenanceOf(ctx).ParseFailed(err)
+			log.Printf("regenerate %v: %v", missing, err)
+		}
+		if fresh != nil {
+			missing = pg.FillSlots(ps, fresh, missing)
+		}
+	}
+	if len(missing) > 0 {
+		return status.Errorf(codes.Internal, "parse LLM output: problems %v could not be read", missing)
+	}
+	return nil
+}
+
+// chat sends prompt to Ollama and returns the whole reply. The call is
+// added to the provenance record in ctx, with purpose saying what it was
+// for.
+func (s *Server) chat(ctx context.Context, purpose string, prompt prompts.Prompt) (string, error) {
 	stream := false
 	// format := json.RawMessage(`"text"`)
 	cReq := &api.ChatRequest{
@@ -78,25 +379,119 @@ func (s *Server) GenerateProblemSet(ctx context.Context, req *pb.GenerateRequest
 	}
 
 	var responseText string
-	err = s.client.Chat(ctx, cReq, func(cr api.ChatResponse) error {
+	start := time.Now()
+	err := s.client.Chat(ctx, cReq, func(cr api.ChatResponse) error {
 		responseText += cr.Message.Content
 		return nil
 	})
+	call := pg.ModelCall{Purpose: purpose, System: prompt.System, User: prompt.User, Response: responseText, Duration: time.Since(start)}
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "ollama resp: %v", err)
+		call.Error = err.Error()
+	}
+	provenanceOf(ctx).AddCall(call)
+	return responseText, err
+}
+
+// simplify asks the model to rewrite every story that is too hard to read
+// for the student's grade. A rewrite is kept only if it has the same numbers
+// and reads easier, so the answer key stays right; otherwise the original
+// story stays and the validators warn about it. A story the model could not
+// be asked about also stays, with an issue saying so.
+func (s *Server) simplify(ctx context.Context, ps *pg.ProblemSet) []pg.Issue {
+	var issues []pg.Issue
+	for i, p := range ps.Problems {
+		r, hard := pg.NeedsSimplifying(p, ps.MetaInfo)
+		if !hard {
+			continue
+		}
+		out, err := s.chat(ctx, "simplify", prompts.Simplify(p.Text, ps.MetaInfo.GradeLevel, r.HardWords))
+		if err != nil {
+			log.Printf("problem %d: simplify: %v", p.Index, err)
+			issues = append(issues, pg.Issue{Index: p.Index, Check: "simplify", Severity: pg.SeverityWarning,
+				Message: fmt.Sprintf("kept the original story: %v", err)})
+			continue
+		}
+		text, err := pg.AcceptRewrite(p, out, ps.MetaInfo)
+		if err != nil {
+			provenanceOf(ctx).ParseFailed(err)
+			log.Printf("problem %d: %v", p.Index, err)
+			continue
+		}
+		ps.Problems[i].Text = text
+	}
+	return issues
+}
+
+// explain has the model explain each worked solution of ps to a child,
+// when enabled. An explanation that strays from the worked numbers is
+// dropped; the steps are enough on their own. A problem the model could not
+// be asked about gets an issue saying so, and the rest are still explained.
+func (s *Server) explain(ctx context.Context, ps *pg.ProblemSet) []pg.Issue {
+	if !s.opts.Explain || s.opts.Offline {
+		return nil
+	}
+	var issues []pg.Issue
+	for i, p := range ps.Problems {
+		if p.Solution == nil {
+			continue
+		}
+		out, err := s.chat(ctx, "explain", prompts.Explain(p, ps.MetaInfo.GradeLevel, ps.MetaInfo.Language))
+		if err != nil {
+			log.Printf("problem %d: explain: %v", p.Index, err)
+			issues = append(issues, pg.Issue{Index: p.Index, Check: "explain", Severity: pg.SeverityWarning,
+				Message: fmt.Sprintf("no explanation: %v", err)})
+			continue
+		}
+		text, err := pg.AcceptExplanation(p, out, ps.MetaInfo.Language)
+		if err != nil {
+			provenanceOf(ctx).ParseFailed(err)
+			log.Printf("problem %d: %v", p.Index, err)
+			continue
+		}
+		ps.Problems[i].Solution.Explanation = text
 	}
-	fmt.Printf("%s\n", responseText)
-	ps, err := s.agent.Parse(responseText, req)
+	return issues
+}
+
+// fromTemplates builds the problem set from mad-lib templates, no model needed.
+// There are templates only for arithmetic.
+func (s *Server) fromTemplates(ctx context.Context, req *pb.GenerateRequest) (*pg.ProblemSet, error) {
+	if kind := pg.NormalizeKind(req.Kind); kind != pg.KindArithmetic {
+		return nil, status.Errorf(codes.FailedPrecondition, "without the model only arithmetic can be made from templates, not %s problems", kind)
+	}
+	ps, err := s.opts.Fallback.Generate(ctx, req)
 	if err != nil {
-		fmt.Printf("failed to parse\n")
+		return nil, status.Errorf(codes.Internal, "templates: %v", err)
+	}
+	return ps, nil
+}
 
-		return nil, status.Errorf(codes.Internal, "parse LLM output: %v", err)
+// hybrid reports whether req is planned by the server before the model
+// writes the stories. Only arithmetic and missing-number stories have plans.
+func (s *Server) hybrid(req *pb.GenerateRequest) bool {
+	kind := pg.NormalizeKind(req.Kind)
+	return s.opts.Hybrid && (kind == pg.KindArithmetic || kind == pg.KindMissing)
+}
+
+// styleFor picks the prompt style matching the request's problem kind.
+func styleFor(req *pb.GenerateRequest) prompts.Style {
+	switch pg.NormalizeKind(req.Kind) {
+	case pg.KindMeasurement:
+		return prompts.StyleMeasurementJSON
+	case pg.KindComparison:
+		return prompts.StyleComparisonJSON
+	case pg.KindMissing:
+		return prompts.StyleMissingNumberJSON
+	default:
+		return prompts.StyleProblemsetJSON
 	}
-	return convertFromInternal(ps), nil
 }
 
 // GenerateProblemSetPDF renders PDF from a protobuf ProblemSet.
 func (s *Server) GenerateProblemSetPDF(ctx context.Context, psReq *pb.ProblemSet) (*pb.PDFResponse, error) {
+	if err := checkSet(psReq); err != nil {
+		return nil, err
+	}
 	tmp, err := os.CreateTemp("", "problem_set_*.pdf")
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "tmp file: %v", err)
@@ -114,7 +509,94 @@ func (s *Server) GenerateProblemSetPDF(ctx context.Context, psReq *pb.ProblemSet
 	return &pb.PDFResponse{Pdf: data, Filename: "problem_set.pdf"}, nil
 }
 
+// TranslateProblemSet has the model translate the theme and text of every
+// problem of a set into another language. Each translation must keep the
+// problem's numbers, names and question, and is retried until it does; the
+// answers are then worked out again and the set validated. Problems that
+// could not be translated keep their text and are reported as issues.
+func (s *Server) TranslateProblemSet(ctx context.Context, req *pb.TranslateRequest) (*pb.ProblemSet, error) {
+	rec := s.newProvenance("translate", req)
+	if rec != nil {
+		rec.Options.Language = req.Language
+	}
+	ps, err := s.translateSet(withProvenance(ctx, rec), req)
+	s.saveProvenance(rec, ps, err)
+	if err != nil {
+		return nil, err
+	}
+	return convertFromInternal(ps), nil
+}
+
+// translateSet translates and checks the set of req.
+func (s *Server) translateSet(ctx context.Context, req *pb.TranslateRequest) (*pg.ProblemSet, error) {
+	if req.ProblemSet == nil || len(req.ProblemSet.Problems) == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "no problem set to translate")
+	}
+	if err := checkSet(req.ProblemSet); err != nil {
+		return nil, err
+	}
+	lang, err := pg.ParseLanguage(req.Language)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "language: %v", err)
+	}
+	if s.opts.Offline {
+		return nil, status.Errorf(codes.FailedPrecondition, "translation needs the model, which is off in offline mode")
+	}
+	src := convertToInternal(req.ProblemSet)
+	if from := pg.NormalizeLanguage(src.MetaInfo.Language); from == lang.Tag {
+		return nil, status.Errorf(codes.InvalidArgument, "the set is already in %s", lang.Name)
+	}
+	ps := pg.Translate(src, lang.Tag, req.DualLanguage)
+	var failed []pg.Issue
+	for i, p := range src.Problems {
+		if pg.IsLocalKind(p.Kind) { // built by the server, not the model: kept as built
+			continue
+		}
+		var err error
+		for attempt := 1; attempt <= maxAttempts; attempt++ {
+			var out string
+			if out, err = s.chat(ctx, "translate", prompts.Translate(p, src.MetaInfo.Language, lang.Tag)); err != nil {
+				return nil, status.Errorf(codes.Internal, "ollama resp: %v", err)
+			}
+			var theme, text string
+			if theme, text, err = pg.AcceptTranslation(p, out, src.MetaInfo, lang.Tag); err == nil {
+				ps.Problems[i].Theme, ps.Problems[i].Text = theme, text
+				break
+			}
+			provenanceOf(ctx).ParseFailed(err)
+			log.Printf("problem %d: attempt %d: %v", p.Index, attempt, err)
+		}
+		if err != nil {
+			failed = append(failed, pg.Issue{Index: p.Index, Check: "translation", Severity: pg.SeverityError,
+				Message: fmt.Sprintf("kept in %s: %v", pg.LanguageOf(ps.SourceLanguage).Name, err)})
+		}
+	}
+	pg.RecomputeAnswers(ps)
+	pg.Solve(ps) // steps and hints in the new language; explanations are written again
+	failed = append(failed, s.explain(ctx, ps)...)
+	pg.AddHints(ps)
+	pg.ScoreReadability(ps)
+	pg.ScoreDifficulty(ps)
+	ps.Issues = append(s.opts.Validators.Validate(ps), failed...)
+	return ps, nil
+}
+
+// checkSet rejects a set sent by a client that convertToInternal cannot
+// read.
+func checkSet(pbps *pb.ProblemSet) error {
+	switch {
+	case pbps == nil:
+		return status.Errorf(codes.InvalidArgument, "no problem set")
+	case pbps.Meta == nil:
+		return status.Errorf(codes.InvalidArgument, "the problem set has no meta")
+	case slices.Contains(pbps.Problems, nil):
+		return status.Errorf(codes.InvalidArgument, "the problem set has an empty problem")
+	}
+	return nil
+}
+
 // convertToInternal converts a protobuf ProblemSet to the internal pg.ProblemSet used by the PDF generator.
+// Sets from clients go through checkSet first.
 func convertToInternal(pbps *pb.ProblemSet) *pg.ProblemSet {
 	problems := make([]pg.Problem, len(pbps.Problems))
 	for i, p := range pbps.Problems {
@@ -124,22 +606,70 @@ func convertToInternal(pbps *pb.ProblemSet) *pg.ProblemSet {
 		}
 		problems[i] = pg.Problem{
 			Index:     int(p.Index),
+			Slot:      int(p.Slot),
 			Theme:     p.Theme,
 			Text:      p.Text,
 			Numbers:   nums,
 			Operation: p.Operation,
 			Answer:    p.Answer,
+			Kind:      p.Kind,
+			Accept:    p.Accept,
+			Choices:   p.Choices,
+			Unknown:   p.Unknown,
+			Equation:  p.Equation,
+			Sequence:  p.Sequence,
+			Rule:      p.Rule,
+			Expanded:  p.Expanded,
+
+			ReadingGrade: p.ReadingGrade,
+			Interest:     p.Interest,
+			Difficulty:   int(p.Difficulty),
+			SourceText:   p.SourceText,
+
+			Chart:    chartToInternal(p.ChartData),
+			Solution: solutionToInternal(p.Solution),
+			Hints:    p.Hints,
 		}
 	}
 	meta := pg.GenerateRequest{
 		Name:        pbps.Meta.Name,
+		Pronouns:    pbps.Meta.Pronouns,
 		Operation:   pbps.Meta.Operation,
 		NumProblems: int(pbps.Meta.NumProblems),
 		GradeLevel:  pbps.Meta.GradeLevel,
 		LikesNouns:  pbps.Meta.LikesNouns,
 		LikesVerbs:  pbps.Meta.LikesVerbs,
+		Kind:        pbps.Meta.Kind,
+		UnitSystem:  pbps.Meta.UnitSystem,
+
+		InterestBalance: pbps.Meta.InterestBalance,
+		Arrange:         pbps.Meta.Arrange,
+		Progression:     pbps.Meta.Progression,
+		Regrouping:      pbps.Meta.Regrouping,
+		Language:        pbps.Meta.Language,
+		Agent:           pbps.Meta.Agent,
+	}
+	for _, s := range pbps.Meta.Operations {
+		meta.Operations = append(meta.Operations, pg.OperationShare{Operation: s.Operation, Count: int(s.Count), Percent: int(s.Percent)})
 	}
-	return &pg.ProblemSet{Problems: problems, MetaInfo: meta}
+	issues := make([]pg.Issue, 0, len(pbps.Issues))
+	for _, i := range pbps.Issues {
+		issues = append(issues, pg.Issue{
+			Index:    int(i.Index),
+			Check:    i.Check,
+			Severity: pg.Severity(i.Severity),
+			Message:  i.Message,
+		})
+	}
+	coverage := make([]pg.Coverage, 0, len(pbps.Coverage))
+	for _, c := range pbps.Coverage {
+		indexes := make([]int, len(c.Problems))
+		for j, n := range c.Problems {
+			indexes[j] = int(n)
+		}
+		coverage = append(coverage, pg.Coverage{Interest: c.Interest, Problems: indexes})
+	}
+	return &pg.ProblemSet{ID: pbps.Id, Problems: problems, MetaInfo: meta, Issues: issues, Diversity: pbps.Diversity, Coverage: coverage, SourceLanguage: pbps.SourceLanguage}
 }
 
 // convertToInternal converts a protobuf ProblemSet to the internal pg.ProblemSet used by the PDF generator.
@@ -152,20 +682,107 @@ func convertFromInternal(pg *pg.ProblemSet) *pb.ProblemSet {
 		}
 		problems[i] = &pb.Problem{
 			Index:     int32(p.Index),
+			Slot:      int32(p.Slot),
 			Theme:     p.Theme,
 			Text:      p.Text,
 			Numbers:   nums,
 			Operation: p.Operation,
 			Answer:    p.Answer,
+			Kind:      p.Kind,
+			Accept:    p.Accept,
+			Choices:   p.Choices,
+			Unknown:   p.Unknown,
+			Equation:  p.Equation,
+			Sequence:  p.Sequence,
+			Rule:      p.Rule,
+			Expanded:  p.Expanded,
+
+			ReadingGrade: p.ReadingGrade,
+			Interest:     p.Interest,
+			Difficulty:   int32(p.Difficulty),
+			SourceText:   p.SourceText,
+
+			ChartData: chartFromInternal(p.Chart),
+			Solution:  solutionFromInternal(p.Solution),
+			Hints:     p.Hints,
 		}
 	}
 	meta := &pb.GenerateRequest{
 		Name:        pg.MetaInfo.Name,
+		Pronouns:    pg.MetaInfo.Pronouns,
 		Operation:   pg.MetaInfo.Operation,
 		NumProblems: int32(pg.MetaInfo.NumProblems),
 		GradeLevel:  pg.MetaInfo.GradeLevel,
 		LikesNouns:  pg.MetaInfo.LikesNouns,
 		LikesVerbs:  pg.MetaInfo.LikesVerbs,
+		Kind:        pg.MetaInfo.Kind,
+		UnitSystem:  pg.MetaInfo.UnitSystem,
+
+		InterestBalance: pg.MetaInfo.InterestBalance,
+		Arrange:         pg.MetaInfo.Arrange,
+		Progression:     pg.MetaInfo.Progression,
+		Regrouping:      pg.MetaInfo.Regrouping,
+		Language:        pg.MetaInfo.Language,
+		Agent:           pg.MetaInfo.Agent,
+	}
+	for _, s := range pg.MetaInfo.Operations {
+		meta.Operations = append(meta.Operations, &pb.OperationShare{Operation: s.Operation, Count: int32(s.Count), Percent: int32(s.Percent)})
+	}
+	issues := make([]*pb.Issue, 0, len(pg.Issues))
+	for _, i := range pg.Issues {
+		issues = append(issues, &pb.Issue{
+			Index:    int32(i.Index),
+			Check:    i.Check,
+			Severity: string(i.Severity),
+			Message:  i.Message,
+		})
+	}
+	coverage := make([]*pb.Coverage, 0, len(pg.Coverage))
+	for _, c := range pg.Coverage {
+		indexes := make([]int32, len(c.Problems))
+		for j, n := range c.Problems {
+			indexes[j] = int32(n)
+		}
+		coverage = append(coverage, &pb.Coverage{Interest: c.Interest, Problems: indexes})
+	}
+	return &pb.ProblemSet{Id: pg.ID, Problems: problems, Meta: meta, Issues: issues, Diversity: pg.Diversity, Coverage: coverage, SourceLanguage: pg.SourceLanguage}
+}
+
+// solutionToInternal and solutionFromInternal convert a worked solution;
+// nil stays nil.
+func solutionToInternal(s *pb.Solution) *pg.Solution {
+	if s == nil {
+		return nil
+	}
+	return &pg.Solution{Equation: s.Equation, Steps: s.Steps, Explanation: s.Explanation}
+}
+
+func solutionFromInternal(s *pg.Solution) *pb.Solution {
+	if s == nil {
+		return nil
+	}
+	return &pb.Solution{Equation: s.Equation, Steps: s.Steps, Explanation: s.Explanation}
+}
+
+// chartToInternal and chartFromInternal convert a chart; nil stays nil.
+func chartToInternal(c *pb.Chart) *pg.Chart {
+	if c == nil {
+		return nil
+	}
+	values := make([]int, len(c.Values))
+	for i, v := range c.Values {
+		values[i] = int(v)
+	}
+	return &pg.Chart{Style: c.Style, Dataset: pg.Dataset{Title: c.Title, Labels: c.Labels, Values: values, Icons: c.Icons, Scale: int(c.Scale)}}
+}
+
+func chartFromInternal(c *pg.Chart) *pb.Chart {
+	if c == nil {
+		return nil
+	}
+	values := make([]int32, len(c.Values))
+	for i, v := range c.Values {
+		values[i] = int32(v)
 	}
-	return &pb.ProblemSet{Problems: problems, Meta: meta}
+	return &pb.Chart{Style: c.Style, Title: c.Title, Labels: c.Labels, Values: values, Icons: c.Icons, Scale: int32(c.Scale)}
 }
@@ -0,0 +1,48 @@
+package grpcsrv
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	pg "github.com/qjs/mathgen_gemma/server/problem_generator"
+	pb "github.com/qjs/mathgen_gemma/server/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestExplainKeepsGoingWhenTheModelFails(t *testing.T) {
+	ollama := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "model not loaded", http.StatusInternalServerError)
+	}))
+	defer ollama.Close()
+	s := NewServer(ollama.URL, "gemma", nil, Options{Explain: true})
+
+	ps := &pg.ProblemSet{
+		MetaInfo: pg.GenerateRequest{GradeLevel: "2", Language: "en"},
+		Problems: []pg.Problem{
+			{Index: 1, Solution: &pg.Solution{Equation: "3 + 4 = 7"}},
+			{Index: 2},
+			{Index: 3, Solution: &pg.Solution{Equation: "9 - 5 = 4"}},
+		},
+	}
+	issues := s.explain(context.Background(), ps)
+	if len(issues) != 2 || issues[0].Index != 1 || issues[1].Index != 3 {
+		t.Fatalf("explain issues = %v, want one for problem 1 and one for problem 3", issues)
+	}
+	for _, i := range issues {
+		if i.Check != "explain" || i.Severity != pg.SeverityWarning {
+			t.Errorf("issue = %v, want an explain warning", i)
+		}
+	}
+}
+
+func TestOfflineRejectsKindsWithoutTemplates(t *testing.T) {
+	s := NewServer("http://localhost:0", "gemma", nil, Options{Offline: true, Fallback: pg.NewTemplateAgent(nil)})
+	req := &pb.GenerateRequest{Kind: pg.KindComparison, GradeLevel: "2", NumProblems: 3}
+	_, err := s.generateSet(context.Background(), req)
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("generateSet(comparison) offline error = %v, want FailedPrecondition", err)
+	}
+}
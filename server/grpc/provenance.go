@@ -0,0 +1,100 @@
+package grpcsrv
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+
+	"github.com/google/uuid"
+	pg "github.com/qjs/mathgen_gemma/server/problem_generator"
+	pb "github.com/qjs/mathgen_gemma/server/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+type provenanceKey struct{}
+
+// withProvenance carries the record of the set being made through the
+// calls that make it.
+func withProvenance(ctx context.Context, rec *pg.Provenance) context.Context {
+	return context.WithValue(ctx, provenanceKey{}, rec)
+}
+
+// provenanceOf is the record in ctx, nil when none is kept. Every
+// pg.Provenance method accepts nil.
+func provenanceOf(ctx context.Context) *pg.Provenance {
+	rec, _ := ctx.Value(provenanceKey{}).(*pg.Provenance)
+	return rec
+}
+
+// newProvenance starts the record of a kind ("generate", "translate") of
+// call for req, or returns nil when the server keeps no records.
+func (s *Server) newProvenance(kind string, req proto.Message) *pg.Provenance {
+	if s.opts.Provenance == nil {
+		return nil
+	}
+	rec := pg.NewProvenance(uuid.NewString(), kind, s.model)
+	rec.Request = json.RawMessage(protojson.Format(req))
+	rec.Options = pg.RunOptions{
+		Hybrid:     s.opts.Hybrid,
+		Offline:    s.opts.Offline,
+		Classifier: s.opts.Classifier,
+		Explain:    s.opts.Explain,
+	}
+	for _, v := range s.opts.Validators {
+		rec.Options.Validators = append(rec.Options.Validators, v.Name())
+	}
+	return rec
+}
+
+// saveProvenance finishes rec with the set or error the call ended with and
+// writes it. The set gets the record's ID. Failing to write only logs: the
+// set itself is fine.
+func (s *Server) saveProvenance(rec *pg.Provenance, ps *pg.ProblemSet, err error) {
+	if rec == nil {
+		return
+	}
+	if ps != nil {
+		ps.ID = rec.ID
+	}
+	rec.Finish(ps, err)
+	if err != nil {
+		log.Printf("provenance %s: failed: %v", rec.ID, err)
+	}
+	if err := s.opts.Provenance.Save(rec); err != nil {
+		log.Printf("provenance %s: %v", rec.ID, err)
+		if ps != nil {
+			ps.ID = ""
+		}
+	}
+}
+
+// GetProvenance returns the provenance record of a set by its ID, with the
+// set itself so it can be rendered again.
+func (s *Server) GetProvenance(ctx context.Context, req *pb.ProvenanceRequest) (*pb.ProvenanceRecord, error) {
+	if s.opts.Provenance == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "this server keeps no provenance records")
+	}
+	rec, err := s.opts.Provenance.Load(req.Id)
+	switch {
+	case errors.Is(err, pg.ErrNoProvenance):
+		return nil, status.Errorf(codes.NotFound, "%v", err)
+	case errors.Is(err, pg.ErrProvenanceID):
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	case err != nil:
+		return nil, status.Errorf(codes.Internal, "%v", err)
+	}
+	data, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "provenance %s: %v", req.Id, err)
+	}
+	out := &pb.ProvenanceRecord{Id: rec.ID, Json: string(data)}
+	if rec.ProblemSet != nil {
+		out.ProblemSet = convertFromInternal(rec.ProblemSet)
+	}
+	return out, nil
+}
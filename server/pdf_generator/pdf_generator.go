@@ -3,9 +3,11 @@ package pdfgenerator
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"html/template"
 	"net/url"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/chromedp/cdproto/page"
@@ -13,16 +15,80 @@ import (
 	pg "github.com/qjs/mathgen_gemma/server/problem_generator"
 )
 
+// funcs are the helpers available to problems.html.
+var funcs = template.FuncMap{
+	// blank draws an answer box where the equation's unknown goes.
+	"blank": func(eq string) template.HTML {
+		return template.HTML(strings.Replace(template.HTMLEscapeString(eq), pg.Blank, `<span class="blank"></span>`, 1))
+	},
+	// svg draws a chart problem's chart; RenderChart escapes its text.
+	"svg": func(c *pg.Chart) (template.HTML, error) {
+		s, err := pg.RenderChart(c.Dataset, c.Style)
+		return template.HTML(s), err
+	},
+	// isBlank reports whether a pattern term is the one to find.
+	"isBlank": func(term string) bool { return term == pg.Blank },
+	// blanks gives one write-in line per part of an expanded form.
+	"blanks": func(expanded string) string {
+		n := strings.Count(expanded, "+") + 1
+		return strings.TrimSuffix(strings.Repeat("_____ + ", n), " + ")
+	},
+	// stars shows a problem's difficulty in the answer key.
+	"stars": pg.StarString,
+	// num groups the digits of a whole-number answer the way the
+	// worksheet's language does.
+	"num": pg.FormatAnswer,
+	// fill writes the answer into the equation's blank.
+	"fill": func(eq, answer string) string {
+		return strings.Replace(eq, pg.Blank, answer, 1)
+	},
+}
+
 func GeneratePDF(ctx context.Context, ps pg.ProblemSet, outFile string) error {
-	tpl, err := template.ParseFiles("server/pdf_generator/template/problems.html")
+	tpl, err := template.New("problems.html").Funcs(funcs).ParseFiles("server/pdf_generator/template/problems.html")
 	if err != nil {
 		return err
 	}
 
+	// Problems are printed in the set's order, with the numbers the issues,
+	// the provenance record and the interactive page use. The server has
+	// already gathered them by theme, or by operation when a mixed set asks
+	// for it; headings are only added where that order allows.
+	problems := ps.Problems
+	groups := pg.GroupByTheme(problems)
+	if pg.PlannedOrder(ps.MetaInfo) {
+		groups = []pg.ThemeGroup{{Problems: problems}}
+		if pg.Arrangement(ps.MetaInfo) == pg.ArrangeGrouped {
+			groups = pg.GroupByOperation(problems, ps.MetaInfo.Language)
+		}
+	}
+	if !inOrder(groups, problems) {
+		groups = []pg.ThemeGroup{{Problems: problems}}
+	}
+
+	lang := pg.NormalizeLanguage(ps.MetaInfo.Language)
+	labels := pg.Labels(lang)
+	op := pg.OperationName(ps.MetaInfo.Operation, lang)
+	title := fmt.Sprintf(labels.Title, ps.MetaInfo.Name, op)
+	answerTitle := fmt.Sprintf(labels.AnswerTitle, ps.MetaInfo.Name, op)
+
+	// A translated set that kept its original text prints both side by
+	// side, with both titles.
+	srcLang := pg.NormalizeLanguage(ps.SourceLanguage)
+	if dualLanguage(problems) && srcLang != lang {
+		src := pg.Labels(srcLang)
+		srcOp := pg.OperationName(ps.MetaInfo.Operation, srcLang)
+		title += " / " + fmt.Sprintf(src.Title, ps.MetaInfo.Name, srcOp)
+		answerTitle += " / " + fmt.Sprintf(src.AnswerTitle, ps.MetaInfo.Name, srcOp)
+	}
 	data := map[string]any{
-		"Title":       ps.MetaInfo.Name + "'s " + ps.MetaInfo.Operation + " Problem Set",
-		"AnswerTitle": ps.MetaInfo.Name + "'s " + ps.MetaInfo.Operation + " Answer Key",
-		"Problems":    ps.Problems,
+		"Title":       title,
+		"AnswerTitle": answerTitle,
+		"Lang":        lang,
+		"SourceLang":  srcLang,
+		"Labels":      labels,
+		"Groups":      groups,
+		"Problems":    problems,
 	}
 
 	var htmlBuf bytes.Buffer
@@ -64,3 +130,28 @@ func GeneratePDF(ctx context.Context, ps pg.ProblemSet, outFile string) error {
 	// -- 3. save -------------------------------------------------------------
 	return os.WriteFile(outFile, pdfBuf, 0o644)
 }
+
+// inOrder reports whether groups print problems in their order.
+func inOrder(groups []pg.ThemeGroup, problems []pg.Problem) bool {
+	i := 0
+	for _, g := range groups {
+		for _, p := range g.Problems {
+			if i >= len(problems) || p.Index != problems[i].Index {
+				return false
+			}
+			i++
+		}
+	}
+	return i == len(problems)
+}
+
+// dualLanguage reports whether problems keep their text from before a
+// translation, to print beside the translated one.
+func dualLanguage(problems []pg.Problem) bool {
+	for _, p := range problems {
+		if p.SourceText != "" {
+			return true
+		}
+	}
+	return false
+}
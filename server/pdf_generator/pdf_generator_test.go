@@ -0,0 +1,26 @@
+package pdfgenerator
+
+import (
+	"testing"
+
+	pg "github.com/qjs/mathgen_gemma/server/problem_generator"
+)
+
+func TestInOrder(t *testing.T) {
+	problems := []pg.Problem{{Index: 1, Theme: "A"}, {Index: 2, Theme: "B"}, {Index: 3, Theme: "A"}}
+	tests := []struct {
+		name   string
+		groups []pg.ThemeGroup
+		want   bool
+	}{
+		{"one group", []pg.ThemeGroup{{Problems: problems}}, true},
+		{"themes reorder", pg.GroupByTheme(problems), false},
+		{"split in order", []pg.ThemeGroup{{Problems: problems[:1]}, {Problems: problems[1:]}}, true},
+		{"missing problem", []pg.ThemeGroup{{Problems: problems[:2]}}, false},
+	}
+	for _, tt := range tests {
+		if got := inOrder(tt.groups, problems); got != tt.want {
+			t.Errorf("%s: inOrder = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
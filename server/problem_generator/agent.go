@@ -2,7 +2,9 @@ package problemgenerator
 
 import pb "github.com/qjs/mathgen_gemma/server/proto"
 
-// Agent parses a plain-text response from the LLM into a ProblemSet.
+// Agent parses a plain-text response from the LLM into a ProblemSet. When
+// only some of its problems can be read, Parse returns those with
+// ParseErrors naming the others.
 type Agent interface {
 	Parse(llmOut string, req *pb.GenerateRequest) (*ProblemSet, error)
 }
@@ -0,0 +1,172 @@
+package problemgenerator
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+
+	pb "github.com/qjs/mathgen_gemma/server/proto"
+)
+
+// Chart styles.
+const (
+	ChartBar        = "bar"
+	ChartPictograph = "pictograph"
+	ChartTally      = "tally"
+)
+
+// Chart questions. The answers are always computed from the Dataset.
+const (
+	OpHowMany    = "how_many"
+	OpMost       = "most"
+	OpFewest     = "fewest"
+	OpAltogether = "altogether"
+)
+
+// Dataset is the small table a chart problem is drawn from.
+type Dataset struct {
+	Title  string   `json:"title"`
+	Labels []string `json:"labels"`
+	Values []int    `json:"values"`
+	Icons  []string `json:"icons,omitempty"` // one picture per label, used by pictographs
+	Scale  int      `json:"scale"`           // values are multiples of Scale; one picture stands for Scale
+}
+
+// Chart is what a chart problem shows: its data and the style to draw it
+// in. Pages draw it with RenderChart when they show it, so a set never
+// carries chart markup a client could have written.
+type Chart struct {
+	Style string `json:"style"` // bar | pictograph | tally
+	Dataset
+}
+
+// String tells charts apart by what they show.
+func (c *Chart) String() string {
+	if c == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s %q %q %v %q %d", c.Style, c.Title, c.Labels, c.Values, c.Icons, c.Scale)
+}
+
+// chartPolicy sets the value range and chart styles for a grade.
+func chartPolicy(grade int) (maxValue, scale int, styles []string) {
+	switch {
+	case grade <= 0:
+		return 5, 1, []string{ChartPictograph, ChartTally}
+	case grade == 1:
+		return 10, 1, []string{ChartPictograph, ChartTally, ChartBar}
+	case grade == 2:
+		return 20, 2, []string{ChartPictograph, ChartTally, ChartBar}
+	}
+	return 50, 5, []string{ChartPictograph, ChartBar}
+}
+
+// newDataset makes a dataset of three or four of the student's interests
+// with distinct values, so "most" and "fewest" always have one answer.
+func newDataset(name string, nouns []string, grade int, rng *rand.Rand) (Dataset, string) {
+	maxValue, scale, styles := chartPolicy(grade)
+	style := styles[rng.IntN(len(styles))]
+	if style == ChartTally {
+		scale = 1 // tallies are counted one by one
+	}
+
+	labels := append([]string(nil), nouns...)
+	for _, extra := range []string{"stars", "apples", "blocks", "balls"} {
+		if len(labels) >= 4 {
+			break
+		}
+		labels = append(labels, extra)
+	}
+	rng.Shuffle(len(labels), func(i, j int) { labels[i], labels[j] = labels[j], labels[i] })
+	labels = labels[:3+rng.IntN(2)]
+
+	// Distinct multiples of scale between scale and maxValue.
+	steps := rng.Perm(maxValue / scale)
+	values := make([]int, len(labels))
+	for i := range values {
+		values[i] = (steps[i] + 1) * scale
+	}
+
+	icons := patternSymbols(labels, len(labels), rng)
+	for i, l := range labels {
+		if e, ok := emojiFor(l); ok {
+			icons[i] = e
+		}
+	}
+	return Dataset{
+		Title:  fmt.Sprintf("%s's favorite things", name),
+		Labels: labels,
+		Values: values,
+		Icons:  icons,
+		Scale:  scale,
+	}, style
+}
+
+// GenerateCharts builds chart-reading problems without the model. Each
+// problem gets its own small dataset drawn from the student's interests,
+// shown as an SVG bar chart, pictograph or tally chart.
+func GenerateCharts(req *pb.GenerateRequest, rng *rand.Rand) (*ProblemSet, error) {
+	if rng == nil {
+		rng = rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+	}
+	grade := GradeNumber(req.GradeLevel)
+	ops := []string{OpHowMany, OpMost, OpFewest, OpHowManyMore, OpAltogether}
+	if grade <= 0 {
+		ops = ops[:3] // kindergarten reads and compares, no arithmetic
+	}
+
+	problems := make([]Problem, 0, req.NumProblems)
+	for i := 1; i <= int(req.NumProblems); i++ {
+		data, style := newDataset(req.Name, req.LikesNouns, grade, rng)
+		op := ops[(i-1)%len(ops)]
+		p, err := chartQuestion(data, op, rng)
+		if err != nil {
+			return nil, fmt.Errorf("problem %d: %v", i, err)
+		}
+		p.Index, p.Kind, p.Chart = i, KindChart, &Chart{Style: style, Dataset: data}
+		p.Theme = "Chart 📊"
+		problems = append(problems, p)
+	}
+	return &ProblemSet{Problems: problems, MetaInfo: metaFromRequest(req)}, nil
+}
+
+// chartQuestion asks op about data and computes the answer from it.
+func chartQuestion(data Dataset, op string, rng *rand.Rand) (Problem, error) {
+	n := len(data.Labels)
+	i := rng.IntN(n)
+	j := (i + 1 + rng.IntN(n-1)) % n
+	if data.Values[j] > data.Values[i] {
+		i, j = j, i // "how many more" always asks about the bigger one
+	}
+	a, b := data.Labels[i], data.Labels[j]
+
+	p := Problem{Operation: op, Numbers: append([]int(nil), data.Values...)}
+	switch op {
+	case OpHowMany:
+		p.Text = fmt.Sprintf("Look at the chart. How many %s are there?", a)
+		p.Answer = strconv.Itoa(data.Values[i])
+	case OpHowManyMore:
+		p.Text = fmt.Sprintf("Look at the chart. How many more %s than %s are there?", a, b)
+		p.Answer = strconv.Itoa(data.Values[i] - data.Values[j])
+	case OpAltogether:
+		p.Text = fmt.Sprintf("Look at the chart. How many %s and %s are there altogether?", a, b)
+		p.Answer = strconv.Itoa(data.Values[i] + data.Values[j])
+	case OpMost, OpFewest:
+		best := 0
+		for k, v := range data.Values {
+			if (op == OpMost && v > data.Values[best]) || (op == OpFewest && v < data.Values[best]) {
+				best = k
+			}
+		}
+		word := "most"
+		if op == OpFewest {
+			word = "fewest"
+		}
+		p.Text = fmt.Sprintf("Look at the chart. Which has the %s?", word)
+		p.Answer = data.Labels[best]
+		p.Choices = append([]string(nil), data.Labels...)
+	default:
+		return Problem{}, fmt.Errorf("unknown chart question %q", op)
+	}
+	return p, nil
+}
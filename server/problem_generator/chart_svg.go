@@ -0,0 +1,164 @@
+package problemgenerator
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// Chart geometry, in SVG user units. Charts are drawn at a fixed width so
+// they fit a Letter page and the interactive page alike.
+const (
+	chartWidth  = 420
+	labelWidth  = 100
+	rowHeight   = 34
+	titleHeight = 30
+)
+
+// Limits on what RenderChart draws. The server never makes bigger charts;
+// they keep a chart sent back by a client to one page.
+const (
+	maxChartRows     = 6
+	maxChartValue    = 100
+	maxChartPictures = 10 // pictures in a pictograph row
+	maxChartTallies  = 30 // tally marks in a row
+)
+
+// RenderChart draws data as an SVG chart of the given style. Emoji are
+// drawn as text, so they show up wherever the page's fonts can draw them,
+// including headless Chrome. Every label and icon is escaped, so the chart
+// is safe to embed whoever made the Dataset.
+func RenderChart(data Dataset, style string) (string, error) {
+	if err := checkDataset(data, style); err != nil {
+		return "", err
+	}
+	switch style {
+	case ChartBar:
+		return renderBar(data), nil
+	case ChartPictograph:
+		return renderPictograph(data), nil
+	case ChartTally:
+		return renderTally(data), nil
+	}
+	return "", fmt.Errorf("unknown chart style %q", style)
+}
+
+// checkDataset reports a dataset RenderChart cannot draw in style.
+func checkDataset(data Dataset, style string) error {
+	switch {
+	case len(data.Labels) == 0 || len(data.Labels) > maxChartRows:
+		return fmt.Errorf("chart has %d rows, want 1 to %d", len(data.Labels), maxChartRows)
+	case len(data.Values) != len(data.Labels):
+		return fmt.Errorf("chart has %d values for %d labels", len(data.Values), len(data.Labels))
+	case data.Scale < 1:
+		return fmt.Errorf("chart scale %d is not positive", data.Scale)
+	case style == ChartPictograph && len(data.Icons) != len(data.Labels):
+		return fmt.Errorf("pictograph has %d icons for %d labels", len(data.Icons), len(data.Labels))
+	}
+	limit := maxChartValue
+	switch style {
+	case ChartPictograph:
+		limit = min(maxChartValue, maxChartPictures*data.Scale)
+	case ChartTally:
+		limit = maxChartTallies
+	}
+	top := 0
+	for _, v := range data.Values {
+		if v < 0 || v > limit {
+			return fmt.Errorf("chart value %d is not between 0 and %d", v, limit)
+		}
+		top = max(top, v)
+	}
+	if top == 0 {
+		return fmt.Errorf("chart has nothing to show")
+	}
+	return nil
+}
+
+// svgOpen starts a chart with its title.
+func svgOpen(b *strings.Builder, title string, height int) {
+	fmt.Fprintf(b, `<svg xmlns="http://www.w3.org/2000/svg" class="chart" width="%d" height="%d" viewBox="0 0 %d %d" font-family="DejaVu Sans, Noto Color Emoji, sans-serif">`,
+		chartWidth, height, chartWidth, height)
+	fmt.Fprintf(b, `<text x="%d" y="20" text-anchor="middle" font-size="16" font-weight="bold">%s</text>`,
+		chartWidth/2, html.EscapeString(title))
+}
+
+// renderBar draws horizontal bars with a labelled value axis.
+func renderBar(data Dataset) string {
+	maxV := 0
+	for _, v := range data.Values {
+		maxV = max(maxV, v)
+	}
+	tick := 1
+	for _, t := range []int{1, 2, 5, 10} {
+		tick = t
+		if maxV/t <= 10 {
+			break
+		}
+	}
+	top := (maxV + tick - 1) / tick * tick
+	plotW := chartWidth - labelWidth - 20
+	height := titleHeight + len(data.Labels)*rowHeight + 30
+
+	var b strings.Builder
+	svgOpen(&b, data.Title, height)
+	axisY := titleHeight + len(data.Labels)*rowHeight
+	for v := 0; v <= top; v += tick {
+		x := labelWidth + v*plotW/top
+		fmt.Fprintf(&b, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="#ccc"/>`, x, titleHeight, x, axisY)
+		fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="middle" font-size="12">%d</text>`, x, axisY+16, v)
+	}
+	for i, l := range data.Labels {
+		y := titleHeight + i*rowHeight
+		fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="end" font-size="14">%s</text>`, labelWidth-8, y+22, html.EscapeString(l))
+		fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" fill="#4a90d9"/>`, labelWidth, y+6, data.Values[i]*plotW/top, rowHeight-12)
+	}
+	fmt.Fprintf(&b, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="#333"/>`, labelWidth, titleHeight, labelWidth, axisY)
+	b.WriteString(`</svg>`)
+	return b.String()
+}
+
+// renderPictograph draws one picture per Scale items and a key.
+func renderPictograph(data Dataset) string {
+	height := titleHeight + len(data.Labels)*rowHeight + 30
+	var b strings.Builder
+	svgOpen(&b, data.Title, height)
+	for i, l := range data.Labels {
+		y := titleHeight + i*rowHeight
+		fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="end" font-size="14">%s</text>`, labelWidth-8, y+22, html.EscapeString(l))
+		for k := 0; k < data.Values[i]/data.Scale; k++ {
+			fmt.Fprintf(&b, `<text x="%d" y="%d" font-size="20">%s</text>`, labelWidth+k*28, y+24, html.EscapeString(data.Icons[i]))
+		}
+	}
+	key := "Each picture = 1"
+	if data.Scale > 1 {
+		key = fmt.Sprintf("Each picture = %d", data.Scale)
+	}
+	fmt.Fprintf(&b, `<text x="%d" y="%d" font-size="13" font-style="italic">%s</text>`, labelWidth, height-8, key)
+	b.WriteString(`</svg>`)
+	return b.String()
+}
+
+// renderTally draws tally marks in bundles of five.
+func renderTally(data Dataset) string {
+	height := titleHeight + len(data.Labels)*rowHeight + 10
+	var b strings.Builder
+	svgOpen(&b, data.Title, height)
+	for i, l := range data.Labels {
+		y := titleHeight + i*rowHeight
+		fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="end" font-size="14">%s</text>`, labelWidth-8, y+22, html.EscapeString(l))
+		x := labelWidth
+		for left := data.Values[i]; left > 0; left -= 5 {
+			n := min(left, 5)
+			for k := 0; k < min(n, 4); k++ {
+				fmt.Fprintf(&b, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="#333" stroke-width="2"/>`, x+k*8, y+6, x+k*8, y+28)
+			}
+			if n == 5 {
+				fmt.Fprintf(&b, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="#333" stroke-width="2"/>`, x-4, y+24, x+28, y+10)
+			}
+			x += 44
+		}
+	}
+	b.WriteString(`</svg>`)
+	return b.String()
+}
@@ -0,0 +1,58 @@
+package problemgenerator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderChart(t *testing.T) {
+	data := Dataset{
+		Title:  `<script>fetch("//x")</script>`,
+		Labels: []string{"cats", `<img src=x onerror=alert(1)>`},
+		Values: []int{3, 5},
+		Icons:  []string{"🐱", `<foreignObject>`},
+		Scale:  1,
+	}
+	tests := []struct {
+		name  string
+		data  func(d Dataset) Dataset
+		style string
+		err   bool
+	}{
+		{name: "bar", style: ChartBar},
+		{name: "pictograph", style: ChartPictograph},
+		{name: "tally", style: ChartTally},
+		{name: "unknown style", style: "pie", err: true},
+		{name: "no rows", style: ChartBar, err: true,
+			data: func(d Dataset) Dataset { d.Labels, d.Values = nil, nil; return d }},
+		{name: "values short", style: ChartBar, err: true,
+			data: func(d Dataset) Dataset { d.Values = d.Values[:1]; return d }},
+		{name: "zero scale", style: ChartPictograph, err: true,
+			data: func(d Dataset) Dataset { d.Scale = 0; return d }},
+		{name: "all zero", style: ChartBar, err: true,
+			data: func(d Dataset) Dataset { d.Values = []int{0, 0}; return d }},
+		{name: "negative", style: ChartBar, err: true,
+			data: func(d Dataset) Dataset { d.Values = []int{-1, 5}; return d }},
+		{name: "too many pictures", style: ChartPictograph, err: true,
+			data: func(d Dataset) Dataset { d.Values = []int{3, 11}; return d }},
+		{name: "too many tallies", style: ChartTally, err: true,
+			data: func(d Dataset) Dataset { d.Values = []int{3, 31}; return d }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := data
+			if tt.data != nil {
+				d = tt.data(d)
+			}
+			svg, err := RenderChart(d, tt.style)
+			if (err != nil) != tt.err {
+				t.Fatalf("err = %v, want error %v", err, tt.err)
+			}
+			for _, tag := range []string{"<script", "<img", "<foreignObject"} {
+				if strings.Contains(svg, tag) {
+					t.Errorf("chart contains %s:\n%s", tag, svg)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package problemgenerator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Comparison operations. Only OpHowManyMore has a numeric answer; the others
+// are answered by picking one of the problem's Choices.
+const (
+	OpHowManyMore = "how_many_more" // 12 vs 7 → "5"
+	OpWhoHasMore  = "who_has_more"  // → the name with the larger amount
+	OpWhoHasFewer = "who_has_fewer" // → the name with the smaller amount
+	OpCompare     = "compare"       // 12 vs 7 → ">"
+)
+
+// CompareSymbols are the choices offered for OpCompare.
+var CompareSymbols = []string{"<", "=", ">"}
+
+// solveComparison computes the answer to a comparison problem. names are the
+// two characters being compared as the model reported them. The story must
+// hold exactly two amounts, and for name answers each name must come before
+// its own amount and the amounts must differ, so exactly one answer is right.
+func solveComparison(text, op string, names []string) (nums []int, answer string, choices []string, err error) {
+	numStrs := reInts.FindAllString(text, -1)
+	if len(numStrs) != 2 {
+		return nil, "", nil, fmt.Errorf("comparison needs exactly two numbers, found %d", len(numStrs))
+	}
+	a, _ := strconv.Atoi(numStrs[0])
+	b, _ := strconv.Atoi(numStrs[1])
+	nums = []int{a, b}
+
+	switch op = normalizeComparisonOp(op); op {
+	case OpCompare:
+		symbol := "="
+		if a < b {
+			symbol = "<"
+		} else if a > b {
+			symbol = ">"
+		}
+		return nums, symbol, append([]string(nil), CompareSymbols...), nil
+
+	case OpHowManyMore:
+		if a == b {
+			return nil, "", nil, fmt.Errorf("both amounts are %d, so neither has more", a)
+		}
+		if len(names) == 2 {
+			ordered, err := orderNames(text, names)
+			if err != nil {
+				return nil, "", nil, err
+			}
+			// "How many more does Leo have than Ana?" only makes sense
+			// when Ana has the smaller amount.
+			smaller := ordered[1]
+			if b > a {
+				smaller = ordered[0]
+			}
+			for _, n := range ordered {
+				if n != smaller && strings.Contains(text, "more") && mentions(text, "than "+n) {
+					return nil, "", nil, fmt.Errorf("asks how many more than %s, but %s has the most", n, n)
+				}
+			}
+		}
+		diff := a - b
+		if diff < 0 {
+			diff = -diff
+		}
+		return nums, strconv.Itoa(diff), nil, nil
+
+	case OpWhoHasMore, OpWhoHasFewer:
+		if len(names) != 2 {
+			return nil, "", nil, fmt.Errorf("%s needs two names, got %d", op, len(names))
+		}
+		if a == b {
+			return nil, "", nil, fmt.Errorf("both amounts are %d, so there is no single answer", a)
+		}
+		ordered, err := orderNames(text, names)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		// The first name in the story owns the first amount.
+		more, fewer := ordered[0], ordered[1]
+		if b > a {
+			more, fewer = fewer, more
+		}
+		if op == OpWhoHasMore {
+			return nums, more, ordered, nil
+		}
+		return nums, fewer, ordered, nil
+
+	default:
+		return nil, "", nil, fmt.Errorf("unknown comparison operation %q", op)
+	}
+}
+
+// orderNames returns names in the order they first appear in text and checks
+// that they are distinct, both present, and each introduced before the amount
+// that belongs to it.
+func orderNames(text string, names []string) ([]string, error) {
+	n0, n1 := strings.TrimSpace(names[0]), strings.TrimSpace(names[1])
+	if n0 == "" || n1 == "" || strings.EqualFold(n0, n1) {
+		return nil, fmt.Errorf("comparison needs two different names, got %q and %q", n0, n1)
+	}
+	i0, i1 := nameIndex(text, n0), nameIndex(text, n1)
+	if i0 < 0 || i1 < 0 {
+		return nil, fmt.Errorf("names %q and %q must both appear in the text", n0, n1)
+	}
+	if i1 < i0 {
+		n0, n1, i0, i1 = n1, n0, i1, i0
+	}
+	locs := reInts.FindAllStringIndex(text, 2)
+	if locs[0][0] < i0 || locs[1][0] < i1 || locs[0][0] > i1 {
+		return nil, fmt.Errorf("cannot tell which amount belongs to %s and which to %s", n0, n1)
+	}
+	return []string{n0, n1}, nil
+}
+
+// normalizeComparisonOp accepts the phrasings the model tends to use.
+func normalizeComparisonOp(op string) string {
+	op = strings.ToLower(strings.TrimSpace(op))
+	op = strings.NewReplacer(" ", "_", "-", "_").Replace(op)
+	switch op {
+	case "how_many_more", "how_many_fewer", "how_many_less", "difference":
+		return OpHowManyMore
+	case "who_has_more", "more", "most", "greater":
+		return OpWhoHasMore
+	case "who_has_fewer", "who_has_less", "fewer", "less", "fewest":
+		return OpWhoHasFewer
+	case "compare", "comparison", "symbol", "<>=":
+		return OpCompare
+	}
+	return op
+}
@@ -0,0 +1,28 @@
+package problemgenerator
+
+import "testing"
+
+func TestSolveComparison(t *testing.T) {
+	tests := []struct {
+		text, op string
+		names    []string
+		answer   string
+		err      bool
+	}{
+		{text: "Sam has 3 cards. Leo has 7 cards. Who has more?", op: OpWhoHasMore, names: []string{"Sam", "Leo"}, answer: "Leo"},
+		{text: "Samantha has 9 cards and Sam has 4 cards. Who has fewer?", op: OpWhoHasFewer, names: []string{"Sam", "Samantha"}, answer: "Sam"},
+		{text: "Samantha has 9 cards and Sam has 4 cards. Who has fewer?", op: OpWhoHasFewer, names: []string{"Samantha", "Sam"}, answer: "Sam"},
+		{text: "The Leopards scored 8 goals and Leo scored 5 goals. Who scored more?", op: OpWhoHasMore, names: []string{"Leo", "Ana"}, err: true},
+		{text: "Ana has 8 shells and Leo has 5. How many more shells does Ana have than Leo?", op: OpHowManyMore, names: []string{"Ana", "Leo"}, answer: "3"},
+		{text: "Ana has 8 shells and Leo has 5. How many more does Leo have than Ana?", op: OpHowManyMore, names: []string{"Ana", "Leo"}, err: true},
+		{text: "Ana has 8 shells and Anabel has 5. How many more does Ana have than Anabel?", op: OpHowManyMore, names: []string{"Ana", "Anabel"}, answer: "3"},
+		{text: "Ana has 4 and Leo has 4. Who has more?", op: OpWhoHasMore, names: []string{"Ana", "Leo"}, err: true},
+		{text: "Compare 12 and 7.", op: OpCompare, answer: ">"},
+	}
+	for _, tt := range tests {
+		_, answer, _, err := solveComparison(tt.text, tt.op, tt.names)
+		if (err != nil) != tt.err || answer != tt.answer {
+			t.Errorf("solveComparison(%q, %s, %q) = %q, %v; want %q, error %v", tt.text, tt.op, tt.names, answer, err, tt.answer, tt.err)
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package problemgenerator
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+
+	pb "github.com/qjs/mathgen_gemma/server/proto"
+)
+
+// Difficulty is what makes an a op b problem hard, each part from 0 (easy)
+// to 1 (hard).
+type Difficulty struct {
+	Size       float64 // the largest number, against the grade's range
+	Regrouping float64 // 1 when the sum carries, the difference borrows or the fact is past 5×5
+	Steps      float64 // 1 when the unknown is a start or change, not the result
+	Reading    float64 // how far the text reads above the grade; 0 for equations
+}
+
+// Score weighs the parts into one number from 0 to 1. The math decides it;
+// hard reading only tips a story over into the next level.
+func (d Difficulty) Score() float64 {
+	return math.Min(1, d.mathScore()+0.15*d.Reading)
+}
+
+func (d Difficulty) mathScore() float64 {
+	return 0.5*d.Size + 0.3*d.Regrouping + 0.2*d.Steps
+}
+
+// Stars turns a score into 1 (warm-up), 2 or 3 (challenge) stars.
+func Stars(score float64) int {
+	switch {
+	case score < 1.0/3:
+		return 1
+	case score < 2.0/3:
+		return 2
+	}
+	return 3
+}
+
+// StarString draws n stars, as the answer key shows them.
+func StarString(n int) string { return strings.Repeat("★", n) }
+
+// Orders a set can ramp its difficulty in.
+const (
+	ProgressionRamp    = "ramp"    // warm-up first, challenge last
+	ProgressionUniform = "uniform" // every problem in the middle
+	ProgressionSpiral  = "spiral"  // easy, medium, hard, and round again
+)
+
+// NormalizeProgression maps form and API spellings to a Progression*
+// constant, or "" when the set has no planned difficulty.
+func NormalizeProgression(s string) string {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "ramp", "warmup", "warm_up", "warm-up", "ramp_up":
+		return ProgressionRamp
+	case "uniform", "even", "same":
+		return ProgressionUniform
+	case "spiral", "cycle":
+		return ProgressionSpiral
+	}
+	return ""
+}
+
+// DifficultyLevels is the star level planned for each of n problems, or nil
+// when progression is "".
+func DifficultyLevels(n int, progression string) []int {
+	var levels []int
+	for i := 0; i < n; i++ {
+		switch NormalizeProgression(progression) {
+		case ProgressionRamp:
+			levels = append(levels, 1+i*3/n)
+		case ProgressionUniform:
+			levels = append(levels, 2)
+		case ProgressionSpiral:
+			levels = append(levels, 1+i%3)
+		default:
+			return nil
+		}
+	}
+	return levels
+}
+
+// levelName is how prompts and issues speak of a star level.
+func levelName(level int) string {
+	return [...]string{"any", "warm-up", "medium", "challenge"}[level]
+}
+
+// ---------- scoring ----------
+
+// mathDifficulty scores a op b = result for a grade, with unknown the slot
+// the student finds.
+func mathDifficulty(op string, a, b, result int, unknown string, grade int) Difficulty {
+	d := Difficulty{Size: math.Min(1, float64(sizeKey(op, a, b, result))/float64(factRange(grade, op)))}
+	if regroups(op, a, b) {
+		d.Regrouping = 1
+	}
+	if unknown == SlotA || unknown == SlotB {
+		d.Steps = 1
+	}
+	return d
+}
+
+// sizeKey is the number that sets a problem's size: the sum, the number
+// taken from, or the larger factor.
+func sizeKey(op string, a, b, result int) int {
+	switch op {
+	case OpSubtraction:
+		return a
+	case "multiplication":
+		return max(a, b)
+	case "division":
+		return max(b, result)
+	}
+	return result
+}
+
+// regroups reports whether a op b needs carrying or borrowing in any
+// column, or for multiplication and division, a fact past 5×5.
+func regroups(op string, a, b int) bool {
+	switch op {
+	case OpAddition, OpSubtraction:
+		return len(regroupColumns(op, a, b)) > 0
+	case "multiplication":
+		return a > 5 && b > 5
+	case "division":
+		return b > 5 && a/max(b, 1) > 5
+	}
+	return false
+}
+
+// DifficultyOf scores p for the set's grade. Only a op b problems (stories,
+// missing numbers and equations) have a difficulty; for the others ok is
+// false.
+func DifficultyOf(p Problem, meta GenerateRequest) (Difficulty, bool) {
+	kind := NormalizeKind(p.Kind)
+	if kind != KindArithmetic && kind != KindMissing && kind != KindEquation {
+		return Difficulty{}, false
+	}
+	op := NormalizeOperation(p.Operation)
+	if _, known := opSymbols[op]; !known || len(p.Numbers) < 2 {
+		return Difficulty{}, false
+	}
+	a, b := p.Numbers[0], p.Numbers[1]
+	result, err := strconv.Atoi(p.Answer)
+	if len(p.Numbers) >= 3 {
+		result = p.Numbers[2]
+	} else if err != nil {
+		return Difficulty{}, false
+	}
+	grade := GradeNumber(meta.GradeLevel)
+	d := mathDifficulty(op, a, b, result, p.Unknown, grade)
+	if p.ReadingGrade > 0 {
+		over := p.ReadingGrade - ReadingTargetFor(meta.GradeLevel).MaxGrade
+		d.Reading = math.Max(0, math.Min(1, (over+2)/3)) // from 2 grades under the target to 1 over
+	}
+	return d, true
+}
+
+// ScoreDifficulty gives every problem of ps its stars.
+func ScoreDifficulty(ps *ProblemSet) {
+	for i, p := range ps.Problems {
+		if d, ok := DifficultyOf(p, ps.MetaInfo); ok {
+			ps.Problems[i].Difficulty = Stars(d.Score())
+		}
+	}
+}
+
+// ---------- planning ----------
+
+// slotSpec is what the planner must meet for one problem.
+type slotSpec struct {
+	Index   int
+	Level   int    // stars, 0 for any
+	Unknown string // the slot the student finds
+	Regroup Regrouping
+}
+
+// slotSpecs are the specs of the n problems of req.
+func slotSpecs(req *pb.GenerateRequest, n int) []slotSpec {
+	levels := DifficultyLevels(n, req.Progression)
+	rule, _ := ParseRegrouping(req.Regrouping)
+	specs := make([]slotSpec, n)
+	for i := range specs {
+		specs[i] = slotSpec{Index: i + 1, Unknown: SlotResult, Regroup: rule}
+		if levels != nil {
+			specs[i].Level = levels[i]
+		}
+	}
+	return specs
+}
+
+// pickFor picks a op b = result that follows spec's regrouping rule and,
+// as far as it can, scores at its level. The rule wins when both cannot be
+// met.
+func pickFor(op string, grade int, spec slotSpec, rng *rand.Rand) (a, b, result int) {
+	top := spec.Regroup.top(factRange(grade, op))
+	var fallback []int
+	for tries := 0; tries < 500; tries++ {
+		a, b, result = pickOperands(op, top, rng)
+		if !spec.Regroup.Allows(op, a, b, spec.Index) {
+			continue
+		}
+		if spec.Level == 0 || Stars(mathDifficulty(op, a, b, result, spec.Unknown, grade).mathScore()) == spec.Level {
+			return a, b, result
+		}
+		if fallback == nil {
+			fallback = []int{a, b, result}
+		}
+	}
+	if fallback != nil {
+		return fallback[0], fallback[1], fallback[2]
+	}
+	return a, b, result
+}
+
+// LevelRange is the size a problem at level stars should have: numbers up
+// to hi, and no lower than lo. Prompts give it to the model.
+func LevelRange(op string, grade, level int) (lo, hi int) {
+	top := factRange(grade, NormalizeOperation(op))
+	if level <= 0 {
+		return 1, top
+	}
+	return max(1, top*(level-1)/3), top * level / 3
+}
+
+// ---------- validator ----------
+
+// ProgressionValidator checks that each problem is near the difficulty its
+// slot was planned at.
+type ProgressionValidator struct{}
+
+func (ProgressionValidator) Name() string { return "progression" }
+
+func (v ProgressionValidator) Validate(ps *ProblemSet) []Issue {
+	levels := DifficultyLevels(ps.MetaInfo.NumProblems, ps.MetaInfo.Progression)
+	return eachProblem(ps, v.Name(), func(p Problem) (Severity, string) {
+		slot := PlannedSlot(p)
+		if p.Difficulty == 0 || slot < 1 || slot > len(levels) {
+			return "", ""
+		}
+		if want := levels[slot-1]; p.Difficulty-want >= 2 || want-p.Difficulty >= 2 {
+			return SeverityWarning, fmt.Sprintf("is %s, planned as a %s problem", StarString(p.Difficulty), levelName(want))
+		}
+		return "", ""
+	})
+}
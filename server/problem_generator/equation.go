@@ -0,0 +1,235 @@
+package problemgenerator
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+
+	pb "github.com/qjs/mathgen_gemma/server/proto"
+)
+
+// Slots of "a op b = result" that can be left unknown.
+const (
+	SlotA      = "a"
+	SlotB      = "b"
+	SlotResult = "result"
+)
+
+// Blank marks the unknown slot in Problem.Equation. Templates swap it for an
+// empty answer box.
+const Blank = "?"
+
+var opSymbols = map[string]string{
+	OpAddition:       "+",
+	OpSubtraction:    "−",
+	"multiplication": "×",
+	"division":       "÷",
+}
+
+// OpSymbol returns the math symbol for an operation ("+", "−", "×", "÷").
+func OpSymbol(op string) string { return opSymbols[NormalizeOperation(op)] }
+
+// normalizeSlot maps the model's story vocabulary (start/change/result)
+// onto equation slots.
+func normalizeSlot(s string) string {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "a", "start", "first", "first_number":
+		return SlotA
+	case "b", "change", "second", "second_number":
+		return SlotB
+	case "result", "answer", "end", "total", "":
+		return SlotResult
+	}
+	return strings.ToLower(strings.TrimSpace(s))
+}
+
+// solveMissing fills in the unknown slot of "a op b = result" from the two
+// known values, given in equation order. Every slot must come out as a whole
+// number that is not negative.
+func solveMissing(op, unknown string, known [2]int) (a, b, result int, err error) {
+	op = NormalizeOperation(op)
+	if _, ok := opSymbols[op]; !ok {
+		return 0, 0, 0, fmt.Errorf("unknown operation %q", op)
+	}
+	x, y := known[0], known[1]
+	notWhole := errors.New("missing number is not a whole number")
+
+	switch normalizeSlot(unknown) {
+	case SlotA: // known holds b, then result
+		b, result = x, y
+		switch op {
+		case OpAddition:
+			a = result - b
+		case OpSubtraction:
+			a = result + b
+		case "multiplication":
+			if b == 0 || result%b != 0 {
+				return 0, 0, 0, notWhole
+			}
+			a = result / b
+		case "division":
+			a = result * b
+		}
+	case SlotB: // known holds a, then result
+		a, result = x, y
+		switch op {
+		case OpAddition:
+			b = result - a
+		case OpSubtraction:
+			b = a - result
+		case "multiplication":
+			if a == 0 || result%a != 0 {
+				return 0, 0, 0, notWhole
+			}
+			b = result / a
+		case "division":
+			if result == 0 || a%result != 0 {
+				return 0, 0, 0, notWhole
+			}
+			b = a / result
+		}
+	case SlotResult:
+		a, b = x, y
+		ans, err := computeAnswer(op, a, b)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		result, _ = strconv.Atoi(ans)
+	default:
+		return 0, 0, 0, fmt.Errorf("unknown slot %q", unknown)
+	}
+	if a < 0 || b < 0 || result < 0 {
+		return 0, 0, 0, errors.New("missing number would be negative")
+	}
+	if op == "division" && (b == 0 || a%b != 0) {
+		return 0, 0, 0, notWhole
+	}
+	return a, b, result, nil
+}
+
+// formatEquation writes "a op b = result" with Blank in the unknown slot.
+func formatEquation(op, unknown string, a, b, result int) string {
+	parts := []string{strconv.Itoa(a), strconv.Itoa(b), strconv.Itoa(result)}
+	switch normalizeSlot(unknown) {
+	case SlotA:
+		parts[0] = Blank
+	case SlotB:
+		parts[1] = Blank
+	case SlotResult:
+		parts[2] = Blank
+	}
+	return fmt.Sprintf("%s %s %s = %s", parts[0], OpSymbol(op), parts[1], parts[2])
+}
+
+// solveMissingStory reads the two known numbers of a missing-number story in
+// the order they appear and solves for the unknown slot.
+func solveMissingStory(text, op, unknown string) (nums []int, answer, equation string, err error) {
+	numStrs := reInts.FindAllString(text, -1)
+	if len(numStrs) != 2 {
+		return nil, "", "", fmt.Errorf("missing-number story needs exactly two numbers, found %d", len(numStrs))
+	}
+	x, _ := strconv.Atoi(numStrs[0])
+	y, _ := strconv.Atoi(numStrs[1])
+	a, b, result, err := solveMissing(op, unknown, [2]int{x, y})
+	if err != nil {
+		return nil, "", "", err
+	}
+	return []int{a, b, result}, slotValue(unknown, a, b, result), formatEquation(op, unknown, a, b, result), nil
+}
+
+func slotValue(slot string, a, b, result int) string {
+	switch normalizeSlot(slot) {
+	case SlotA:
+		return strconv.Itoa(a)
+	case SlotB:
+		return strconv.Itoa(b)
+	}
+	return strconv.Itoa(result)
+}
+
+// factRange is the largest operand (add/sub: largest result) used in bare
+// equations for a grade.
+func factRange(grade int, op string) int {
+	if op == "multiplication" || op == "division" {
+		if grade >= 4 {
+			return 12
+		}
+		return 10
+	}
+	switch {
+	case grade <= 0:
+		return 10
+	case grade == 1:
+		return 20
+	case grade <= 3:
+		return 100
+	}
+	return 1000
+}
+
+// pickOperands picks a op b = result with every value a whole number that is
+// not negative. max bounds the result for addition, a for subtraction and
+// both factors for multiplication and division.
+func pickOperands(op string, max int, rng *rand.Rand) (a, b, result int) {
+	switch op {
+	case OpAddition:
+		result = 1 + rng.IntN(max)
+		a = rng.IntN(result + 1)
+		b = result - a
+	case OpSubtraction:
+		a = 1 + rng.IntN(max)
+		b = rng.IntN(a + 1)
+		result = a - b
+	case "multiplication":
+		a, b = 1+rng.IntN(max), 1+rng.IntN(max)
+		result = a * b
+	case "division":
+		b, result = 1+rng.IntN(max), 1+rng.IntN(max)
+		a = b * result
+	}
+	return a, b, result
+}
+
+// GenerateEquations builds a set of bare equations ("? + 4 = 9") without the
+// model. Kindergarten always solves for the result; from 1st grade on the
+// blank can be in any slot.
+func GenerateEquations(req *pb.GenerateRequest, rng *rand.Rand) (*ProblemSet, error) {
+	ops, err := OperationSlots(req)
+	if err != nil {
+		return nil, err
+	}
+	if rng == nil {
+		rng = rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+	}
+	grade := GradeNumber(req.GradeLevel)
+	slots := []string{SlotA, SlotB, SlotResult}
+	specs := slotSpecs(req, len(ops))
+
+	problems := make([]Problem, 0, len(ops))
+	for i, op := range ops {
+		if _, ok := opSymbols[op]; !ok {
+			return nil, fmt.Errorf("equations need addition, subtraction, multiplication or division, got %q", req.Operation)
+		}
+		slot := SlotResult
+		if grade >= 1 {
+			slot = slots[rng.IntN(len(slots))]
+		}
+		spec := specs[i]
+		spec.Unknown = slot
+		a, b, result := pickFor(op, grade, spec, rng)
+		eq := formatEquation(op, slot, a, b, result)
+		problems = append(problems, Problem{
+			Index:     i + 1,
+			Text:      eq,
+			Numbers:   []int{a, b, result},
+			Operation: op,
+			Answer:    slotValue(slot, a, b, result),
+			Kind:      KindEquation,
+			Unknown:   slot,
+			Equation:  eq,
+		})
+	}
+	return &ProblemSet{Problems: problems, MetaInfo: metaFromRequest(req)}, nil
+}
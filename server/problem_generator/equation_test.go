@@ -0,0 +1,43 @@
+package problemgenerator
+
+import "testing"
+
+func TestSolveMissing(t *testing.T) {
+	tests := []struct {
+		op, unknown string
+		known       [2]int
+		want        [3]int // a, b, result
+		answer      string
+		err         bool
+	}{
+		{op: "add", unknown: "a", known: [2]int{4, 9}, want: [3]int{5, 4, 9}, answer: "5"},
+		{op: "add", unknown: "change", known: [2]int{3, 10}, want: [3]int{3, 7, 10}, answer: "7"},
+		{op: "add", unknown: "", known: [2]int{6, 7}, want: [3]int{6, 7, 13}, answer: "13"},
+		{op: "sub", unknown: "start", known: [2]int{5, 8}, want: [3]int{13, 5, 8}, answer: "13"},
+		{op: "sub", unknown: "b", known: [2]int{12, 4}, want: [3]int{12, 8, 4}, answer: "8"},
+		{op: "multiplication", unknown: "a", known: [2]int{3, 12}, want: [3]int{4, 3, 12}, answer: "4"},
+		{op: "division", unknown: "b", known: [2]int{20, 5}, want: [3]int{20, 4, 5}, answer: "4"},
+		{op: "division", unknown: "a", known: [2]int{3, 6}, want: [3]int{18, 3, 6}, answer: "18"},
+		{op: "add", unknown: "a", known: [2]int{9, 4}, err: true},             // negative
+		{op: "sub", unknown: "b", known: [2]int{4, 12}, err: true},            // negative
+		{op: "multiplication", unknown: "a", known: [2]int{5, 12}, err: true}, // not whole
+		{op: "division", unknown: "b", known: [2]int{7, 0}, err: true},
+		{op: "add", unknown: "middle", known: [2]int{1, 2}, err: true},
+	}
+	for _, tt := range tests {
+		a, b, result, err := solveMissing(tt.op, tt.unknown, tt.known)
+		if (err != nil) != tt.err {
+			t.Errorf("solveMissing(%s, %q, %v) error = %v, want error %v", tt.op, tt.unknown, tt.known, err, tt.err)
+			continue
+		}
+		if tt.err {
+			continue
+		}
+		if got := [3]int{a, b, result}; got != tt.want {
+			t.Errorf("solveMissing(%s, %q, %v) = %v, want %v", tt.op, tt.unknown, tt.known, got, tt.want)
+		}
+		if got := slotValue(tt.unknown, a, b, result); got != tt.answer {
+			t.Errorf("slotValue(%q) = %q, want %q", tt.unknown, got, tt.answer)
+		}
+	}
+}
@@ -32,6 +32,9 @@ func NewCSVAgent() *CSVAgent { return &CSVAgent{} }
 // CSV output is as such
 // "Index","theme","text","operation","num1","num2"
 func (a *CSVAgent) Parse(llmOut string, req *pb.GenerateRequest) (*ProblemSet, error) {
+	if kind := NormalizeKind(req.Kind); kind != KindArithmetic {
+		return nil, fmt.Errorf("CSVAgent: %s problems are not supported", kind)
+	}
 	r := csv.NewReader(strings.NewReader(llmOut))
 	r.TrimLeadingSpace = true
 	r.FieldsPerRecord = -1
@@ -50,6 +53,7 @@ func (a *CSVAgent) Parse(llmOut string, req *pb.GenerateRequest) (*ProblemSet, e
 	}
 
 	var problems []Problem
+	var failed ParseErrors
 	recN := 0
 	for {
 		rec, err := r.Read()
@@ -61,39 +65,40 @@ func (a *CSVAgent) Parse(llmOut string, req *pb.GenerateRequest) (*ProblemSet, e
 		}
 
 		recN++
+		slot := recN
+		if idx, err := safeAtoi(rec[0]); err == nil && idx > 0 {
+			slot = idx
+		}
 		if len(rec) < 6 {
-			return nil, fmt.Errorf("line %d: need 6 columns, got %d", recN, len(rec))
+			failed = append(failed, ProblemError{slot, fmt.Errorf("line %d: need 6 columns, got %d", recN, len(rec))})
+			continue
 		}
 
 		// ---------- pull index and two ints for value & compute answer --------------
-		idx, aNum, bNum, answer, err := extractNumbersAndAnswer(rec, strings.ToLower(req.Operation))
+		op := strings.ToLower(req.Operation)
+		if op == OpMixed { // each row says its own operation
+			op = NormalizeOperation(strings.Trim(rec[3], "\"”“ "))
+		}
+		_, aNum, bNum, answer, err := extractNumbersAndAnswer(rec, op)
 		if err != nil {
-			return nil, fmt.Errorf("unable to extract numbers and answer")
+			failed = append(failed, ProblemError{slot, fmt.Errorf("unable to extract numbers and answer")})
+			continue
 		}
 		problems = append(problems, Problem{
-			Index:     idx,
+			Index:     slot,
 			Theme:     strings.TrimSpace(rec[1]),
 			Text:      strings.TrimSpace(rec[2]),
 			Numbers:   []int{aNum, bNum},
-			Operation: strings.ToLower(req.Operation),
+			Operation: op,
 			Answer:    answer,
 		})
 	}
 
-	if len(problems) == 0 {
+	if len(problems) == 0 && len(failed) == 0 {
 		return nil, errEmptyCSV
 	}
 
-	meta := GenerateRequest{
-		Name:        req.Name,
-		Gender:      req.Gender,
-		Operation:   req.Operation,
-		NumProblems: int(req.NumProblems),
-		GradeLevel:  req.GradeLevel,
-		LikesNouns:  req.LikesNouns,
-		LikesVerbs:  req.LikesVerbs,
-	}
-	return &ProblemSet{Problems: problems, MetaInfo: meta}, nil
+	return partial(&ProblemSet{Problems: problems, MetaInfo: metaFromRequest(req)}, failed)
 }
 
 func NewJSONAgent() *JSONAgent { return &JSONAgent{} }
@@ -105,47 +110,115 @@ func (a *JSONAgent) Parse(llmOut string, req *pb.GenerateRequest) (*ProblemSet,
 	clean := cleanCodeBlock(llmOut)
 
 	var raw []struct {
-		Index     int    `json:"index"`
-		Theme     string `json:"theme"`
-		Text      string `json:"text"`
-		Operation string `json:"operation"`
+		Index     int      `json:"index"`
+		Theme     string   `json:"theme"`
+		Text      string   `json:"text"`
+		Operation string   `json:"operation"`
+		Unit      string   `json:"unit"`    // measurement: unit the answer is asked in
+		Names     []string `json:"names"`   // comparison: the two characters compared
+		Unknown   string   `json:"unknown"` // missing_number: start | change | result
 	}
 	if err := json.Unmarshal([]byte(clean), &raw); err != nil {
 		return nil, fmt.Errorf("JSONAgent: failed to parse LLM JSON: %w", err)
 	}
 
+	kind := NormalizeKind(req.Kind)
+	policy := PolicyFor(req.GradeLevel, req.UnitSystem)
+	meta := metaFromRequest(req)
+
 	var problems []Problem
-	for _, rp := range raw {
-		aNum, bNum, answer, err := extractNumAnswerText(rp.Text, rp.Operation)
-		if err != nil {
-			return nil, fmt.Errorf("failed to extract numbers: %v", err)
+	var failed ParseErrors
+	for i, rp := range raw {
+		slot := rp.Index
+		if slot <= 0 {
+			slot = i + 1
 		}
-		problems = append(problems, Problem{
-			Index:     rp.Index,
+		p := Problem{
+			Index:     slot,
 			Theme:     rp.Theme,
 			Text:      rp.Text,
-			Numbers:   []int{aNum, bNum},
 			Operation: rp.Operation,
-			Answer:    answer,
-		})
+			Kind:      kind,
+		}
+		text := numberText(rp.Text, meta.Language) // the solvers read digits
+		switch kind {
+		case KindArithmetic:
+			aNum, bNum, answer, err := extractNumAnswerText(text, rp.Operation)
+			if err != nil {
+				failed = append(failed, ProblemError{slot, fmt.Errorf("failed to extract numbers: %v", err)})
+				continue
+			}
+			p.Numbers, p.Answer = []int{aNum, bNum}, answer
+		case KindMeasurement:
+			nums, answer, accept, err := solveMeasurement(text, rp.Operation, rp.Unit, policy)
+			if err != nil {
+				failed = append(failed, ProblemError{slot, err})
+				continue
+			}
+			p.Operation = NormalizeOperation(rp.Operation)
+			p.Numbers, p.Answer, p.Accept = nums, answer, accept
+		case KindComparison:
+			nums, answer, choices, err := solveComparison(text, rp.Operation, rp.Names)
+			if err != nil {
+				failed = append(failed, ProblemError{slot, err})
+				continue
+			}
+			p.Operation = normalizeComparisonOp(rp.Operation)
+			p.Numbers, p.Answer, p.Choices = nums, answer, choices
+		case KindMissing:
+			nums, answer, equation, err := solveMissingStory(text, rp.Operation, rp.Unknown)
+			if err != nil {
+				failed = append(failed, ProblemError{slot, err})
+				continue
+			}
+			p.Operation = NormalizeOperation(rp.Operation)
+			p.Numbers, p.Answer = nums, answer
+			p.Unknown, p.Equation = normalizeSlot(rp.Unknown), equation
+		default:
+			return nil, fmt.Errorf("JSONAgent: unknown problem kind %q", kind)
+		}
+		problems = append(problems, p)
 	}
-	meta := GenerateRequest{
+	return partial(&ProblemSet{Problems: problems, MetaInfo: meta}, failed)
+}
+
+// ----------------------------- helpers --------------------------------
+// metaFromRequest copies the request into the ProblemSet's MetaInfo.
+func metaFromRequest(req *pb.GenerateRequest) GenerateRequest {
+	return GenerateRequest{
 		Name:        req.Name,
 		Gender:      req.Gender,
+		Pronouns:    NormalizePronouns(req.Pronouns, req.Gender),
 		Operation:   req.Operation,
 		NumProblems: int(req.NumProblems),
 		GradeLevel:  req.GradeLevel,
 		LikesNouns:  req.LikesNouns,
 		LikesVerbs:  req.LikesVerbs,
+		Kind:        NormalizeKind(req.Kind),
+		UnitSystem:  req.UnitSystem,
+
+		InterestBalance: NormalizeBalance(req.InterestBalance),
+		Operations:      operationShares(req.Operations),
+		Arrange:         NormalizeArrange(req.Arrange),
+		Progression:     NormalizeProgression(req.Progression),
+		Regrouping:      normalizeRegrouping(req.Regrouping),
+		Language:        NormalizeLanguage(req.Language),
+		Agent:           req.Agent,
 	}
-	return &ProblemSet{Problems: problems, MetaInfo: meta}, nil
 }
 
-// ----------------------------- helpers --------------------------------
+func operationShares(shares []*pb.OperationShare) []OperationShare {
+	var out []OperationShare
+	for _, s := range shares {
+		out = append(out, OperationShare{Operation: NormalizeOperation(s.Operation), Count: int(s.Count), Percent: int(s.Percent)})
+	}
+	return out
+}
+
 func extractNumAnswerText(text string, op string) (int, int, string, error) {
 	numStrs := reInts.FindAllString(text, 2)
 	if len(numStrs) < 2 {
-		return 0, 0, "N/A", fmt.Errorf("problem %d: could not find two integers in text")
+		return 0, 0, "N/A", fmt.Errorf("could not find two integers in %q", text)
 	}
 	aNum, _ := strconv.Atoi(numStrs[0])
 	bNum, _ := strconv.Atoi(numStrs[1])
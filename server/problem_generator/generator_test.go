@@ -0,0 +1,103 @@
+package problemgenerator
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	pb "github.com/qjs/mathgen_gemma/server/proto"
+)
+
+func TestParseIndexesBySlot(t *testing.T) {
+	req := &pb.GenerateRequest{Name: "Ana", Operation: "add", Kind: KindArithmetic, NumProblems: 3}
+	tests := []struct {
+		name   string
+		agent  Agent
+		out    string
+		read   []int
+		failed []int
+	}{
+		{
+			name:  "json numbered by the model",
+			agent: NewJSONAgent(),
+			out: `[{"index": 1, "text": "Ana has 2 cats and gets 3 more. How many?", "operation": "add"},
+				{"index": 2, "text": "Ana has many cats. How many?", "operation": "add"},
+				{"index": 3, "text": "Ana has 4 cats and gets 1 more. How many?", "operation": "add"}]`,
+			read:   []int{1, 3},
+			failed: []int{2},
+		},
+		{
+			name:  "json without indexes",
+			agent: NewJSONAgent(),
+			out: `[{"text": "Ana has many cats. How many?", "operation": "add"},
+				{"text": "Ana has 2 cats and gets 3 more. How many?", "operation": "add"},
+				{"text": "Ana has 4 cats and gets 1 more. How many?", "operation": "add"}]`,
+			read:   []int{2, 3},
+			failed: []int{1},
+		},
+		{
+			name:   "csv with a short row",
+			agent:  NewCSVAgent(),
+			out:    "index,theme,text,operation,num1,num2\n1,cats,Ana has 2 cats and 3 more.,add,2,3\n2,cats,Ana has cats\nproblem 3,cats,Ana has 4 cats and 1 more.,add,4,1\n",
+			read:   []int{1, 3},
+			failed: []int{2},
+		},
+		{
+			name:   "csv without indexes",
+			agent:  NewCSVAgent(),
+			out:    "index,theme,text,operation,num1,num2\n,cats,Ana has cats\n,cats,Ana has 2 cats and 3 more.,add,2,3\n,cats,Ana has 4 cats and 1 more.,add,4,1\n",
+			read:   []int{2, 3},
+			failed: []int{1},
+		},
+	}
+	for _, tt := range tests {
+		ps, err := tt.agent.Parse(tt.out, req)
+		var failed ParseErrors
+		if !errors.As(err, &failed) || ps == nil {
+			t.Errorf("%s: Parse = %v, %v; want a partial set", tt.name, ps, err)
+			continue
+		}
+		var read []int
+		for _, p := range ps.Problems {
+			read = append(read, p.Index)
+		}
+		if !slices.Equal(read, tt.read) || !slices.Equal(failed.Indexes(), tt.failed) {
+			t.Errorf("%s: read %v, failed %v; want %v and %v", tt.name, read, failed.Indexes(), tt.read, tt.failed)
+		}
+	}
+}
+
+func TestParseShortReply(t *testing.T) {
+	req := &pb.GenerateRequest{Name: "Ana", Operation: "add", Kind: KindArithmetic, NumProblems: 4}
+	tests := []struct {
+		name   string
+		agent  Agent
+		out    string
+		failed []int
+		empty  bool
+	}{
+		{
+			name:   "json",
+			agent:  NewJSONAgent(),
+			out:    `[{"index": 1, "text": "Ana has 2 cats and gets 3 more. How many?", "operation": "add"}, {"index": 3, "text": "Ana has many cats.", "operation": "add"}]`,
+			failed: []int{2, 3, 4},
+		},
+		{
+			name:   "csv",
+			agent:  NewCSVAgent(),
+			out:    "index,theme,text,operation,num1,num2\n1,cats,Ana has 2 cats and 3 more.,add,2,3\n2,cats,Ana has 4 cats and 1 more.,add,4,1\n",
+			failed: []int{3, 4},
+		},
+		{name: "nothing written", agent: NewJSONAgent(), out: `[]`, failed: []int{1, 2, 3, 4}, empty: true},
+	}
+	for _, tt := range tests {
+		ps, err := tt.agent.Parse(tt.out, req)
+		var failed ParseErrors
+		if !errors.As(err, &failed) || !slices.Equal(failed.Indexes(), tt.failed) || (ps == nil) != tt.empty {
+			t.Errorf("%s: Parse = %v, %v; want slots %v to fail", tt.name, ps, err, tt.failed)
+		}
+	}
+	if got := (ParseErrors{{Index: 1}, {Index: 3}, {Index: 4}}).Only([]int{3, 5}); len(got) != 1 || got[0].Index != 3 {
+		t.Errorf("Only = %v, want just slot 3", got)
+	}
+}
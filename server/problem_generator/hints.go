@@ -0,0 +1,261 @@
+package problemgenerator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MaxHints is how many hints a problem gets at most.
+const MaxHints = 3
+
+// hintWords are the phrases hints are written with, from the gentlest
+// (what is asked) to the strongest (how to work it out).
+type hintWords struct {
+	Question string // the question
+	Numbers  string // the numbers, listed
+	And      string
+
+	Draw map[string]string // by operation, for numbers small enough to draw
+	Work map[string]string // by operation, for bigger numbers
+
+	Missing string // the equation with its blank
+	Undo    string
+	Compare string
+	Order   string // comparing big numbers
+}
+
+var hintPhrases = map[string]hintWords{
+	"en": {
+		Question: "What do you need to find? Read the question again: %s",
+		Numbers:  "Find the numbers in the story: %s.",
+		And:      "and",
+		Draw: map[string]string{
+			OpAddition:       "You are putting groups together, so add. Try drawing a dot for each thing and counting them all.",
+			OpSubtraction:    "Some are taken away or compared, so subtract. Try drawing the first amount and crossing out the second.",
+			"multiplication": "There are equal groups, so multiply. Try drawing the groups and counting everything.",
+			"division":       "Things are shared out equally, so divide. Try drawing them and sharing them into equal groups.",
+		},
+		Work: map[string]string{
+			OpAddition:       "You are putting groups together, so add. Write the numbers one under the other and add each column, starting with the ones.",
+			OpSubtraction:    "Some are taken away or compared, so subtract. Write the bigger number on top and subtract each column, starting with the ones.",
+			"multiplication": "There are equal groups, so multiply. Split the bigger number into tens and ones and multiply each part.",
+			"division":       "Things are shared out equally, so divide. Think: what times the size of a group makes the total?",
+		},
+		Missing: "Write it as an equation with a box for the number you don't know: %s.",
+		Undo:    "Undo the operation to find the number in the box.",
+		Compare: "Which amount is bigger? Try drawing both amounts as towers of blocks and comparing them.",
+		Order:   "Which amount is bigger? Compare the digits from the left, starting with the biggest place.",
+	},
+	"es": {
+		Question: "¿Qué tienes que averiguar? Vuelve a leer la pregunta: %s",
+		Numbers:  "Busca los números del problema: %s.",
+		And:      "y",
+		Draw: map[string]string{
+			OpAddition:       "Estás juntando grupos, así que suma. Dibuja un punto por cada cosa y cuéntalos todos.",
+			OpSubtraction:    "Se quitan o se comparan cantidades, así que resta. Dibuja la primera cantidad y tacha la segunda.",
+			"multiplication": "Hay grupos iguales, así que multiplica. Dibuja los grupos y cuenta todo.",
+			"division":       "Se reparte en partes iguales, así que divide. Dibuja las cosas y repártelas en grupos iguales.",
+		},
+		Work: map[string]string{
+			OpAddition:       "Estás juntando grupos, así que suma. Escribe un número debajo del otro y suma cada columna, empezando por las unidades.",
+			OpSubtraction:    "Se quitan o se comparan cantidades, así que resta. Escribe el número mayor arriba y resta cada columna, empezando por las unidades.",
+			"multiplication": "Hay grupos iguales, así que multiplica. Separa el número mayor en decenas y unidades y multiplica cada parte.",
+			"division":       "Se reparte en partes iguales, así que divide. Piensa: ¿qué número por el tamaño del grupo da el total?",
+		},
+		Missing: "Escríbelo como una ecuación con una casilla para el número que no sabes: %s.",
+		Undo:    "Haz la operación contraria para hallar el número de la casilla.",
+		Compare: "¿Qué cantidad es mayor? Dibuja las dos cantidades como torres de bloques y compáralas.",
+		Order:   "¿Qué cantidad es mayor? Compara las cifras de izquierda a derecha, empezando por el lugar más grande.",
+	},
+	"fr": {
+		Question: "Que dois-tu trouver ? Relis la question : %s",
+		Numbers:  "Repère les nombres de l'énoncé : %s.",
+		And:      "et",
+		Draw: map[string]string{
+			OpAddition:       "On réunit des groupes, donc on additionne. Dessine un point pour chaque chose et compte-les tous.",
+			OpSubtraction:    "On enlève ou on compare, donc on soustrait. Dessine la première quantité et barre la seconde.",
+			"multiplication": "Il y a des groupes égaux, donc on multiplie. Dessine les groupes et compte tout.",
+			"division":       "On partage en parts égales, donc on divise. Dessine les choses et partage-les en groupes égaux.",
+		},
+		Work: map[string]string{
+			OpAddition:       "On réunit des groupes, donc on additionne. Écris les nombres l'un sous l'autre et additionne chaque colonne, en commençant par les unités.",
+			OpSubtraction:    "On enlève ou on compare, donc on soustrait. Écris le plus grand nombre en haut et soustrais chaque colonne, en commençant par les unités.",
+			"multiplication": "Il y a des groupes égaux, donc on multiplie. Décompose le plus grand nombre en dizaines et unités et multiplie chaque partie.",
+			"division":       "On partage en parts égales, donc on divise. Cherche : quel nombre fois la taille d'un groupe donne le total ?",
+		},
+		Missing: "Écris-le comme une équation avec une case pour le nombre inconnu : %s.",
+		Undo:    "Fais l'opération inverse pour trouver le nombre de la case.",
+		Compare: "Quelle quantité est la plus grande ? Dessine les deux quantités comme des tours de cubes et compare-les.",
+		Order:   "Quelle quantité est la plus grande ? Compare les chiffres de gauche à droite, en commençant par le plus grand rang.",
+	},
+	"vi": {
+		Question: "Em cần tìm gì? Đọc lại câu hỏi: %s",
+		Numbers:  "Tìm các số trong bài: %s.",
+		And:      "và",
+		Draw: map[string]string{
+			OpAddition:       "Bài toán gộp các nhóm lại, nên em làm phép cộng. Hãy vẽ mỗi đồ vật một chấm rồi đếm tất cả.",
+			OpSubtraction:    "Bài toán bớt đi hoặc so sánh, nên em làm phép trừ. Hãy vẽ số thứ nhất rồi gạch bớt số thứ hai.",
+			"multiplication": "Có các nhóm bằng nhau, nên em làm phép nhân. Hãy vẽ các nhóm rồi đếm tất cả.",
+			"division":       "Chia đều cho mọi người, nên em làm phép chia. Hãy vẽ các đồ vật rồi chia thành các nhóm bằng nhau.",
+		},
+		Work: map[string]string{
+			OpAddition:       "Bài toán gộp các nhóm lại, nên em làm phép cộng. Viết các số thẳng cột rồi cộng từng cột, bắt đầu từ hàng đơn vị.",
+			OpSubtraction:    "Bài toán bớt đi hoặc so sánh, nên em làm phép trừ. Viết số lớn ở trên rồi trừ từng cột, bắt đầu từ hàng đơn vị.",
+			"multiplication": "Có các nhóm bằng nhau, nên em làm phép nhân. Tách số lớn thành hàng chục và hàng đơn vị rồi nhân từng phần.",
+			"division":       "Chia đều cho mọi người, nên em làm phép chia. Hãy nghĩ: số nào nhân với số trong mỗi nhóm thì bằng tổng?",
+		},
+		Missing: "Viết thành phép tính, để một ô trống cho số chưa biết: %s.",
+		Undo:    "Làm phép tính ngược lại để tìm số trong ô trống.",
+		Compare: "Số nào lớn hơn? Hãy vẽ hai số thành hai tháp khối rồi so sánh.",
+		Order:   "Số nào lớn hơn? So sánh các chữ số từ trái sang phải, bắt đầu từ hàng lớn nhất.",
+	},
+}
+
+// reQuestion finds the sentences of a story that ask something.
+var reQuestion = regexp.MustCompile(`¿?[^.!?¿]*\?`)
+
+// drawLimit is the biggest number a child is asked to draw.
+const drawLimit = 20
+
+// AddHints gives every problem of ps up to MaxHints hints in its language,
+// from the gentlest to the strongest: what the question asks, which numbers
+// matter, and which operation to use or what to draw. A hint that would give
+// the answer away is left out.
+func AddHints(ps *ProblemSet) {
+	lang := NormalizeLanguage(ps.MetaInfo.Language)
+	w := hintPhrases[lang]
+	for i, p := range ps.Problems {
+		var hints []string
+		for _, h := range hintsFor(p, lang, w) {
+			if h != "" && !RevealsAnswer(p, h, lang) && len(hints) < MaxHints {
+				hints = append(hints, h)
+			}
+		}
+		ps.Problems[i].Hints = hints
+	}
+}
+
+func hintsFor(p Problem, lang string, w hintWords) []string {
+	var question, numbers string
+	if qs := reQuestion.FindAllString(p.Text, -1); len(qs) > 0 {
+		question = fmt.Sprintf(w.Question, strings.TrimSpace(qs[len(qs)-1]))
+	}
+	stated := reInts.FindAllString(numberText(p.Text, lang), -1)
+	if len(stated) > 0 && len(stated) <= 4 {
+		numbers = fmt.Sprintf(w.Numbers, listNumbers(stated, w.And))
+	}
+	small := true
+	for _, n := range reInts.FindAllString(numberText(p.Text, lang)+" "+p.Equation, -1) {
+		v, _ := strconv.Atoi(n)
+		small = small && v <= drawLimit
+	}
+
+	op := NormalizeOperation(p.Operation)
+	how := w.Work[op]
+	if small {
+		how = w.Draw[op]
+	}
+	undo := normalizeSlot(p.Unknown) != SlotResult
+	switch NormalizeKind(p.Kind) {
+	case KindArithmetic, KindMeasurement:
+	case KindMissing: // the story becomes an equation first
+		if p.Equation == "" {
+			break
+		}
+		how = fmt.Sprintf(w.Missing, p.Equation)
+		if undo {
+			how += " " + w.Undo
+		}
+	case KindEquation:
+		if undo {
+			how = w.Undo
+		}
+	case KindComparison:
+		switch {
+		case normalizeComparisonOp(p.Operation) == OpHowManyMore && small:
+			how = w.Draw[OpSubtraction]
+		case normalizeComparisonOp(p.Operation) == OpHowManyMore:
+			how = w.Work[OpSubtraction]
+		case small:
+			how = w.Compare
+		default:
+			how = w.Order
+		}
+	default:
+		how = ""
+	}
+	return []string{question, numbers, how}
+}
+
+func listNumbers(nums []string, and string) string {
+	if len(nums) == 1 {
+		return nums[0]
+	}
+	return strings.Join(nums[:len(nums)-1], ", ") + " " + and + " " + nums[len(nums)-1]
+}
+
+// RevealsAnswer reports whether hint gives p's answer away: it states a
+// number the problem does not, or the answer itself where the problem does
+// not show it.
+func RevealsAnswer(p Problem, hint, lang string) bool {
+	shown := strings.Join([]string{numberText(p.Text, lang), p.Equation, strings.Join(p.Sequence, " ")}, " ")
+	stated := map[string]bool{}
+	for _, n := range reInts.FindAllString(shown, -1) {
+		stated[n] = true
+	}
+	for _, n := range reInts.FindAllString(numberText(hint, lang), -1) {
+		if !stated[n] {
+			return true
+		}
+	}
+	answer := strings.ToLower(strings.TrimSpace(p.Answer))
+	return answer != "" && strings.Contains(strings.ToLower(hint), answer) && !strings.Contains(strings.ToLower(shown), answer)
+}
+
+// ---------- usage ----------
+
+// HintUsage is how many hints a student opened on each problem of a set
+// before checking their answers.
+type HintUsage struct {
+	Time     time.Time     `json:"time"`
+	Set      string        `json:"set"` // the set's ID, see Provenance
+	Student  string        `json:"student"`
+	Problems []ProblemHint `json:"problems"`
+}
+
+// ProblemHint is the hints opened on one problem, and whether it was then
+// answered right.
+type ProblemHint struct {
+	Index   int  `json:"index"`
+	Hints   int  `json:"hints"`
+	Correct bool `json:"correct"`
+}
+
+// HintLog writes hint usage as JSON lines.
+type HintLog struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewHintLog(w io.Writer) *HintLog { return &HintLog{w: w} }
+
+// Log records u. A nil HintLog drops it.
+func (l *HintLog) Log(u HintUsage) error {
+	if l == nil {
+		return nil
+	}
+	data, err := json.Marshal(u)
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, err = l.w.Write(append(data, '\n'))
+	return err
+}
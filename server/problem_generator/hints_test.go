@@ -0,0 +1,93 @@
+package problemgenerator
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+)
+
+func TestRevealsAnswer(t *testing.T) {
+	story := Problem{Kind: KindArithmetic, Operation: OpAddition, Text: "Ana has 3 cats and gets 4 more. How many cats now?", Answer: "7"}
+	equation := Problem{Kind: KindEquation, Operation: OpAddition, Text: "3 + ? = 10", Equation: "3 + ? = 10", Unknown: SlotB, Answer: "7"}
+	compare := Problem{Kind: KindComparison, Operation: OpWhoHasMore, Text: "Ana has 3 cats. Leo has 7 cats. Who has more?", Answer: "Leo"}
+	tests := []struct {
+		p    Problem
+		hint string
+		lang string
+		want bool
+	}{
+		{story, "Find the numbers in the story: 3 and 4.", "en", false},
+		{story, "Count on from 3: 4, 5, 6, 7.", "en", true},
+		{story, "So Ana has 7 cats.", "en", true},
+		{equation, "Undo the operation: 10 − 3.", "en", false},
+		{equation, "Start at 3 and count up to 10: that is 7 steps.", "en", true},
+		{compare, "Which amount is bigger? Try drawing both amounts.", "en", false},
+		{compare, "Leo has more cats than Ana.", "en", false}, // Leo is already in the story
+		{Problem{Kind: KindComparison, Operation: OpCompare, Text: "Compare 12 and 7.", Answer: ">"}, "12 > 7", "en", true},
+		{Problem{Text: "Lan có 3 con mèo và 4 con chó.", Answer: "7"}, "Lan có bảy con vật.", "vi", true},
+	}
+	for _, tt := range tests {
+		if got := RevealsAnswer(tt.p, tt.hint, tt.lang); got != tt.want {
+			t.Errorf("RevealsAnswer(%q, %q) = %v, want %v", tt.p.Text, tt.hint, got, tt.want)
+		}
+	}
+}
+
+func TestAddHints(t *testing.T) {
+	ps := &ProblemSet{Problems: []Problem{
+		{Index: 1, Kind: KindArithmetic, Operation: OpAddition, Text: "Ana has 3 cats and gets 4 more. How many cats now?", Answer: "7"},
+		{Index: 2, Kind: KindArithmetic, Operation: OpSubtraction, Text: "Leo has 45 cards and gives away 27. How many are left?", Answer: "18"},
+		{Index: 3, Kind: KindMissing, Operation: OpAddition, Unknown: "change", Text: "Ana had 5 shells and found some more. Now she has 9. How many did she find?", Equation: "5 + ? = 9", Answer: "4"},
+		{Index: 4, Kind: KindPattern, Text: "Ana counts. What comes next?", Sequence: []string{"2", "4", "6", Blank}, Answer: "8"},
+	}}
+	AddHints(ps)
+	want := [][]string{
+		{
+			"What do you need to find? Read the question again: How many cats now?",
+			"Find the numbers in the story: 3 and 4.",
+			"You are putting groups together, so add. Try drawing a dot for each thing and counting them all.",
+		},
+		{
+			"What do you need to find? Read the question again: How many are left?",
+			"Find the numbers in the story: 45 and 27.",
+			"Some are taken away or compared, so subtract. Write the bigger number on top and subtract each column, starting with the ones.",
+		},
+		{
+			"What do you need to find? Read the question again: How many did she find?",
+			"Find the numbers in the story: 5 and 9.",
+			"Write it as an equation with a box for the number you don't know: 5 + ? = 9. Undo the operation to find the number in the box.",
+		},
+		{"What do you need to find? Read the question again: What comes next?"},
+	}
+	for i, p := range ps.Problems {
+		if !slices.Equal(p.Hints, want[i]) {
+			t.Errorf("problem %d hints:\n%q\nwant:\n%q", p.Index, p.Hints, want[i])
+		}
+		if len(p.Hints) > MaxHints {
+			t.Errorf("problem %d has %d hints", p.Index, len(p.Hints))
+		}
+	}
+
+	es := &ProblemSet{MetaInfo: GenerateRequest{Language: "es"}, Problems: []Problem{
+		{Index: 1, Kind: KindArithmetic, Operation: OpAddition, Text: "Ana tiene tres gatos y le dan cuatro más. ¿Cuántos gatos tiene?", Answer: "7"},
+	}}
+	AddHints(es)
+	if got := es.Problems[0].Hints; len(got) != 3 || got[1] != "Busca los números del problema: 3 y 4." {
+		t.Errorf("Spanish hints = %q", got)
+	}
+}
+
+func TestHintLog(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewHintLog(&buf)
+	if err := l.Log(HintUsage{Set: "set-1", Student: "Ana", Problems: []ProblemHint{{Index: 1, Hints: 2, Correct: true}}}); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte(`"problems":[{"index":1,"hints":2,"correct":true}]`)) || !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+		t.Errorf("HintLog wrote %s", buf.String())
+	}
+	var none *HintLog
+	if err := none.Log(HintUsage{}); err != nil {
+		t.Errorf("nil HintLog: %v", err)
+	}
+}
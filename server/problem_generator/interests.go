@@ -0,0 +1,151 @@
+package problemgenerator
+
+import (
+	"fmt"
+	"strings"
+
+	pb "github.com/qjs/mathgen_gemma/server/proto"
+)
+
+// How interests are shared out over the problems of a set.
+const (
+	BalanceRoundRobin = "round_robin" // every interest in turn
+	BalanceWeighted   = "weighted"    // earlier interests are favourites and get more problems
+)
+
+// NormalizeBalance maps form and API spellings to a Balance* constant.
+func NormalizeBalance(s string) string {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "weighted", "favorites", "favourites":
+		return BalanceWeighted
+	default:
+		return BalanceRoundRobin
+	}
+}
+
+// Coverage is which problems of a set are about one of the student's
+// interests.
+type Coverage struct {
+	Interest string `json:"interest"`
+	Problems []int  `json:"problems"` // indexes of the problems that mention it
+}
+
+// Interests are the student's likes, nouns first, without repeats.
+func Interests(meta GenerateRequest) []string {
+	var out []string
+	seen := map[string]bool{}
+	for _, s := range append(append([]string{}, meta.LikesNouns...), meta.LikesVerbs...) {
+		key := strings.ToLower(strings.TrimSpace(s))
+		if key != "" && !seen[key] {
+			seen[key] = true
+			out = append(out, strings.TrimSpace(s))
+		}
+	}
+	return out
+}
+
+// AssignInterests picks the interest each problem of req is about, as
+// req.InterestBalance says.
+func AssignInterests(req *pb.GenerateRequest) []string {
+	return assignInterests(Interests(metaFromRequest(req)), int(req.NumProblems), req.InterestBalance)
+}
+
+// assignInterests shares interests out over n problems. Round robin gives
+// every interest a turn; weighted gives the first of k interests k shares,
+// the second k-1 and so on, still spreading them through the set.
+func assignInterests(interests []string, n int, balance string) []string {
+	if len(interests) == 0 || n <= 0 {
+		return nil
+	}
+	if NormalizeBalance(balance) != BalanceWeighted {
+		slots := make([]string, 0, n)
+		for i := 0; i < n; i++ {
+			slots = append(slots, interests[i%len(interests)])
+		}
+		return slots
+	}
+	weights := make([]int, len(interests))
+	for i := range interests {
+		weights[i] = len(interests) - i
+	}
+	return interleave(interests, largestRemainder(n, weights))
+}
+
+// ApplyInterests records the interest each problem was asked to be about,
+// by index.
+func ApplyInterests(ps *ProblemSet, slots []string) {
+	for i := range ps.Problems {
+		if idx := ps.Problems[i].Index - 1; idx >= 0 && idx < len(slots) {
+			ps.Problems[i].Interest = slots[idx]
+		}
+	}
+}
+
+// Mentions reports whether text is about interest: every word of it must
+// appear, allowing plurals and -ing forms ("dinosaur" for "dinosaurs",
+// "dancing" for "dance").
+func Mentions(text, interest string) bool {
+	words := map[string]bool{}
+	for _, w := range reWord.FindAllString(strings.ToLower(text), -1) {
+		words[w] = true
+	}
+	want := reWord.FindAllString(strings.ToLower(interest), -1)
+	if len(want) == 0 {
+		return false
+	}
+	for _, w := range want {
+		found := false
+		for have := range words {
+			if isFamiliar(map[string]bool{w: true}, have) || isFamiliar(map[string]bool{have: true}, w) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// InterestCoverage lists, for every interest of the student, the problems
+// whose theme or text mention it.
+func InterestCoverage(ps *ProblemSet) []Coverage {
+	var out []Coverage
+	for _, interest := range Interests(ps.MetaInfo) {
+		c := Coverage{Interest: interest}
+		for _, p := range ps.Problems {
+			if Mentions(p.Theme+" "+p.Text, interest) {
+				c.Problems = append(c.Problems, p.Index)
+			}
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// ---------- validator ----------
+
+// InterestValidator checks that every story is about the interest it was
+// assigned, and notes interests no problem used.
+type InterestValidator struct{}
+
+func (InterestValidator) Name() string { return "interest" }
+
+func (v InterestValidator) Validate(ps *ProblemSet) []Issue {
+	issues := eachProblem(ps, v.Name(), func(p Problem) (Severity, string) {
+		if !isStory(p) || p.Interest == "" || Mentions(p.Theme+" "+p.Text, p.Interest) {
+			return "", ""
+		}
+		if !isEnglish(ps.MetaInfo) { // the story may name the interest in its own language
+			return SeverityInfo, fmt.Sprintf("may not be about %s", p.Interest)
+		}
+		return SeverityWarning, fmt.Sprintf("is not about %s", p.Interest)
+	})
+	for _, c := range InterestCoverage(ps) {
+		if len(c.Problems) == 0 {
+			issues = append(issues, Issue{Check: v.Name(), Severity: SeverityInfo, Message: fmt.Sprintf("no problem is about %s", c.Interest)})
+		}
+	}
+	return issues
+}
@@ -0,0 +1,79 @@
+package problemgenerator
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestAssignInterests(t *testing.T) {
+	tests := []struct {
+		interests []string
+		n         int
+		balance   string
+		want      []string
+	}{
+		{[]string{"cats", "trains"}, 5, "", []string{"cats", "trains", "cats", "trains", "cats"}},
+		{[]string{"cats", "trains", "soccer"}, 6, "weighted", []string{"cats", "trains", "soccer", "cats", "trains", "cats"}},
+		{[]string{"cats", "trains", "soccer"}, 4, "Favourites", []string{"cats", "trains", "soccer", "cats"}},
+		{[]string{"cats"}, 3, "weighted", []string{"cats", "cats", "cats"}},
+		{nil, 3, "", nil},
+		{[]string{"cats"}, 0, "", nil},
+	}
+	for _, tt := range tests {
+		if got := assignInterests(tt.interests, tt.n, tt.balance); !slices.Equal(got, tt.want) {
+			t.Errorf("assignInterests(%q, %d, %q) = %q, want %q", tt.interests, tt.n, tt.balance, got, tt.want)
+		}
+	}
+	meta := GenerateRequest{LikesNouns: []string{"Cats", " trains", "cats"}, LikesVerbs: []string{"dance", ""}}
+	if got, want := Interests(meta), []string{"Cats", "trains", "dance"}; !slices.Equal(got, want) {
+		t.Errorf("Interests = %q, want %q", got, want)
+	}
+}
+
+func TestMentions(t *testing.T) {
+	tests := []struct {
+		text, interest string
+		want           bool
+	}{
+		{"Ana has 3 dinosaurs.", "dinosaur", true},
+		{"Ana sees a dinosaur.", "dinosaurs", true},
+		{"Ana is dancing with 4 friends.", "dance", true},
+		{"Ana rides the space shuttle.", "space shuttles", true},
+		{"Ana rides a shuttle.", "space shuttles", false},
+		{"Ana catches a catfish.", "cats", false},
+		{"Ana has 3 cats.", "", false},
+	}
+	for _, tt := range tests {
+		if got := Mentions(tt.text, tt.interest); got != tt.want {
+			t.Errorf("Mentions(%q, %q) = %v, want %v", tt.text, tt.interest, got, tt.want)
+		}
+	}
+}
+
+func TestInterestCoverage(t *testing.T) {
+	ps := &ProblemSet{
+		MetaInfo: GenerateRequest{LikesNouns: []string{"cats", "trains", "soccer"}},
+		Problems: []Problem{
+			{Index: 1, Kind: KindArithmetic, Text: "Ana has 3 cats. How many?"},
+			{Index: 2, Kind: KindArithmetic, Theme: "trains", Text: "Ana counts 4 cars. How many?"},
+			{Index: 3, Kind: KindArithmetic, Text: "A cat sits on a train. How many?"},
+		},
+	}
+	ApplyInterests(ps, []string{"cats", "trains", "soccer"})
+	want := []Coverage{{"cats", []int{1, 3}}, {"trains", []int{2, 3}}, {"soccer", nil}}
+	got := InterestCoverage(ps)
+	if len(got) != len(want) {
+		t.Fatalf("InterestCoverage = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i].Interest != want[i].Interest || !slices.Equal(got[i].Problems, want[i].Problems) {
+			t.Errorf("InterestCoverage[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	issues := InterestValidator{}.Validate(ps)
+	if len(issues) != 2 || issues[0].Index != 3 || issues[0].Severity != SeverityWarning ||
+		issues[1].Index != 0 || issues[1].Severity != SeverityInfo {
+		t.Errorf("InterestValidator = %v, want a warning on problem 3 and a note about soccer", issues)
+	}
+}
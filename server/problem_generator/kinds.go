@@ -0,0 +1,105 @@
+package problemgenerator
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"regexp"
+	"strconv"
+	"strings"
+
+	pb "github.com/qjs/mathgen_gemma/server/proto"
+)
+
+// Problem kinds. An empty kind means KindArithmetic so requests and stored
+// problem sets from before kinds existed keep working.
+const (
+	KindArithmetic  = "arithmetic"
+	KindMeasurement = "measurement"
+	KindComparison  = "comparison"
+	KindMissing     = "missing_number" // story with an unknown start, change or result
+	KindEquation    = "equation"       // bare "? + 4 = 9", built without the model
+	KindPattern     = "pattern"        // growing and repeating sequences, built without the model
+	KindChart       = "chart"          // questions about a generated chart, built without the model
+	KindPlaceValue  = "place_value"    // digits, values and expanded form, built without the model
+	KindRounding    = "rounding"       // round to the nearest 10/100/1000, built without the model
+	KindEstimation  = "estimation"     // round, then add or subtract, built without the model
+)
+
+// NormalizeKind lower-cases kind and maps "" to KindArithmetic.
+func NormalizeKind(kind string) string {
+	kind = strings.ToLower(strings.TrimSpace(kind))
+	if kind == "" {
+		return KindArithmetic
+	}
+	return kind
+}
+
+// IsLocalKind reports whether problems of kind are built by the server alone,
+// with no model call.
+func IsLocalKind(kind string) bool {
+	switch NormalizeKind(kind) {
+	case KindEquation, KindPattern, KindChart, KindPlaceValue, KindRounding, KindEstimation:
+		return true
+	}
+	return false
+}
+
+// GenerateLocal builds a ProblemSet for a kind where IsLocalKind is true. A
+// nil rng uses a randomly seeded one.
+func GenerateLocal(req *pb.GenerateRequest, rng *rand.Rand) (*ProblemSet, error) {
+	switch kind := NormalizeKind(req.Kind); kind {
+	case KindEquation:
+		return GenerateEquations(req, rng)
+	case KindPattern:
+		return GeneratePatterns(req, rng)
+	case KindChart:
+		return GenerateCharts(req, rng)
+	case KindPlaceValue, KindRounding, KindEstimation:
+		return GeneratePlaceValue(req, rng)
+	default:
+		return nil, fmt.Errorf("%s problems need the model", kind)
+	}
+}
+
+// Canonical operation names. OpConvert only applies to measurement problems.
+const (
+	OpConvert     = "convert"
+	OpAddition    = "addition"
+	OpSubtraction = "subtraction"
+)
+
+// NormalizeOperation maps the spellings used by the web form and the model
+// ("Add", "+", "Multiply", …) onto the canonical operation names.
+func NormalizeOperation(op string) string {
+	switch strings.ToLower(strings.TrimSpace(op)) {
+	case "addition", "add", "+", "plus", "sum":
+		return OpAddition
+	case "subtraction", "subtract", "sub", "-", "minus":
+		return OpSubtraction
+	case "multiplication", "multiply", "mul", "*", "×", "times":
+		return "multiplication"
+	case "division", "divide", "div", "/", "÷":
+		return "division"
+	case "convert", "conversion", "converting":
+		return OpConvert
+	default:
+		return strings.ToLower(strings.TrimSpace(op))
+	}
+}
+
+var reGrade = regexp.MustCompile(`\d+`)
+
+// GradeNumber maps a GradeLevel such as "Kindergarten" or "2nd Grade" to a
+// number, 0 being kindergarten. Unrecognised levels count as 2nd grade, the
+// middle of the range the web form offers.
+func GradeNumber(level string) int {
+	l := strings.ToLower(strings.TrimSpace(level))
+	if strings.HasPrefix(l, "k") || strings.Contains(l, "kinder") {
+		return 0
+	}
+	if d := reGrade.FindString(l); d != "" {
+		n, _ := strconv.Atoi(d)
+		return n
+	}
+	return 2
+}
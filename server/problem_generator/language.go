@@ -0,0 +1,342 @@
+package problemgenerator
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	pb "github.com/qjs/mathgen_gemma/server/proto"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// Language is one a worksheet can be written in.
+type Language struct {
+	Tag    string // BCP 47, e.g. "es"
+	Name   string // in English, for prompts
+	Native string // in the language itself, for the web form
+}
+
+// Languages are the languages worksheets can be written in, English first.
+var Languages = []Language{
+	{Tag: "en", Name: "English", Native: "English"},
+	{Tag: "es", Name: "Spanish", Native: "Español"},
+	{Tag: "fr", Name: "French", Native: "Français"},
+	{Tag: "vi", Name: "Vietnamese", Native: "Tiếng Việt"},
+}
+
+var languageMatcher = func() language.Matcher {
+	tags := make([]language.Tag, len(Languages))
+	for i, l := range Languages {
+		tags[i] = language.Make(l.Tag)
+	}
+	return language.NewMatcher(tags)
+}()
+
+// ParseLanguage finds the worksheet language s names: a tag such as
+// "es-MX", or a name in English or the language itself. "" is English.
+func ParseLanguage(s string) (Language, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Languages[0], nil
+	}
+	for _, l := range Languages {
+		if strings.EqualFold(s, l.Name) || strings.EqualFold(s, l.Native) {
+			return l, nil
+		}
+	}
+	tag, err := language.Parse(s)
+	if err != nil {
+		return Language{}, fmt.Errorf("unknown language %q", s)
+	}
+	if _, i, conf := languageMatcher.Match(tag); conf != language.No {
+		return Languages[i], nil
+	}
+	return Language{}, fmt.Errorf("worksheets cannot be written in %s yet", s)
+}
+
+// NormalizeLanguage is the tag of the language s names, English when it
+// names none we support.
+func NormalizeLanguage(s string) string {
+	l, err := ParseLanguage(s)
+	if err != nil {
+		return Languages[0].Tag
+	}
+	return l.Tag
+}
+
+// ResolveLanguage checks the language of req and writes it as a tag.
+func ResolveLanguage(req *pb.GenerateRequest) error {
+	l, err := ParseLanguage(req.Language)
+	if err != nil {
+		return err
+	}
+	req.Language = l.Tag
+	return nil
+}
+
+// LanguageOf returns the language with tag, English when there is none.
+func LanguageOf(tag string) Language {
+	for _, l := range Languages {
+		if l.Tag == tag {
+			return l
+		}
+	}
+	return Languages[0]
+}
+
+// isEnglish reports whether meta's problems are written in English. The
+// checks that read the words of a story (reading level, pronouns, operation
+// cues) only know English.
+func isEnglish(meta GenerateRequest) bool {
+	return NormalizeLanguage(meta.Language) == "en"
+}
+
+// ---------- number formatting ----------
+
+// FormatNumber writes n the way lang groups digits: 1,234 in English,
+// 1.234 in Spanish, 1 234 in French.
+func FormatNumber(n int, lang string) string {
+	return message.NewPrinter(language.Make(NormalizeLanguage(lang))).Sprintf("%d", n)
+}
+
+// FormatAnswer writes a whole-number answer with FormatNumber and leaves
+// any other answer ("2 m 30 cm", "Sam") as it is.
+func FormatAnswer(answer, lang string) string {
+	n, err := strconv.Atoi(strings.TrimSpace(answer))
+	if err != nil {
+		return answer
+	}
+	return FormatNumber(n, lang)
+}
+
+// ---------- number words ----------
+
+// numberWords are the words for numbers in each language. Compounds that do
+// not add up word by word ("quatre-vingt", "veintidós") are listed whole.
+var numberWords = map[string]map[string]int{
+	"en": wordValues(0, 1, "zero one two three four five six seven eight nine ten eleven twelve thirteen fourteen fifteen sixteen seventeen eighteen nineteen",
+		20, 10, "twenty thirty forty fifty sixty seventy eighty ninety"),
+	"es": wordValues(0, 1, "cero uno dos tres cuatro cinco seis siete ocho nueve diez once doce trece catorce quince dieciséis diecisiete dieciocho diecinueve",
+		20, 1, "veinte veintiuno veintidós veintitrés veinticuatro veinticinco veintiséis veintisiete veintiocho veintinueve",
+		30, 10, "treinta cuarenta cincuenta sesenta setenta ochenta noventa",
+		100, 100, "ciento doscientos trescientos cuatrocientos quinientos seiscientos setecientos ochocientos novecientos",
+		200, 100, "doscientas trescientas cuatrocientas quinientas seiscientas setecientas ochocientas novecientas",
+		1, 0, "un una", 100, 0, "cien"),
+	"fr": wordValues(0, 1, "zéro un deux trois quatre cinq six sept huit neuf dix onze douze treize quatorze quinze seize dix-sept dix-huit dix-neuf",
+		20, 10, "vingt trente quarante cinquante soixante soixante-dix",
+		80, 0, "quatre-vingt quatre-vingts", 90, 0, "quatre-vingt-dix", 1, 0, "une"),
+	"vi": wordValues(0, 1, "không một hai ba bốn năm sáu bảy tám chín mười",
+		1, 0, "mốt", 4, 0, "tư", 5, 0, "lăm"),
+}
+
+// numberMultipliers multiply the number before them: "deux cents", "hai
+// mươi", "three hundred". From a thousand up they multiply all of it.
+var numberMultipliers = map[string]map[string]int{
+	"en": {"hundred": 100, "thousand": 1000},
+	"es": {"mil": 1000},
+	"fr": {"cent": 100, "cents": 100, "mille": 1000},
+	"vi": {"mươi": 10, "trăm": 100, "nghìn": 1000, "ngàn": 1000},
+}
+
+// numberJoiners may come between the words of one number: "treinta y dos",
+// "vingt et un", "một trăm linh năm".
+var numberJoiners = map[string]map[string]bool{
+	"en": {"and": true},
+	"es": {"y": true},
+	"fr": {"et": true},
+	"vi": {"linh": true, "lẻ": true},
+}
+
+// numberArticles are number words that alone mean "a". They only count
+// inside a longer number ("vingt et un", "một trăm").
+var numberArticles = map[string]bool{"un": true, "una": true, "une": true, "một": true}
+
+// numberCompoundForms are the forms digits take only at the end of a longer
+// number ("hai mươi mốt", "ba mươi tư", "mười lăm"). Alone they are other
+// words: "ngày mốt" (the day after tomorrow), "thứ tư" (Wednesday).
+var numberCompoundForms = map[string]bool{"mốt": true, "tư": true, "lăm": true}
+
+// numberAmbiguous are number words that alone usually mean something else:
+// "year" (năm), "not" (không), "dad" (ba) or "new" (neuf). Besides inside a
+// longer number, they count alone just before one of numberCounters ("ba
+// quả táo", "neuf ans").
+var numberAmbiguous = map[string]bool{"năm": true, "không": true, "ba": true, "neuf": true}
+
+// numberCounters are words that follow an amount: units, and the
+// classifiers Vietnamese counts things with.
+var numberCounters = map[string]map[string]bool{
+	"fr": wordSet("ans heures minutes euros mètres centimètres kilos kilogrammes grammes litres fois"),
+	"vi": wordSet("con cái chiếc quả trái cuốn quyển bông cây viên tấm bức hộp túi gói tuổi giờ phút mét xăng-ti-mét ki-lô-gam lít"),
+}
+
+// wordValues reads lists given as start, step, words: the words take the
+// values start, start+step, ….
+func wordValues(lists ...any) map[string]int {
+	m := map[string]int{}
+	for i := 0; i+2 < len(lists); i += 3 {
+		start, step := lists[i].(int), lists[i+1].(int)
+		for j, w := range strings.Fields(lists[i+2].(string)) {
+			m[w] = start + j*step
+		}
+	}
+	return m
+}
+
+var reNumberToken = regexp.MustCompile(`[\p{L}]+(?:-[\p{L}]+)*`)
+
+// wordValue is the value of one word, which may be a hyphenated compound
+// ("vingt-deux", "vingt-et-un", "quatre-vingt-dix-sept"); its parts are
+// matched longest first.
+func wordValue(word, lang string) (int, bool) {
+	words := numberWords[lang]
+	if n, ok := words[word]; ok {
+		return n, true
+	}
+	parts := strings.Split(word, "-")
+	if len(parts) == 1 {
+		return 0, false
+	}
+	total := 0
+	for i := 0; i < len(parts); {
+		if numberJoiners[lang][parts[i]] {
+			i++
+			continue
+		}
+		j := len(parts)
+		for ; j > i; j-- {
+			if n, ok := words[strings.Join(parts[i:j], "-")]; ok {
+				total += n
+				break
+			}
+		}
+		if j == i {
+			return 0, false
+		}
+		i = j
+	}
+	return total, true
+}
+
+// WordsToDigits rewrites the numbers text spells out in lang as digits, so
+// "María tiene doce manzanas y come tres" reads "María tiene 12 manzanas y
+// come 3". Words that usually mean something else ("un perro", "un vélo
+// neuf") are left alone.
+func WordsToDigits(text, lang string) string {
+	lang = NormalizeLanguage(lang)
+	locs := reNumberToken.FindAllStringIndex(text, -1)
+	word := func(i int) string { return strings.ToLower(text[locs[i][0]:locs[i][1]]) }
+	// alone reports whether the word at i is a number on its own.
+	alone := func(i int) bool {
+		switch w := word(i); {
+		case numberArticles[w], numberCompoundForms[w]:
+			return false
+		case numberAmbiguous[w]:
+			return i+1 < len(locs) && strings.TrimSpace(text[locs[i][1]:locs[i+1][0]]) == "" && numberCounters[lang][word(i+1)]
+		}
+		return true
+	}
+
+	var out strings.Builder
+	last := 0
+	for i := 0; i < len(locs); {
+		start := locs[i][0]
+		value, current, prev, words, end := 0, 0, 0, 0, 0
+		j := i
+		for ; j < len(locs); j++ {
+			w := word(j)
+			if j > i && strings.TrimSpace(text[locs[j-1][1]:locs[j][0]]) != "" {
+				break // punctuation ends a number
+			}
+			if m, ok := numberMultipliers[lang][w]; ok {
+				if words == 0 && m < 100 {
+					break // "mươi" needs a number before it
+				}
+				if m >= 1000 {
+					value, current = (value+max(current, 1))*m, 0
+				} else { // only the word before: "hai trăm ba mươi" is 200 + 3×10
+					current += max(prev, 1)*m - prev
+				}
+				prev = 0
+			} else if n, ok := wordValue(w, lang); ok {
+				current, prev = current+n, n
+			} else if numberJoiners[lang][w] && words > 0 && j+1 < len(locs) {
+				if _, next := wordValue(word(j+1), lang); !next {
+					break
+				}
+				continue
+			} else {
+				break
+			}
+			words++
+			end = locs[j][1]
+		}
+		if words == 0 || (words == 1 && !alone(i)) {
+			i = max(j, i+1)
+			continue
+		}
+		out.WriteString(text[last:start])
+		out.WriteString(strconv.Itoa(value + current))
+		last, i = end, j
+	}
+	out.WriteString(text[last:])
+	return out.String()
+}
+
+// numberText is the text the numbers of a story in lang are read from: for
+// languages other than English, with spelled-out numbers turned into digits.
+// English stories are asked for in digits and "one" is too often a word.
+func numberText(text, lang string) string {
+	if NormalizeLanguage(lang) == "en" {
+		return text
+	}
+	return WordsToDigits(text, lang)
+}
+
+// ---------- worksheet labels ----------
+
+// WorksheetLabels are the words printed on a worksheet around the problems.
+type WorksheetLabels struct {
+	Title       string // name, operation
+	AnswerTitle string // name, operation
+	Answer      string
+	CircleOne   string
+	Rule        string
+	Check       string
+	Wrong       string
+	Score       string
+	Heading     string // interactive page; name
+	ShowHow     string // interactive page: reveals the worked solution
+	Hint        string // interactive page: opens the next hint
+	HintsUsed   string
+}
+
+var worksheetLabels = map[string]WorksheetLabels{
+	"en": {"%s's %s Problem Set", "%s's %s Answer Key", "Answer", "Circle one", "rule", "Check my answers", "Wrong 😓", "Score", "Interactive worksheet for %s", "Show me how", "Hint", "Hints used"},
+	"es": {"Problemas de %[2]s de %[1]s", "Respuestas de %[2]s de %[1]s", "Respuesta", "Encierra una", "regla", "Revisar mis respuestas", "Incorrecto 😓", "Puntos", "Hoja interactiva de %s", "Muéstrame cómo", "Pista", "Pistas usadas"},
+	"fr": {"Exercices de %s : %s", "Corrigé de %s : %s", "Réponse", "Entoure la bonne réponse", "règle", "Vérifier mes réponses", "Faux 😓", "Score", "Fiche interactive de %s", "Montre-moi comment", "Indice", "Indices utilisés"},
+	"vi": {"Bài tập %[2]s của %[1]s", "Đáp án %[2]s của %[1]s", "Trả lời", "Khoanh tròn một đáp án", "quy luật", "Kiểm tra bài làm", "Sai rồi 😓", "Điểm", "Bài tập tương tác của %s", "Chỉ em cách làm", "Gợi ý", "Gợi ý đã dùng"},
+}
+
+// operationNames are the operations as a worksheet title names them.
+var operationNames = map[string]map[string]string{
+	"es": {OpAddition: "suma", OpSubtraction: "resta", "multiplication": "multiplicación", "division": "división", OpConvert: "medidas", OpMixed: "repaso"},
+	"fr": {OpAddition: "addition", OpSubtraction: "soustraction", "multiplication": "multiplication", "division": "division", OpConvert: "mesures", OpMixed: "révision"},
+	"vi": {OpAddition: "phép cộng", OpSubtraction: "phép trừ", "multiplication": "phép nhân", "division": "phép chia", OpConvert: "đo lường", OpMixed: "ôn tập"},
+}
+
+// Labels returns the worksheet labels for lang, English when there are
+// none.
+func Labels(lang string) WorksheetLabels {
+	return worksheetLabels[NormalizeLanguage(lang)]
+}
+
+// OperationName is how a worksheet in lang names op. English keeps the
+// operation as the request spelled it.
+func OperationName(op, lang string) string {
+	if name, ok := operationNames[NormalizeLanguage(lang)][NormalizeOperation(op)]; ok {
+		return name
+	}
+	return op
+}
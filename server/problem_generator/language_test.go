@@ -0,0 +1,35 @@
+package problemgenerator
+
+import "testing"
+
+func TestWordsToDigits(t *testing.T) {
+	tests := []struct {
+		lang, text, want string
+	}{
+		{"es", "María tiene doce manzanas y come tres", "María tiene 12 manzanas y come 3"},
+		{"es", "Hay treinta y dos sillas", "Hay 32 sillas"},
+		{"es", "Compra doscientas cincuenta hojas", "Compra 250 hojas"},
+		{"es", "Un perro tiene cuatro patas", "Un perro tiene 4 patas"},
+		{"es", "Lee ciento cinco páginas y dos mil libros", "Lee 105 páginas y 2000 libros"},
+		{"fr", "Il y a quatre-vingt-douze billes", "Il y a 92 billes"},
+		{"fr", "Elle a soixante et onze perles", "Elle a 71 perles"},
+		{"fr", "vingt et un", "21"},
+		{"fr", "Il achète deux cents bonbons", "Il achète 200 bonbons"},
+		{"fr", "Un vélo neuf coûte neuf euros", "Un vélo neuf coûte 9 euros"},
+		{"fr", "Une fille a trois chats, une autre en a deux.", "Une fille a 3 chats, une autre en a 2."},
+		{"vi", "Lan có hai mươi mốt con mèo", "Lan có 21 con mèo"},
+		{"vi", "một trăm linh năm", "105"},
+		{"vi", "hai trăm ba mươi lăm", "235"},
+		{"vi", "Năm nay Lan có năm con mèo", "Năm nay Lan có 5 con mèo"},
+		{"vi", "Lan có ba quả táo và ba của Lan", "Lan có 3 quả táo và ba của Lan"},
+		{"vi", "Bạn không có mười cái bút", "Bạn không có 10 cái bút"},
+		{"vi", "Ngày mốt Lan có ba mươi tư con mèo", "Ngày mốt Lan có 34 con mèo"},
+		{"vi", "Thứ tư Lan mua mười lăm quả táo", "Thứ tư Lan mua 15 quả táo"},
+		{"en", "She has three hundred and twelve beads", "She has 312 beads"},
+	}
+	for _, tt := range tests {
+		if got := WordsToDigits(tt.text, tt.lang); got != tt.want {
+			t.Errorf("WordsToDigits(%q, %s) = %q, want %q", tt.text, tt.lang, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package problemgenerator
+
+import (
+	"errors"
+	"fmt"
+)
+
+var errNoMeasures = errors.New("no measurements with units in text")
+
+// solveMeasurement reads the measures out of a measurement problem and
+// computes its answer in unit (a symbol or name). When unit is empty the
+// answer is given in the units the problem itself uses, e.g. "2 m 30 cm".
+// Every unit must be allowed by policy.
+func solveMeasurement(text, op, unit string, policy MeasurementPolicy) (nums []int, answer string, accept []string, err error) {
+	measures, err := ParseMeasures(text)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	if len(measures) == 0 {
+		return nil, "", nil, errNoMeasures
+	}
+
+	dim, sys := measures[0][0].Unit.Dimension, measures[0][0].Unit.System
+	used := map[string]Unit{}
+	for _, m := range measures {
+		for _, q := range m {
+			if q.Unit.Dimension != dim || q.Unit.System != sys {
+				return nil, "", nil, fmt.Errorf("mixes %s and %s", measures[0][0].Unit.Plural, q.Unit.Plural)
+			}
+			if !policy.Allows(q.Unit) {
+				return nil, "", nil, fmt.Errorf("%s are not used in grade %d %s worksheets", q.Unit.Plural, policy.Grade, policy.System)
+			}
+			used[q.Unit.Symbol] = q.Unit
+			nums = append(nums, q.Amount)
+		}
+	}
+
+	var total int
+	switch NormalizeOperation(op) {
+	case OpConvert:
+		if !policy.Convert {
+			return nil, "", nil, fmt.Errorf("unit conversion is not taught in grade %d", policy.Grade)
+		}
+		if len(measures) != 1 {
+			return nil, "", nil, fmt.Errorf("convert needs one measurement, found %d", len(measures))
+		}
+		total = measures[0].Base()
+	case OpAddition:
+		if len(measures) < 2 {
+			return nil, "", nil, fmt.Errorf("addition needs two measurements, found %d", len(measures))
+		}
+		for _, m := range measures {
+			total += m.Base()
+		}
+	case OpSubtraction:
+		if len(measures) != 2 {
+			return nil, "", nil, fmt.Errorf("subtraction needs two measurements, found %d", len(measures))
+		}
+		total = measures[0].Base() - measures[1].Base()
+		if total < 0 {
+			return nil, "", nil, fmt.Errorf("%s is less than %s", measures[0], measures[1])
+		}
+	default:
+		return nil, "", nil, fmt.Errorf("unknown measurement operation %q", op)
+	}
+
+	units := make([]Unit, 0, len(used)+1)
+	for _, u := range used {
+		units = append(units, u)
+	}
+	var forms []Measure
+	if unit != "" {
+		target, ok := LookupUnit(unit)
+		if !ok {
+			return nil, "", nil, fmt.Errorf("unknown answer unit %q", unit)
+		}
+		if target.Dimension != dim || target.System != sys {
+			return nil, "", nil, fmt.Errorf("answer in %s does not match %s", target.Plural, measures[0][0].Unit.Plural)
+		}
+		if !policy.Allows(target) {
+			return nil, "", nil, fmt.Errorf("%s are not used in grade %d %s worksheets", target.Plural, policy.Grade, policy.System)
+		}
+		if _, seen := used[target.Symbol]; !seen && !policy.Convert {
+			return nil, "", nil, fmt.Errorf("unit conversion is not taught in grade %d", policy.Grade)
+		}
+		if q, ok := InUnit(total, target); ok {
+			forms = append(forms, Measure{q})
+		}
+		units = append(units, target)
+	}
+	smallest := units[0]
+	for _, u := range units {
+		if u.Factor < smallest.Factor {
+			smallest = u
+		}
+	}
+	compound, ok := Compound(total, units)
+	if !ok {
+		return nil, "", nil, fmt.Errorf("answer is not a whole number of %s", smallest.Plural)
+	}
+	forms = append(forms, compound)
+	// The smallest unit in play gives the spelling most students reach
+	// for ("230 cm"), so accept it too.
+	if q, ok := InUnit(total, smallest); ok {
+		forms = append(forms, Measure{q})
+	}
+
+	seen := map[string]bool{}
+	for _, m := range forms {
+		for _, s := range []string{m.String(), m.Named()} {
+			if !seen[s] {
+				seen[s] = true
+				accept = append(accept, s)
+			}
+		}
+	}
+	return nums, forms[0].String(), accept, nil
+}
@@ -0,0 +1,314 @@
+package problemgenerator
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	pb "github.com/qjs/mathgen_gemma/server/proto"
+)
+
+// OpMixed is the Operation of a set that mixes several operations; see
+// GenerateRequest.Operations.
+const OpMixed = "mixed"
+
+// OperationShare is how much of a mixed set uses one operation: a number
+// of problems, or a percentage of the set.
+type OperationShare struct {
+	Operation string `json:"operation"`
+	Count     int    `json:"count,omitempty"`
+	Percent   int    `json:"percent,omitempty"`
+}
+
+// How the problems of a mixed set are ordered on the worksheet.
+const (
+	ArrangeInterleaved = "interleaved" // operations take turns, as planned
+	ArrangeGrouped     = "grouped"     // all of one operation, then the next
+	ArrangeShuffled    = "shuffled"    // random order
+)
+
+// NormalizeArrange maps form and API spellings to an Arrange* constant.
+func NormalizeArrange(s string) string {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "grouped", "group", "by_operation":
+		return ArrangeGrouped
+	case "shuffled", "shuffle", "random":
+		return ArrangeShuffled
+	default:
+		return ArrangeInterleaved
+	}
+}
+
+// ResolveOperations checks the operation mix of req and fills in what
+// follows from it: Operation becomes OpMixed (in any case), and NumProblems
+// the total when the shares are counts. Requests with a single operation
+// are left alone.
+func ResolveOperations(req *pb.GenerateRequest) error {
+	if len(req.Operations) == 0 {
+		return nil
+	}
+	switch kind := NormalizeKind(req.Kind); kind {
+	case KindArithmetic, KindMissing, KindEquation:
+	default:
+		return fmt.Errorf("mixed operations need arithmetic, missing-number or equation problems, not %s", kind)
+	}
+	if req.Operations[0].Count > 0 {
+		total := 0
+		for _, s := range req.Operations {
+			total += int(s.Count)
+		}
+		req.NumProblems = int32(total)
+	}
+	if NormalizeOperation(req.Operation) != OpMixed {
+		req.Operation = OpMixed
+	}
+	_, err := OperationSlots(req)
+	return err
+}
+
+// OperationSlots is the operation of every problem of req, in order.
+func OperationSlots(req *pb.GenerateRequest) ([]string, error) {
+	return operationSlots(metaFromRequest(req))
+}
+
+// operationSlots shares the operations of meta out over its problems. Counts
+// are taken as they are; percentages share NumProblems by largest
+// remainder. Either way operations take turns through the set, so a review
+// sheet does not start with all of one kind.
+func operationSlots(meta GenerateRequest) ([]string, error) {
+	if len(meta.Operations) == 0 {
+		slots := make([]string, meta.NumProblems)
+		for i := range slots {
+			slots[i] = NormalizeOperation(meta.Operation)
+		}
+		return slots, nil
+	}
+	byCount := meta.Operations[0].Count > 0
+	ops := make([]string, len(meta.Operations))
+	weights := make([]int, len(meta.Operations))
+	for i, s := range meta.Operations {
+		ops[i] = NormalizeOperation(s.Operation)
+		if _, ok := opSymbols[ops[i]]; !ok {
+			return nil, fmt.Errorf("unknown operation %q", s.Operation)
+		}
+		if slices.Contains(ops[:i], ops[i]) {
+			return nil, fmt.Errorf("%s is listed twice", ops[i])
+		}
+		switch {
+		case s.Count < 0 || s.Percent < 0:
+			return nil, fmt.Errorf("%s: shares cannot be negative", ops[i])
+		case byCount && s.Count == 0, !byCount && s.Count > 0:
+			return nil, fmt.Errorf("%s: give every operation a count, or every one a percentage", ops[i])
+		case byCount:
+			weights[i] = s.Count
+		case s.Percent > 0:
+			weights[i] = s.Percent
+		default:
+			weights[i] = 1 // no share given: equal parts
+		}
+	}
+	counts := weights
+	if !byCount {
+		counts = largestRemainder(meta.NumProblems, weights)
+	}
+	return interleave(ops, counts), nil
+}
+
+// largestRemainder splits n into parts proportional to weights: whole
+// shares first, then the biggest leftovers.
+func largestRemainder(n int, weights []int) []int {
+	total := 0
+	for _, w := range weights {
+		total += w
+	}
+	counts := make([]int, len(weights))
+	if total == 0 || n <= 0 {
+		return counts
+	}
+	rest := make([]int, len(weights))
+	given := 0
+	for i, w := range weights {
+		counts[i] = n * w / total
+		rest[i] = n * w % total
+		given += counts[i]
+	}
+	for ; given < n; given++ {
+		best := 0
+		for i := range rest {
+			if rest[i] > rest[best] {
+				best = i
+			}
+		}
+		counts[best]++
+		rest[best] = -1
+	}
+	return counts
+}
+
+// interleave lists counts[i] of items[i], taking turns while each lasts.
+func interleave(items []string, counts []int) []string {
+	left := slices.Clone(counts)
+	var out []string
+	for more := true; more; {
+		more = false
+		for i, item := range items {
+			if left[i] > 0 {
+				out = append(out, item)
+				left[i]--
+				more = true
+			}
+		}
+	}
+	return out
+}
+
+// PlannedSlot is the index p was planned for: which operation, difficulty
+// and regrouping it was asked to have.
+func PlannedSlot(p Problem) int {
+	if p.Slot > 0 {
+		return p.Slot
+	}
+	return p.Index
+}
+
+// slotOperation is the operation the problem with index was planned with,
+// or "" when ps does not mix operations.
+func slotOperation(meta GenerateRequest, index int) string {
+	if len(meta.Operations) == 0 {
+		return ""
+	}
+	slots, err := operationSlots(meta)
+	if err != nil || index < 1 || index > len(slots) {
+		return ""
+	}
+	return slots[index-1]
+}
+
+// fitsSlot reports whether c can stand in for the problem with index: in a
+// mixed set it must have that problem's planned operation.
+func fitsSlot(meta GenerateRequest, index int, c Problem) bool {
+	want := slotOperation(meta, index)
+	return want == "" || NormalizeOperation(c.Operation) == want
+}
+
+var reShare = regexp.MustCompile(`^\s*([A-Za-z+\-*/×÷−]+)\s*[:=]?\s*(\d+)\s*(%?)\s*$`)
+
+// ParseOperationShares reads a mix typed as "addition 6, subtraction 4" or
+// "add 60%, subtract 40%".
+func ParseOperationShares(s string) ([]*pb.OperationShare, error) {
+	var shares []*pb.OperationShare
+	for _, part := range strings.Split(s, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		m := reShare.FindStringSubmatch(part)
+		if m == nil {
+			return nil, fmt.Errorf("%q: want an operation and a count or percentage", strings.TrimSpace(part))
+		}
+		n, _ := strconv.Atoi(m[2])
+		share := &pb.OperationShare{Operation: NormalizeOperation(m[1])}
+		if m[3] == "%" {
+			share.Percent = int32(n)
+		} else {
+			share.Count = int32(n)
+		}
+		shares = append(shares, share)
+	}
+	return shares, nil
+}
+
+// ---------- arranging ----------
+
+// Arrange orders the problems of a mixed set as MetaInfo.Arrange asks and
+// numbers them again, along with the issues about them. Each problem keeps
+// the index it was planned for in Slot, which validators check it against.
+// A set whose order nothing planned is gathered by theme. It returns each
+// problem's old number mapped to its new one, or nil when it left the order
+// alone, for records that kept the old numbers.
+func Arrange(ps *ProblemSet, rng *rand.Rand) map[int]int {
+	problems := slices.Clone(ps.Problems)
+	switch Arrangement(ps.MetaInfo) {
+	case ArrangeGrouped:
+		order := map[string]int{}
+		for i, s := range ps.MetaInfo.Operations {
+			order[NormalizeOperation(s.Operation)] = i
+		}
+		slices.SortStableFunc(problems, func(a, b Problem) int {
+			return order[NormalizeOperation(a.Operation)] - order[NormalizeOperation(b.Operation)]
+		})
+	case ArrangeShuffled:
+		if rng == nil {
+			rng = rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+		}
+		rng.Shuffle(len(problems), func(i, j int) { problems[i], problems[j] = problems[j], problems[i] })
+	default:
+		if PlannedOrder(ps.MetaInfo) {
+			return nil
+		}
+		problems = problems[:0]
+		for _, g := range GroupByTheme(ps.Problems) {
+			problems = append(problems, g.Problems...)
+		}
+	}
+	renumber := map[int]int{}
+	for i := range problems {
+		problems[i].Slot = PlannedSlot(problems[i])
+		renumber[problems[i].Index] = i + 1
+		problems[i].Index = i + 1
+	}
+	for i, issue := range ps.Issues {
+		if n, ok := renumber[issue.Index]; ok {
+			ps.Issues[i].Index = n
+		}
+	}
+	ps.Problems = problems
+	return renumber
+}
+
+// Arrangement is how Arrange orders a set: as MetaInfo.Arrange asks for a
+// mixed set, and interleaved, that is as planned, for any other. A set with
+// a difficulty progression is never grouped or shuffled, which would undo
+// its ramp.
+func Arrangement(meta GenerateRequest) string {
+	if NormalizeOperation(meta.Operation) != OpMixed || NormalizeProgression(meta.Progression) != "" {
+		return ArrangeInterleaved
+	}
+	return NormalizeArrange(meta.Arrange)
+}
+
+// PlannedOrder reports whether the order of a set's problems was planned,
+// by interleaving a mix of operations or by a difficulty progression, and
+// must be kept.
+func PlannedOrder(meta GenerateRequest) bool {
+	return NormalizeOperation(meta.Operation) == OpMixed || NormalizeProgression(meta.Progression) != ""
+}
+
+// GroupByOperation gathers problems under a heading for each operation, in
+// the order each first appears. Headings are in the worksheet's language.
+func GroupByOperation(problems []Problem, lang string) []ThemeGroup {
+	var groups []ThemeGroup
+	at := map[string]int{}
+	for _, p := range problems {
+		op := NormalizeOperation(p.Operation)
+		i, ok := at[op]
+		if !ok {
+			i = len(groups)
+			at[op] = i
+			heading := OperationName(op, lang)
+			if r, size := utf8.DecodeRuneInString(heading); size > 0 {
+				heading = string(unicode.ToUpper(r)) + heading[size:]
+			}
+			if sym := OpSymbol(op); sym != "" {
+				heading += " " + sym
+			}
+			groups = append(groups, ThemeGroup{Theme: heading})
+		}
+		groups[i].Problems = append(groups[i].Problems, p)
+	}
+	return groups
+}
@@ -0,0 +1,137 @@
+package problemgenerator
+
+import (
+	"maps"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func TestArrange(t *testing.T) {
+	mixed := []OperationShare{{Operation: OpAddition, Count: 2}, {Operation: OpSubtraction, Count: 2}}
+	problems := []Problem{
+		{Index: 1, Text: "1", Theme: "Space 🚀", Operation: OpAddition},
+		{Index: 2, Text: "2", Theme: "Dinosaurs 🦖", Operation: OpSubtraction},
+		{Index: 3, Text: "3", Theme: "Space 🚀", Operation: OpAddition},
+		{Index: 4, Text: "4", Theme: "Dinosaurs 🦖", Operation: OpSubtraction},
+	}
+	tests := []struct {
+		name     string
+		meta     GenerateRequest
+		order    []int // old indexes, in their new order
+		renumber map[int]int
+	}{
+		{
+			name:     "gathered by theme",
+			meta:     GenerateRequest{Operation: OpAddition},
+			order:    []int{1, 3, 2, 4},
+			renumber: map[int]int{1: 1, 3: 2, 2: 3, 4: 4},
+		},
+		{
+			name:  "interleaved mix kept",
+			meta:  GenerateRequest{Operation: OpMixed, Operations: mixed},
+			order: []int{1, 2, 3, 4},
+		},
+		{
+			name:  "progression kept",
+			meta:  GenerateRequest{Operation: OpAddition, Progression: ProgressionRamp},
+			order: []int{1, 2, 3, 4},
+		},
+		{
+			name:  "progression not shuffled",
+			meta:  GenerateRequest{Operation: OpMixed, Operations: mixed, Progression: ProgressionRamp, Arrange: ArrangeShuffled},
+			order: []int{1, 2, 3, 4},
+		},
+		{
+			name:  "progression not grouped",
+			meta:  GenerateRequest{Operation: OpMixed, Operations: mixed, Progression: ProgressionSpiral, Arrange: ArrangeGrouped},
+			order: []int{1, 2, 3, 4},
+		},
+		{
+			name:     "single operation not shuffled",
+			meta:     GenerateRequest{Operation: OpAddition, Arrange: ArrangeShuffled},
+			order:    []int{1, 3, 2, 4},
+			renumber: map[int]int{1: 1, 3: 2, 2: 3, 4: 4},
+		},
+		{
+			name:     "grouped by operation",
+			meta:     GenerateRequest{Operation: OpMixed, Operations: mixed, Arrange: ArrangeGrouped},
+			order:    []int{1, 3, 2, 4},
+			renumber: map[int]int{1: 1, 3: 2, 2: 3, 4: 4},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ps := &ProblemSet{MetaInfo: tt.meta, Problems: slices.Clone(problems), Issues: []Issue{{Index: 3}}}
+			renumber := Arrange(ps, rand.New(rand.NewPCG(1, 2)))
+			var order []int
+			for i, p := range ps.Problems {
+				if p.Index != i+1 {
+					t.Errorf("problem %d numbered %d", i+1, p.Index)
+				}
+				n, _ := strconv.Atoi(p.Text)
+				order = append(order, n)
+			}
+			if !slices.Equal(order, tt.order) {
+				t.Errorf("order = %v, want %v", order, tt.order)
+			}
+			if !maps.Equal(renumber, tt.renumber) {
+				t.Errorf("renumber = %v, want %v", renumber, tt.renumber)
+			}
+			if want := slices.Index(tt.order, 3) + 1; ps.Issues[0].Index != want {
+				t.Errorf("issue moved to %d, want %d", ps.Issues[0].Index, want)
+			}
+		})
+	}
+}
+
+func TestArrangeKeepsSlots(t *testing.T) {
+	meta := GenerateRequest{
+		Operation:  OpMixed,
+		Operations: []OperationShare{{Operation: OpAddition, Count: 2}, {Operation: OpSubtraction, Count: 2}},
+		Arrange:    ArrangeGrouped,
+		Regrouping: string(RegroupSome),
+	}
+	numbers := map[string][][]int{ // without regrouping, then with it
+		OpAddition:    {{12, 13}, {27, 15}},
+		OpSubtraction: {{38, 16}, {42, 17}},
+	}
+	text := map[string]string{OpAddition: "How many are there altogether?", OpSubtraction: "How many are left?"}
+	var problems []Problem
+	for i := 1; i <= 4; i++ {
+		op := slotOperation(meta, i)
+		n := numbers[op][0]
+		if Regrouping(meta.Regrouping).ForSlot(i) == RegroupAll {
+			n = numbers[op][1]
+		}
+		problems = append(problems, Problem{Index: i, Kind: KindArithmetic, Operation: op, Numbers: n, Text: text[op]})
+	}
+	ps := &ProblemSet{MetaInfo: meta, Problems: problems}
+	chain := Chain{OperationValidator{}, RegroupingValidator{}}
+	if issues := chain.Validate(ps); len(issues) > 0 {
+		t.Fatalf("planned set: %v", issues)
+	}
+	Arrange(ps, nil)
+	for i, p := range ps.Problems {
+		if p.Index != i+1 || p.Slot == 0 {
+			t.Errorf("problem %d: index %d, slot %d", i+1, p.Index, p.Slot)
+		}
+	}
+	if issues := chain.Validate(ps); len(issues) > 0 {
+		t.Errorf("arranged set: %v", issues)
+	}
+}
+
+func TestProvenanceRenumber(t *testing.T) {
+	p := NewProvenance("x", "generate", "m")
+	issues := []Issue{{Index: 2}, {Index: 3}}
+	p.AddAttempt(1, []Issue{{Index: 1}})
+	p.AddAttempt(2, issues)
+	issues[0].Index = 9 // the set's own issues move on their own
+	p.Renumber(map[int]int{1: 3, 2: 1, 3: 2})
+	got := []int{p.Attempts[0].Issues[0].Index, p.Attempts[1].Issues[0].Index, p.Attempts[1].Issues[1].Index}
+	if want := []int{3, 1, 2}; !slices.Equal(got, want) {
+		t.Errorf("attempt issues = %v, want %v", got, want)
+	}
+}
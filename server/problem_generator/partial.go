@@ -0,0 +1,117 @@
+package problemgenerator
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ProblemError is why the problem for one slot of the model's reply could
+// not be read.
+type ProblemError struct {
+	Index int
+	Err   error
+}
+
+func (e ProblemError) Error() string { return fmt.Sprintf("problem %d: %v", e.Index, e.Err) }
+
+// ParseErrors is returned by Parse, next to the set of problems it could
+// read, when some of the reply's problems could not be: one entry per slot
+// left out. The server asks the model again for just those slots.
+type ParseErrors []ProblemError
+
+func (e ParseErrors) Error() string {
+	s := make([]string, len(e))
+	for i, pe := range e {
+		s[i] = pe.Error()
+	}
+	return strings.Join(s, "; ")
+}
+
+// Indexes lists the slots that failed, in order.
+func (e ParseErrors) Indexes() []int {
+	var out []int
+	for _, pe := range e {
+		if !slices.Contains(out, pe.Index) {
+			out = append(out, pe.Index)
+		}
+	}
+	slices.Sort(out)
+	return out
+}
+
+// Only keeps the errors of the given slots.
+func (e ParseErrors) Only(indexes []int) ParseErrors {
+	var out ParseErrors
+	for _, pe := range e {
+		if slices.Contains(indexes, pe.Index) {
+			out = append(out, pe)
+		}
+	}
+	return out
+}
+
+// partial returns what a parser read: ps on its own when every problem was
+// read, ps with errs when some were not, and errs alone when none were. A
+// slot of the set the reply has nothing for, as when the model wrote fewer
+// problems than asked, counts as not read.
+func partial(ps *ProblemSet, errs ParseErrors) (*ProblemSet, error) {
+	seen := map[int]bool{}
+	for _, p := range ps.Problems {
+		seen[p.Index] = true
+	}
+	for _, pe := range errs {
+		seen[pe.Index] = true
+	}
+	for slot := 1; slot <= ps.MetaInfo.NumProblems; slot++ {
+		if !seen[slot] {
+			errs = append(errs, ProblemError{slot, fmt.Errorf("no problem written")})
+		}
+	}
+	switch {
+	case len(errs) == 0:
+		return ps, nil
+	case len(ps.Problems) == 0:
+		return nil, errs
+	}
+	return ps, errs
+}
+
+// FillSlots adds problems of fresh to ps for the missing slots: the one
+// written for each slot or, when the model numbered them its own way, any
+// other that fits the slot. ps is kept in index order. It returns the slots
+// still missing.
+func FillSlots(ps, fresh *ProblemSet, missing []int) []int {
+	used := make([]bool, len(fresh.Problems))
+	add := func(index, j int) {
+		c := fresh.Problems[j]
+		c.Index = index
+		used[j] = true
+		ps.Problems = append(ps.Problems, c)
+	}
+	var left []int
+	for _, index := range missing {
+		j := slices.IndexFunc(fresh.Problems, func(c Problem) bool { return c.Index == index })
+		if j < 0 || !fitsSlot(ps.MetaInfo, index, fresh.Problems[j]) {
+			left = append(left, index)
+			continue
+		}
+		add(index, j)
+	}
+	var still []int
+	for _, index := range left {
+		filled := false
+		for j, c := range fresh.Problems {
+			if !used[j] && fitsSlot(ps.MetaInfo, index, c) {
+				add(index, j)
+				filled = true
+				break
+			}
+		}
+		if !filled {
+			still = append(still, index)
+		}
+	}
+	slices.SortFunc(ps.Problems, func(a, b Problem) int { return a.Index - b.Index })
+	return still
+}
@@ -0,0 +1,283 @@
+package problemgenerator
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+
+	pb "github.com/qjs/mathgen_gemma/server/proto"
+)
+
+// Pattern questions.
+const (
+	OpNextTerm = "next_term" // what comes next?
+	OpFindRule = "find_rule" // what is the rule? (growing patterns only)
+)
+
+// Rule describes how a sequence continues. Growing patterns add Step each
+// time (a negative Step shrinks); repeating patterns cycle through Repeat.
+type Rule struct {
+	Step   int
+	Repeat []string
+}
+
+func (r Rule) String() string {
+	switch {
+	case len(r.Repeat) > 0:
+		return "repeat " + strings.Join(r.Repeat, " ")
+	case r.Step < 0:
+		return fmt.Sprintf("subtract %d", -r.Step)
+	default:
+		return fmt.Sprintf("add %d", r.Step)
+	}
+}
+
+// Next returns the term that follows terms under r.
+func (r Rule) Next(terms []string) string {
+	if len(r.Repeat) > 0 {
+		return r.Repeat[len(terms)%len(r.Repeat)]
+	}
+	last, _ := strconv.Atoi(terms[len(terms)-1])
+	return strconv.Itoa(last + r.Step)
+}
+
+var errNoRule = errors.New("no single rule explains the pattern")
+
+// DiscoverRule works out the rule behind terms. Numeric terms need at least
+// three values and a constant difference; other terms must show the
+// repeating unit at least twice. Anything else is ambiguous to a child and
+// is rejected.
+func DiscoverRule(terms []string) (Rule, error) {
+	if nums, ok := atoiAll(terms); ok {
+		if len(nums) < 3 {
+			return Rule{}, errNoRule
+		}
+		step := nums[1] - nums[0]
+		for i := 2; i < len(nums); i++ {
+			if nums[i]-nums[i-1] != step {
+				return Rule{}, errNoRule
+			}
+		}
+		if step == 0 {
+			return Rule{}, errNoRule
+		}
+		return Rule{Step: step}, nil
+	}
+	for period := 2; period*2 <= len(terms); period++ {
+		ok := true
+		for i := period; i < len(terms) && ok; i++ {
+			ok = terms[i] == terms[i%period]
+		}
+		if ok {
+			return Rule{Repeat: append([]string(nil), terms[:period]...)}, nil
+		}
+	}
+	return Rule{}, errNoRule
+}
+
+func atoiAll(terms []string) ([]int, bool) {
+	nums := make([]int, len(terms))
+	for i, t := range terms {
+		n, err := strconv.Atoi(t)
+		if err != nil {
+			return nil, false
+		}
+		nums[i] = n
+	}
+	return nums, true
+}
+
+// patternShapes stand in for interests that have no emoji in the theme
+// dictionary.
+var patternShapes = []string{"🔴", "🔷", "⭐", "🟩", "🔺"}
+
+// patternSymbols picks count distinct pictures, preferring the student's
+// interests.
+func patternSymbols(nouns []string, count int, rng *rand.Rand) []string {
+	var out []string
+	seen := map[string]bool{}
+	for _, i := range rng.Perm(len(nouns)) {
+		if e, ok := emojiFor(nouns[i]); ok && !seen[e] {
+			seen[e] = true
+			out = append(out, e)
+		}
+	}
+	for _, i := range rng.Perm(len(patternShapes)) {
+		if !seen[patternShapes[i]] {
+			seen[patternShapes[i]] = true
+			out = append(out, patternShapes[i])
+		}
+	}
+	return out[:count]
+}
+
+// growingSteps are the steps a grade may use; the first grades skip count.
+func growingSteps(grade int) (steps []int, maxStart int, shrink bool) {
+	switch {
+	case grade <= 0:
+		return []int{1, 2}, 5, false
+	case grade == 1:
+		return []int{2, 5, 10}, 20, false
+	case grade == 2:
+		return []int{2, 3, 4, 5, 10}, 50, false
+	}
+	return []int{3, 4, 6, 7, 8, 9, 25, 50, 100}, 100, true
+}
+
+// GeneratePatterns builds growing and repeating pattern problems themed on
+// the student's interests without the model. Every problem's rule is
+// rediscovered from the terms it shows, so a pattern only ships when its
+// answer follows from what the child can see.
+func GeneratePatterns(req *pb.GenerateRequest, rng *rand.Rand) (*ProblemSet, error) {
+	if rng == nil {
+		rng = rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+	}
+	grade := GradeNumber(req.GradeLevel)
+	nouns := req.LikesNouns
+	if len(nouns) == 0 {
+		nouns = []string{"stars", "apples", "blocks"}
+	}
+
+	problems := make([]Problem, 0, req.NumProblems)
+	for i := 1; i <= int(req.NumProblems); i++ {
+		noun := nouns[(i-1)%len(nouns)]
+		var p Problem
+		// Repeating patterns make up 3/4 of a kindergarten sheet, 1/2 in
+		// 1st grade and 1/4 from 2nd grade on.
+		if rng.IntN(4) < 3-min(grade, 2) {
+			p = repeatingPattern(req.Name, noun, nouns, grade, rng)
+		} else {
+			p = growingPattern(req.Name, noun, grade, rng)
+		}
+		p.Index, p.Kind = i, KindPattern
+
+		var shown []string
+		for _, t := range p.Sequence {
+			if t != Blank {
+				shown = append(shown, t)
+			}
+		}
+		rule, err := DiscoverRule(shown)
+		if err != nil {
+			return nil, fmt.Errorf("problem %d: %v", i, err)
+		}
+		want := rule.Next(shown)
+		if p.Operation == OpFindRule {
+			want = rule.String()
+		}
+		if want != p.Answer {
+			return nil, fmt.Errorf("problem %d: pattern answer %q does not follow rule %q", i, p.Answer, rule)
+		}
+		problems = append(problems, p)
+	}
+	return &ProblemSet{Problems: problems, MetaInfo: metaFromRequest(req)}, nil
+}
+
+func growingPattern(name, noun string, grade int, rng *rand.Rand) Problem {
+	steps, maxStart, shrink := growingSteps(grade)
+	step := steps[rng.IntN(len(steps))]
+	start := rng.IntN(maxStart/step+1) * step
+	if step > 2 && rng.IntN(2) == 0 {
+		start += rng.IntN(step) // not always a multiple, so it is not just skip counting
+	}
+	length := 5
+	if shrink && rng.IntN(3) == 0 {
+		start += step * (length + 1)
+		step = -step
+	}
+
+	terms := make([]string, length+1)
+	for j := range terms {
+		terms[j] = strconv.Itoa(start + j*step)
+	}
+	rule := Rule{Step: step}
+
+	var text string
+	if step > 0 {
+		text = fmt.Sprintf("%s counts %s. Each group has %d more %s than the last one.", name, noun, step, noun)
+	} else {
+		text = fmt.Sprintf("%s is sharing %s. Each time there are %d fewer %s than before.", name, noun, -step, noun)
+	}
+	p := Problem{
+		Theme:     noun,
+		Numbers:   []int{start, step},
+		Operation: OpNextTerm,
+		Rule:      rule.String(),
+	}
+	if grade >= 2 && rng.IntN(3) == 0 {
+		// Rule questions show every term and offer three nearby rules.
+		p.Operation = OpFindRule
+		p.Text = text[:strings.Index(text, ".")+1] + " What is the rule?"
+		p.Sequence = terms[:length]
+		p.Answer = rule.String()
+		p.Choices = ruleChoices(step, rng)
+		return p
+	}
+	p.Text = text + fmt.Sprintf(" How many %s come next?", noun)
+	p.Sequence = withBlank(terms[:length])
+	p.Answer = terms[length]
+	return p
+}
+
+// ruleChoices offers the right rule and two near misses, in random order.
+func ruleChoices(step int, rng *rand.Rand) []string {
+	choices := []string{Rule{Step: step}.String()}
+	for _, d := range []int{1, -1, 2} {
+		s := step + d
+		if step < 0 {
+			s = step - d
+		}
+		if s != 0 && len(choices) < 3 {
+			choices = append(choices, Rule{Step: s}.String())
+		}
+	}
+	rng.Shuffle(len(choices), func(i, j int) { choices[i], choices[j] = choices[j], choices[i] })
+	return choices
+}
+
+// withBlank copies terms and adds Blank for the term to find.
+func withBlank(terms []string) []string {
+	return append(append([]string(nil), terms...), Blank)
+}
+
+var repeatUnits = [][]int{{0, 1}, {0, 0, 1}, {0, 1, 1}, {0, 1, 2}, {0, 1, 2, 2}}
+
+func repeatingPattern(name, noun string, nouns []string, grade int, rng *rand.Rand) Problem {
+	// Kindergarten sticks to AB, AAB and ABB units.
+	units := repeatUnits[:3]
+	if grade >= 1 {
+		units = repeatUnits
+	}
+	shape := units[rng.IntN(len(units))]
+	symbols := patternSymbols(nouns, 3, rng)
+
+	unit := make([]string, len(shape))
+	for j, s := range shape {
+		unit[j] = symbols[s]
+	}
+	length := 2*len(unit) + rng.IntN(len(unit)) // two full repeats, then part of a third
+	terms := make([]string, length+1)
+	for j := range terms {
+		terms[j] = unit[j%len(unit)]
+	}
+
+	var choices []string
+	seen := map[string]bool{}
+	for _, s := range unit {
+		if !seen[s] {
+			seen[s] = true
+			choices = append(choices, s)
+		}
+	}
+	return Problem{
+		Theme:     noun,
+		Text:      fmt.Sprintf("%s lines up %s in a pattern. What comes next?", name, noun),
+		Operation: OpNextTerm,
+		Answer:    terms[length],
+		Choices:   choices,
+		Sequence:  withBlank(terms[:length]),
+		Rule:      Rule{Repeat: unit}.String(),
+	}
+}
@@ -0,0 +1,63 @@
+package problemgenerator
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	pb "github.com/qjs/mathgen_gemma/server/proto"
+)
+
+func TestDiscoverRule(t *testing.T) {
+	tests := []struct {
+		terms      []string
+		rule, next string
+		err        bool
+	}{
+		{terms: []string{"2", "4", "6", "8"}, rule: "add 2", next: "10"},
+		{terms: []string{"50", "45", "40"}, rule: "subtract 5", next: "35"},
+		{terms: []string{"3", "7", "11", "15", "19"}, rule: "add 4", next: "23"},
+		{terms: []string{"🔴", "🔷", "🔴", "🔷", "🔴"}, rule: "repeat 🔴 🔷", next: "🔷"},
+		{terms: []string{"A", "A", "B", "A", "A", "B", "A"}, rule: "repeat A A B", next: "A"},
+		{terms: []string{"2", "4"}, err: true},                // too short to tell
+		{terms: []string{"1", "2", "4", "8"}, err: true},      // not a constant step
+		{terms: []string{"5", "5", "5"}, err: true},           // nothing grows
+		{terms: []string{"A", "B", "C", "A", "B"}, err: true}, // unit not shown twice
+	}
+	for _, tt := range tests {
+		rule, err := DiscoverRule(tt.terms)
+		if (err != nil) != tt.err {
+			t.Errorf("DiscoverRule(%q) error = %v, want error %v", tt.terms, err, tt.err)
+			continue
+		}
+		if tt.err {
+			continue
+		}
+		if rule.String() != tt.rule {
+			t.Errorf("DiscoverRule(%q) = %q, want %q", tt.terms, rule, tt.rule)
+		}
+		if next := rule.Next(tt.terms); next != tt.next {
+			t.Errorf("%q.Next(%q) = %q, want %q", rule, tt.terms, next, tt.next)
+		}
+	}
+}
+
+func TestGeneratePatterns(t *testing.T) {
+	for _, grade := range []string{"K", "grade 1", "grade 2", "grade 4"} {
+		req := &pb.GenerateRequest{Name: "Ana", GradeLevel: grade, NumProblems: 20, LikesNouns: []string{"cats", "rockets"}}
+		ps, err := GeneratePatterns(req, rand.New(rand.NewPCG(1, 2)))
+		if err != nil {
+			t.Fatalf("%s: %v", grade, err)
+		}
+		if len(ps.Problems) != 20 {
+			t.Fatalf("%s: %d problems, want 20", grade, len(ps.Problems))
+		}
+		for _, p := range ps.Problems {
+			if p.Kind != KindPattern || p.Answer == "" || p.Rule == "" || len(p.Sequence) == 0 {
+				t.Errorf("%s: incomplete pattern %+v", grade, p)
+			}
+			if p.Operation == OpFindRule && GradeNumber(grade) < 2 {
+				t.Errorf("%s: rule question before grade 2: %+v", grade, p)
+			}
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package problemgenerator
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+
+	pb "github.com/qjs/mathgen_gemma/server/proto"
+)
+
+// Place value, rounding and estimation questions.
+const (
+	OpDigit    = "digit"    // what digit is in the tens place?
+	OpValue    = "value"    // what is the value of the 4?
+	OpExpanded = "expanded" // write 347 in expanded form
+	OpRound    = "round"    // round 347 to the nearest ten
+	OpEstimate = "estimate" // estimate 47 + 38 by rounding first
+)
+
+var placeNames = []string{"ones", "tens", "hundreds", "thousands", "ten thousands", "hundred thousands"}
+
+// placeSingular names a rounding target ("ten", "hundred").
+var placeSingular = []string{"one", "ten", "hundred", "thousand", "ten thousand", "hundred thousand"}
+
+// numberRange returns the smallest and largest number a grade works with:
+// teens in kindergarten, two digits in 1st grade, three in 2nd, four in 3rd
+// and up to six after that.
+func numberRange(grade int) (lo, hi int) {
+	switch {
+	case grade <= 0:
+		return 11, 19
+	case grade == 1:
+		return 10, 99
+	case grade == 2:
+		return 100, 999
+	case grade == 3:
+		return 1000, 9999
+	}
+	return 10000, 999999
+}
+
+// roundingPlaces lists the places (as powers of ten) a grade rounds to.
+func roundingPlaces(grade int) []int {
+	switch {
+	case grade <= 2:
+		return []int{10}
+	case grade == 3:
+		return []int{10, 100}
+	}
+	return []int{10, 100, 1000}
+}
+
+func pow10(exp int) int {
+	p := 1
+	for ; exp > 0; exp-- {
+		p *= 10
+	}
+	return p
+}
+
+// Digit returns the digit of n at place (0 = ones).
+func Digit(n, place int) int { return n / pow10(place) % 10 }
+
+// RoundTo rounds n to the nearest multiple of unit, halves going up.
+func RoundTo(n, unit int) int { return (n + unit/2) / unit * unit }
+
+// ExpandedForm writes n as the sum of its place values, skipping zeros:
+// 347 → "300 + 40 + 7", 307 → "300 + 7".
+func ExpandedForm(n int) string {
+	var parts []string
+	s := strconv.Itoa(n)
+	for i, r := range s {
+		if r == '0' {
+			continue
+		}
+		parts = append(parts, string(r)+strings.Repeat("0", len(s)-1-i))
+	}
+	if len(parts) == 0 {
+		return "0"
+	}
+	return strings.Join(parts, " + ")
+}
+
+// expandedWithZeros is ExpandedForm keeping zero places ("300 + 0 + 7"),
+// which students are also taught to write.
+func expandedWithZeros(n int) string {
+	s := strconv.Itoa(n)
+	parts := make([]string, len(s))
+	for i, r := range s {
+		if r == '0' {
+			parts[i] = "0"
+			continue
+		}
+		parts[i] = string(r) + strings.Repeat("0", len(s)-1-i)
+	}
+	return strings.Join(parts, " + ")
+}
+
+func placeOf(unit int) int {
+	p := 0
+	for ; unit > 1; unit /= 10 {
+		p++
+	}
+	return p
+}
+
+// GeneratePlaceValue builds place value, rounding or estimation problems
+// (depending on the request's kind) without the model, with numbers sized
+// for the grade and the student's interests in the stories.
+func GeneratePlaceValue(req *pb.GenerateRequest, rng *rand.Rand) (*ProblemSet, error) {
+	if rng == nil {
+		rng = rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+	}
+	kind := NormalizeKind(req.Kind)
+	grade := GradeNumber(req.GradeLevel)
+	nouns := req.LikesNouns
+	if len(nouns) == 0 {
+		nouns = []string{"stars", "apples", "blocks"}
+	}
+
+	var ops []string
+	switch kind {
+	case KindPlaceValue:
+		ops = []string{OpDigit, OpValue, OpExpanded}
+	case KindRounding:
+		ops = []string{OpRound}
+	case KindEstimation:
+		ops = []string{OpEstimate}
+	default:
+		return nil, fmt.Errorf("%s is not a place value kind", kind)
+	}
+
+	problems := make([]Problem, 0, req.NumProblems)
+	for i := 1; i <= int(req.NumProblems); i++ {
+		noun := nouns[(i-1)%len(nouns)]
+		p := placeValueProblem(req.Name, noun, ops[(i-1)%len(ops)], grade, rng)
+		p.Index, p.Kind, p.Theme = i, kind, noun
+		problems = append(problems, p)
+	}
+	return &ProblemSet{Problems: problems, MetaInfo: metaFromRequest(req)}, nil
+}
+
+func placeValueProblem(name, noun, op string, grade int, rng *rand.Rand) Problem {
+	lo, hi := numberRange(grade)
+	n := lo + rng.IntN(hi-lo+1)
+	digits := len(strconv.Itoa(n))
+	p := Problem{Operation: op, Numbers: []int{n}, Expanded: ExpandedForm(n)}
+
+	switch op {
+	case OpDigit, OpValue:
+		// Pick a place whose digit is not zero so "value of the 0" never comes up.
+		place := rng.IntN(digits)
+		for Digit(n, place) == 0 {
+			place = (place + 1) % digits
+		}
+		d := Digit(n, place)
+		if op == OpDigit || strings.Count(strconv.Itoa(n), strconv.Itoa(d)) > 1 {
+			// A digit that appears twice has no single value, so ask for
+			// the digit in a place instead.
+			p.Operation = OpDigit
+			p.Text = fmt.Sprintf("%s counted %d %s. What digit is in the %s place of %d?", name, n, noun, placeNames[place], n)
+			p.Answer = strconv.Itoa(d)
+		} else {
+			p.Text = fmt.Sprintf("%s counted %d %s. What is the value of the %d in %d?", name, n, noun, d, n)
+			p.Answer = strconv.Itoa(d * pow10(place))
+		}
+	case OpExpanded:
+		p.Text = fmt.Sprintf("%s has %d %s. Write %d in expanded form.", name, n, noun, n)
+		p.Answer = ExpandedForm(n)
+		if alt := expandedWithZeros(n); alt != p.Answer {
+			p.Accept = []string{p.Answer, alt}
+		}
+	case OpRound:
+		places := roundingPlaces(grade)
+		unit := places[rng.IntN(len(places))]
+		if unit >= n {
+			unit = 10
+		}
+		p.Text = fmt.Sprintf("%s has %d %s. Round %d to the nearest %s.", name, n, noun, n, placeSingular[placeOf(unit)])
+		p.Answer = strconv.Itoa(RoundTo(n, unit))
+	case OpEstimate:
+		// Estimation uses numbers one digit shorter than place value work
+		// so the mental sum stays manageable.
+		lo, hi = numberRange(grade - 1)
+		if grade <= 1 {
+			lo, hi = 10, 99
+		}
+		a, b := lo+rng.IntN(hi-lo+1), lo+rng.IntN(hi-lo+1)
+		places := roundingPlaces(grade)
+		unit := places[len(places)-1]
+		for unit >= min(a, b) && unit > 10 {
+			unit /= 10
+		}
+		ra, rb := RoundTo(a, unit), RoundTo(b, unit)
+		if a < b {
+			a, b, ra, rb = b, a, rb, ra
+		}
+		sym, est := "+", ra+rb
+		ask := fmt.Sprintf("how many %s there are altogether", noun)
+		// Only subtract when the estimate is not zero.
+		if ra > rb && rng.IntN(2) == 0 {
+			sym, est = "−", ra-rb
+			ask = fmt.Sprintf("how many more %s are in the first box", noun)
+		}
+		p.Numbers = []int{a, b}
+		p.Expanded = ""
+		p.Text = fmt.Sprintf("%s has %d %s in one box and %d in another. Round each number to the nearest %s to estimate %s.",
+			name, a, noun, b, placeSingular[placeOf(unit)], ask)
+		p.Equation = fmt.Sprintf("%d %s %d = %s", ra, sym, rb, Blank)
+		p.Answer = strconv.Itoa(est)
+	}
+	return p
+}
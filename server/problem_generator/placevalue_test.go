@@ -0,0 +1,81 @@
+package problemgenerator
+
+import (
+	"math/rand/v2"
+	"strconv"
+	"testing"
+
+	pb "github.com/qjs/mathgen_gemma/server/proto"
+)
+
+func TestPlaceValue(t *testing.T) {
+	tests := []struct {
+		n, place, digit int
+		expanded, zeros string
+	}{
+		{347, 1, 4, "300 + 40 + 7", "300 + 40 + 7"},
+		{307, 1, 0, "300 + 7", "300 + 0 + 7"},
+		{5000, 3, 5, "5000", "5000 + 0 + 0 + 0"},
+		{12, 0, 2, "10 + 2", "10 + 2"},
+	}
+	for _, tt := range tests {
+		if got := Digit(tt.n, tt.place); got != tt.digit {
+			t.Errorf("Digit(%d, %d) = %d, want %d", tt.n, tt.place, got, tt.digit)
+		}
+		if got := ExpandedForm(tt.n); got != tt.expanded {
+			t.Errorf("ExpandedForm(%d) = %q, want %q", tt.n, got, tt.expanded)
+		}
+		if got := expandedWithZeros(tt.n); got != tt.zeros {
+			t.Errorf("expandedWithZeros(%d) = %q, want %q", tt.n, got, tt.zeros)
+		}
+	}
+}
+
+func TestRoundTo(t *testing.T) {
+	tests := []struct{ n, unit, want int }{
+		{347, 10, 350},
+		{344, 10, 340},
+		{345, 10, 350}, // halves go up
+		{347, 100, 300},
+		{350, 100, 400},
+		{4999, 1000, 5000},
+		{4, 10, 0},
+	}
+	for _, tt := range tests {
+		if got := RoundTo(tt.n, tt.unit); got != tt.want {
+			t.Errorf("RoundTo(%d, %d) = %d, want %d", tt.n, tt.unit, got, tt.want)
+		}
+	}
+}
+
+func TestGeneratePlaceValue(t *testing.T) {
+	for _, kind := range []string{KindPlaceValue, KindRounding, KindEstimation} {
+		for _, grade := range []string{"K", "grade 2", "grade 4"} {
+			req := &pb.GenerateRequest{Name: "Leo", GradeLevel: grade, Kind: kind, NumProblems: 12, LikesNouns: []string{"shells"}}
+			ps, err := GeneratePlaceValue(req, rand.New(rand.NewPCG(3, 4)))
+			if err != nil {
+				t.Fatalf("%s %s: %v", kind, grade, err)
+			}
+			lo, hi := numberRange(GradeNumber(grade))
+			for _, p := range ps.Problems {
+				if p.Kind != kind || p.Answer == "" {
+					t.Errorf("%s %s: incomplete problem %+v", kind, grade, p)
+				}
+				if kind == KindEstimation {
+					continue
+				}
+				if n := p.Numbers[0]; n < lo || n > hi {
+					t.Errorf("%s %s: %d outside %d..%d", kind, grade, n, lo, hi)
+				}
+				if p.Operation == OpRound {
+					if _, err := strconv.Atoi(p.Answer); err != nil {
+						t.Errorf("%s %s: rounded answer %q is not a number", kind, grade, p.Answer)
+					}
+				}
+			}
+		}
+	}
+	if _, err := GeneratePlaceValue(&pb.GenerateRequest{Kind: KindPattern, NumProblems: 1}, nil); err == nil {
+		t.Error("GeneratePlaceValue(pattern) succeeded, want error")
+	}
+}
@@ -0,0 +1,159 @@
+package problemgenerator
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+
+	pb "github.com/qjs/mathgen_gemma/server/proto"
+)
+
+// Plan is the math of one problem, fixed before any story is written. In
+// hybrid mode the model only writes the words around it, so the answer key is
+// right by construction.
+type Plan struct {
+	Index     int
+	Operation string
+	A         int
+	B         int
+	Result    int
+	Unknown   string // SlotA | SlotB | SlotResult
+}
+
+// Known returns the two values the story must state, in equation order.
+func (p Plan) Known() []int {
+	switch p.Unknown {
+	case SlotA:
+		return []int{p.B, p.Result}
+	case SlotB:
+		return []int{p.A, p.Result}
+	}
+	return []int{p.A, p.B}
+}
+
+// Answer is the value of the unknown slot.
+func (p Plan) Answer() string { return slotValue(p.Unknown, p.A, p.B, p.Result) }
+
+// Equation writes the plan as "a op b = result" with Blank for the unknown.
+func (p Plan) Equation() string {
+	return formatEquation(p.Operation, p.Unknown, p.A, p.B, p.Result)
+}
+
+// PlanProblems picks the operation, operands and unknown of every problem in
+// req, following its operation mix, difficulty progression and regrouping
+// rule if it has them. Arithmetic always asks for the result; missing-number problems from
+// 1st grade on can hide any slot.
+func PlanProblems(req *pb.GenerateRequest, rng *rand.Rand) ([]Plan, error) {
+	kind := NormalizeKind(req.Kind)
+	if kind != KindArithmetic && kind != KindMissing {
+		return nil, fmt.Errorf("cannot plan %s problems", kind)
+	}
+	ops, err := OperationSlots(req)
+	if err != nil {
+		return nil, err
+	}
+	if rng == nil {
+		rng = rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+	}
+	grade := GradeNumber(req.GradeLevel)
+	specs := slotSpecs(req, len(ops))
+
+	plans := make([]Plan, 0, len(ops))
+	for i, op := range ops {
+		if _, ok := opSymbols[op]; !ok {
+			return nil, fmt.Errorf("unknown operation %q", req.Operation)
+		}
+		slot := SlotResult
+		if kind == KindMissing && grade >= 1 {
+			slot = []string{SlotA, SlotB, SlotResult}[rng.IntN(3)]
+		}
+		spec := specs[i]
+		spec.Unknown = slot
+		// Stories need amounts of at least 2 to read naturally.
+		a, b, result := pickFor(op, grade, spec, rng)
+		for tries := 0; (a < 2 || b < 2) && tries < 50; tries++ {
+			a, b, result = pickFor(op, grade, spec, rng)
+		}
+		plans = append(plans, Plan{Index: i + 1, Operation: op, A: a, B: b, Result: result, Unknown: slot})
+	}
+	return plans, nil
+}
+
+// ApplyPlans reads the stories the model wrote for plans and builds the
+// ProblemSet from the plans' own math. Each story must state the plan's two
+// known numbers, in order, and no other numbers, so it cannot give away or
+// contradict the answer; plans without such a story are reported in
+// ParseErrors.
+func ApplyPlans(llmOut string, plans []Plan, req *pb.GenerateRequest) (*ProblemSet, error) {
+	var raw []struct {
+		Index int    `json:"index"`
+		Theme string `json:"theme"`
+		Text  string `json:"text"`
+	}
+	if err := json.Unmarshal([]byte(cleanCodeBlock(llmOut)), &raw); err != nil {
+		return nil, fmt.Errorf("hybrid: failed to parse LLM JSON: %w", err)
+	}
+	stories := map[int]int{}
+	for i, rp := range raw {
+		stories[rp.Index] = i
+	}
+
+	kind := NormalizeKind(req.Kind)
+	meta := metaFromRequest(req)
+	problems := make([]Problem, 0, len(plans))
+	var failed ParseErrors
+	for _, plan := range plans {
+		i, ok := stories[plan.Index]
+		if !ok {
+			failed = append(failed, ProblemError{plan.Index, fmt.Errorf("no story written")})
+			continue
+		}
+		rp := raw[i]
+		if err := checkStory(numberText(rp.Text, meta.Language), plan); err != nil {
+			failed = append(failed, ProblemError{plan.Index, err})
+			continue
+		}
+		p := Problem{
+			Index:     plan.Index,
+			Theme:     rp.Theme,
+			Text:      rp.Text,
+			Operation: plan.Operation,
+			Answer:    plan.Answer(),
+			Kind:      kind,
+			Numbers:   []int{plan.A, plan.B},
+		}
+		if kind == KindMissing {
+			p.Numbers = []int{plan.A, plan.B, plan.Result}
+			p.Unknown, p.Equation = plan.Unknown, plan.Equation()
+		}
+		problems = append(problems, p)
+	}
+	return partial(&ProblemSet{Problems: problems, MetaInfo: meta}, failed)
+}
+
+// checkStory makes sure text states exactly the plan's known numbers.
+func checkStory(text string, plan Plan) error {
+	want := plan.Known()
+	var got []int
+	for _, s := range reInts.FindAllString(text, -1) {
+		n, _ := strconv.Atoi(s)
+		got = append(got, n)
+	}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		return fmt.Errorf("story should state %s, found %s", joinInts(want), joinInts(got))
+	}
+	return nil
+}
+
+func joinInts(nums []int) string {
+	if len(nums) == 0 {
+		return "no numbers"
+	}
+	s := make([]string, len(nums))
+	for i, n := range nums {
+		s[i] = strconv.Itoa(n)
+	}
+	return strings.Join(s, ", ")
+}
@@ -0,0 +1,132 @@
+package problemgenerator
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	pb "github.com/qjs/mathgen_gemma/server/proto"
+)
+
+func TestPlanProblems(t *testing.T) {
+	tests := []struct {
+		kind, op, grade string
+		err             bool
+	}{
+		{kind: KindArithmetic, op: "add", grade: "K"},
+		{kind: KindArithmetic, op: "subtraction", grade: "grade 2"},
+		{kind: KindMissing, op: "add", grade: "grade 1"},
+		{kind: KindMissing, op: "sub", grade: "grade 3"},
+		{kind: KindArithmetic, op: "exponent", grade: "grade 2", err: true},
+		{kind: KindPattern, op: "add", grade: "grade 2", err: true},
+	}
+	for _, tt := range tests {
+		req := &pb.GenerateRequest{Kind: tt.kind, Operation: tt.op, GradeLevel: tt.grade, NumProblems: 10}
+		plans, err := PlanProblems(req, rand.New(rand.NewPCG(5, 6)))
+		if (err != nil) != tt.err {
+			t.Errorf("PlanProblems(%s %s) error = %v, want error %v", tt.kind, tt.op, err, tt.err)
+			continue
+		}
+		if tt.err {
+			continue
+		}
+		if len(plans) != 10 {
+			t.Fatalf("PlanProblems(%s %s) made %d plans, want 10", tt.kind, tt.op, len(plans))
+		}
+		for i, p := range plans {
+			if p.Index != i+1 {
+				t.Errorf("plan %d has index %d", i+1, p.Index)
+			}
+			if p.A < 2 || p.B < 2 {
+				t.Errorf("plan %d: operands %d and %d are too small for a story", p.Index, p.A, p.B)
+			}
+			want := p.A + p.B
+			if p.Operation == OpSubtraction {
+				want = p.A - p.B
+			}
+			if p.Result != want {
+				t.Errorf("plan %d: %d %s %d = %d, want %d", p.Index, p.A, OpSymbol(p.Operation), p.B, p.Result, want)
+			}
+			if tt.kind == KindArithmetic && p.Unknown != SlotResult {
+				t.Errorf("plan %d: arithmetic hides %s, want result", p.Index, p.Unknown)
+			}
+		}
+	}
+}
+
+func TestPlanAnswer(t *testing.T) {
+	tests := []struct {
+		plan     Plan
+		known    [2]int
+		answer   string
+		equation string
+	}{
+		{Plan{Operation: OpAddition, A: 5, B: 4, Result: 9, Unknown: SlotResult}, [2]int{5, 4}, "9", "5 + 4 = ?"},
+		{Plan{Operation: OpAddition, A: 5, B: 4, Result: 9, Unknown: SlotA}, [2]int{4, 9}, "5", "? + 4 = 9"},
+		{Plan{Operation: OpSubtraction, A: 12, B: 8, Result: 4, Unknown: SlotB}, [2]int{12, 4}, "8", "12 − ? = 4"},
+	}
+	for _, tt := range tests {
+		known := tt.plan.Known()
+		if [2]int{known[0], known[1]} != tt.known || tt.plan.Answer() != tt.answer || tt.plan.Equation() != tt.equation {
+			t.Errorf("%+v: known %v, answer %q, equation %q; want %v, %q, %q",
+				tt.plan, known, tt.plan.Answer(), tt.plan.Equation(), tt.known, tt.answer, tt.equation)
+		}
+	}
+}
+
+func TestApplyPlans(t *testing.T) {
+	plans := []Plan{
+		{Index: 1, Operation: OpAddition, A: 5, B: 4, Result: 9, Unknown: SlotResult},
+		{Index: 2, Operation: OpSubtraction, A: 12, B: 8, Result: 4, Unknown: SlotB},
+	}
+	req := &pb.GenerateRequest{Kind: KindMissing, Operation: "add"}
+	tests := []struct {
+		name, out string
+		answers   []string
+		err       bool
+	}{
+		{
+			name:    "stories state the known numbers",
+			out:     `[{"index": 1, "text": "Ana has 5 shells and finds 4 more. How many now?"}, {"index": 2, "text": "Leo had 12 cars, gave some away and has 4 left. How many did he give?"}]`,
+			answers: []string{"9", "8"},
+		},
+		{
+			name:    "fenced reply",
+			out:     "```json\n[{\"index\": 2, \"text\": \"12 birds, some fly off, 4 stay.\"}, {\"index\": 1, \"text\": \"5 and 4 more\"}]\n```",
+			answers: []string{"9", "8"},
+		},
+		{
+			name: "story gives away the answer",
+			out:  `[{"index": 1, "text": "Ana has 5 shells and finds 4 more, so 9 in all."}, {"index": 2, "text": "12 birds, some fly off, 4 stay."}]`,
+			err:  true,
+		},
+		{
+			name: "numbers out of order",
+			out:  `[{"index": 1, "text": "Ana finds 4 shells, then 5 more."}, {"index": 2, "text": "12 birds, some fly off, 4 stay."}]`,
+			err:  true,
+		},
+		{
+			name: "story missing",
+			out:  `[{"index": 1, "text": "Ana has 5 shells and finds 4 more."}]`,
+			err:  true,
+		},
+		{name: "not JSON", out: "Here are your stories!", err: true},
+	}
+	for _, tt := range tests {
+		ps, err := ApplyPlans(tt.out, plans, req)
+		if (err != nil) != tt.err {
+			t.Errorf("%s: error = %v, want error %v", tt.name, err, tt.err)
+			continue
+		}
+		if tt.err {
+			continue
+		}
+		for i, p := range ps.Problems {
+			if p.Answer != tt.answers[i] || p.Index != plans[i].Index {
+				t.Errorf("%s: problem %d answer %q, want %q", tt.name, p.Index, p.Answer, tt.answers[i])
+			}
+			if p.Numbers[2] != plans[i].Result || p.Equation != plans[i].Equation() {
+				t.Errorf("%s: problem %d numbers %v, equation %q; want result %d, %q", tt.name, p.Index, p.Numbers, p.Equation, plans[i].Result, plans[i].Equation())
+			}
+		}
+	}
+}
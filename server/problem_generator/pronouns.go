@@ -0,0 +1,325 @@
+package problemgenerator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Pronouns are the words a story uses for the student.
+type Pronouns struct {
+	Subject    string // she, he, they
+	Object     string // her, him, them
+	Possessive string // her, his, their
+	Reflexive  string // herself, himself, themselves
+}
+
+var (
+	She  = Pronouns{"she", "her", "her", "herself"}
+	He   = Pronouns{"he", "him", "his", "himself"}
+	They = Pronouns{"they", "them", "their", "themselves"}
+)
+
+func (p Pronouns) String() string { return p.Subject + "/" + p.Object }
+
+// NormalizePronouns maps a pronouns choice, or for older requests a gender,
+// to "she/her", "he/him" or "they/them". Anything else is they/them.
+func NormalizePronouns(pronouns, gender string) string {
+	return PronounsFor(pronouns, gender).String()
+}
+
+// PronounsFor returns the pronouns for a pronouns choice or, when that is
+// empty, a gender.
+func PronounsFor(pronouns, gender string) Pronouns {
+	s := strings.ToLower(strings.TrimSpace(pronouns))
+	if s == "" {
+		switch strings.ToLower(strings.TrimSpace(gender)) {
+		case "female", "girl", "f":
+			return She
+		case "male", "boy", "m":
+			return He
+		}
+		return They
+	}
+	switch strings.SplitN(s, "/", 2)[0] {
+	case "she", "her":
+		return She
+	case "he", "him":
+		return He
+	}
+	return They
+}
+
+// ExampleNames are the names the prompt examples use, or have used. Models
+// copy them into stories for other students.
+var ExampleNames = []string{"Amelia", "Sam"}
+
+// rePeople matches other people a story can be about, whose pronouns are not
+// the student's.
+var rePeople = regexp.MustCompile(`(?i)\b(mom|mommy|mother|dad|daddy|father|sister|brother|grandma|grandpa|grandmother|grandfather|aunt|uncle|cousin|friend|friends|teacher|coach|girl|boy|woman|man|lady|queen|king|princess|prince|baby|neighbor|neighbour)\b`)
+
+var reCapitalized = regexp.MustCompile(`\b[A-Z][a-z]+\b`)
+
+var calendarWords = wordSet(`monday tuesday wednesday thursday friday saturday sunday
+	january february march april may june july august september october november december`)
+
+// othersIn reports whether text names anyone besides the student, so that
+// its pronouns may be about someone else.
+func othersIn(text, name string) bool {
+	if rePeople.MatchString(text) {
+		return true
+	}
+	for _, loc := range reCapitalized.FindAllStringIndex(text, -1) {
+		w := text[loc[0]:loc[1]]
+		if strings.EqualFold(w, name) || isSightWord(strings.ToLower(w)) || calendarWords[strings.ToLower(w)] || sentenceStart(text, loc[0]) {
+			continue
+		}
+		if _, ok := Themes().Lookup(w); ok {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// sentenceStart reports whether position i of text begins a sentence.
+func sentenceStart(text string, i int) bool {
+	before := strings.TrimRight(text[:i], " \"'")
+	return before == "" || strings.HasSuffix(before, ".") || strings.HasSuffix(before, "!") || strings.HasSuffix(before, "?")
+}
+
+// wrongPronouns are the pronouns that are not the student's: he and she
+// words for they/them, and the other singular set for he and she.
+func wrongPronouns(want Pronouns) []string {
+	var wrong []string
+	for _, set := range []Pronouns{She, He} {
+		if set != want {
+			wrong = append(wrong, set.Subject, set.Object, set.Possessive, set.Reflexive)
+		}
+	}
+	if want == He {
+		wrong = append(wrong, "hers")
+	}
+	return wrong
+}
+
+// wrongPronounRes match wrongPronouns for each set of pronouns.
+var wrongPronounRes = func() map[Pronouns]*regexp.Regexp {
+	res := map[Pronouns]*regexp.Regexp{}
+	for _, want := range []Pronouns{She, He, They} {
+		res[want] = regexp.MustCompile(`(?i)\b(` + strings.Join(wrongPronouns(want), "|") + `)\b`)
+	}
+	return res
+}()
+
+// ---------- correction ----------
+
+// Personalize fixes the two ways the model gets a story's student wrong:
+// it swaps example names for the student's name, and swaps wrong pronouns
+// for the student's own when the student is the only person in the story.
+// What it cannot fix safely is left for the validators.
+func Personalize(ps *ProblemSet) {
+	name := strings.TrimSpace(ps.MetaInfo.Name)
+	if name == "" {
+		return
+	}
+	hasName := regexp.MustCompile(`(?i)\b` + regexp.QuoteMeta(name) + `\b`)
+	want := PronounsFor(ps.MetaInfo.Pronouns, ps.MetaInfo.Gender)
+	for i, p := range ps.Problems {
+		if !isStory(p) {
+			continue
+		}
+		if !hasName.MatchString(p.Text) {
+			if re := protagonist(p.Text, name); re != nil {
+				p = rename(p, re, name)
+			}
+		}
+		if isEnglish(ps.MetaInfo) && !othersIn(p.Text, name) {
+			p.Text = fixPronouns(p.Text, want)
+		}
+		ps.Problems[i] = p
+	}
+}
+
+// exampleNameRes match each of ExampleNames as a word, in any case.
+var exampleNameRes = func() []*regexp.Regexp {
+	res := make([]*regexp.Regexp, len(ExampleNames))
+	for i, ex := range ExampleNames {
+		res[i] = regexp.MustCompile(`(?i)\b` + regexp.QuoteMeta(ex) + `\b`)
+	}
+	return res
+}()
+
+// protagonist returns the regexp of the example name text tells its story
+// about, the first one it mentions, or nil when it has none. Any later
+// example name is another character, as in a comparison story, and is kept
+// so the student is not compared with themself.
+func protagonist(text, name string) *regexp.Regexp {
+	var first *regexp.Regexp
+	at := len(text)
+	for i, ex := range ExampleNames {
+		if strings.EqualFold(ex, name) {
+			continue
+		}
+		if loc := exampleNameRes[i].FindStringIndex(text); loc != nil && loc[0] < at {
+			first, at = exampleNameRes[i], loc[0]
+		}
+	}
+	return first
+}
+
+// rename puts name in place of what re matches everywhere p can name the
+// student, so the answer key agrees with the story.
+func rename(p Problem, re *regexp.Regexp, name string) Problem {
+	p.Text = re.ReplaceAllLiteralString(p.Text, name)
+	p.Answer = re.ReplaceAllLiteralString(p.Answer, name)
+	p.Choices = renameAll(p.Choices, re, name)
+	p.Accept = renameAll(p.Accept, re, name)
+	return p
+}
+
+func renameAll(ss []string, re *regexp.Regexp, name string) []string {
+	if ss == nil {
+		return nil
+	}
+	out := make([]string, len(ss))
+	for i, s := range ss {
+		out[i] = re.ReplaceAllLiteralString(s, name)
+	}
+	return out
+}
+
+// fixPronouns rewrites the wrong pronouns in text as want. Moving to
+// they/them also makes the verb next to the subject plural ("she buys"
+// becomes "they buy", "does she" becomes "do they").
+func fixPronouns(text string, want Pronouns) string {
+	var out strings.Builder
+	last := 0
+	for _, loc := range wrongPronounRes[want].FindAllStringIndex(text, -1) {
+		w, rest := text[loc[0]:loc[1]], text[loc[1]:]
+		var to string
+		switch strings.ToLower(w) {
+		case "she", "he":
+			to = want.Subject
+		case "him":
+			to = want.Object
+		case "his", "hers":
+			to = want.Possessive
+		case "herself", "himself":
+			to = want.Reflexive
+		case "her":
+			to = want.Possessive
+			if reObjectFollows.MatchString(rest) {
+				to = want.Object // "gave her 3", not "her book"
+			}
+		}
+		before := text[last:loc[0]]
+		if to == They.Subject {
+			if m := rePrevAux.FindStringSubmatchIndex(before); m != nil { // "does she" becomes "do they"
+				aux := before[m[2]:m[3]]
+				before = before[:m[2]] + matchCase(aux, singularVerbs[strings.ToLower(aux)]) + before[m[3]:]
+			}
+		}
+		out.WriteString(before)
+		out.WriteString(matchCase(w, to))
+		last = loc[1]
+		if to == They.Subject {
+			if m := reNextWord.FindStringSubmatchIndex(rest); m != nil {
+				out.WriteString(rest[:m[2]])
+				out.WriteString(pluralVerb(rest[m[2]:m[3]]))
+				last += m[3]
+			}
+		}
+	}
+	out.WriteString(text[last:])
+	return out.String()
+}
+
+var (
+	reObjectFollows = regexp.MustCompile(`^(\s+\d|[.,!?]|\s*$|\s+(a|an|the|some|to|and|with|at|in|on|for|more|back|one|two|three)\b)`)
+	reNextWord      = regexp.MustCompile(`^\s+([A-Za-z]+)`)
+	rePrevAux       = regexp.MustCompile(`(?i)\b(does|is|was|has)\s+$`)
+)
+
+// singularVerbs are the irregular verbs that change after they.
+var singularVerbs = map[string]string{"has": "have", "is": "are", "was": "were", "does": "do", "goes": "go"}
+
+// pluralVerb turns a present-tense verb after "they" plural; other words,
+// such as past tenses and adverbs, are left alone.
+func pluralVerb(verb string) string {
+	lower := strings.ToLower(verb)
+	if v, ok := singularVerbs[lower]; ok {
+		return v
+	}
+	switch {
+	case lower == "always" || lower == "sometimes" || strings.HasSuffix(lower, "ss") ||
+		strings.HasSuffix(lower, "us") || strings.HasSuffix(lower, "is") || !strings.HasSuffix(lower, "s"):
+		return verb
+	case strings.HasSuffix(lower, "ies"):
+		return verb[:len(verb)-3] + "y"
+	case strings.HasSuffix(lower, "ches"), strings.HasSuffix(lower, "shes"), strings.HasSuffix(lower, "xes"):
+		return verb[:len(verb)-2]
+	}
+	return verb[:len(verb)-1]
+}
+
+// matchCase writes to with the capitalization of like.
+func matchCase(like, to string) string {
+	if like != "" && like[0] >= 'A' && like[0] <= 'Z' {
+		return strings.ToUpper(to[:1]) + to[1:]
+	}
+	return to
+}
+
+// ---------- validators ----------
+
+// ExampleNameValidator flags stories that use a name from the prompt
+// examples instead of the student's.
+type ExampleNameValidator struct{}
+
+func (ExampleNameValidator) Name() string { return "example_name" }
+
+func (v ExampleNameValidator) Validate(ps *ProblemSet) []Issue {
+	name := strings.TrimSpace(ps.MetaInfo.Name)
+	return eachProblem(ps, v.Name(), func(p Problem) (Severity, string) {
+		if !isStory(p) {
+			return "", ""
+		}
+		for i, ex := range ExampleNames {
+			if strings.EqualFold(ex, name) || !exampleNameRes[i].MatchString(p.Text) {
+				continue
+			}
+			if !strings.Contains(strings.ToLower(p.Text), strings.ToLower(name)) {
+				return SeverityError, fmt.Sprintf("is about %s, a name from the examples, not %s", ex, name)
+			}
+			if NormalizeKind(p.Kind) != KindComparison {
+				return SeverityWarning, fmt.Sprintf("has %s, a name from the examples", ex)
+			}
+		}
+		return "", ""
+	})
+}
+
+// PronounValidator flags stories that use pronouns for the student other
+// than the ones asked for.
+type PronounValidator struct{}
+
+func (PronounValidator) Name() string { return "pronouns" }
+
+func (v PronounValidator) Validate(ps *ProblemSet) []Issue {
+	if !isEnglish(ps.MetaInfo) {
+		return nil // only English pronouns are known
+	}
+	want := PronounsFor(ps.MetaInfo.Pronouns, ps.MetaInfo.Gender)
+	re := wrongPronounRes[want]
+	return eachProblem(ps, v.Name(), func(p Problem) (Severity, string) {
+		w := re.FindString(p.Text)
+		if !isStory(p) || w == "" {
+			return "", ""
+		}
+		if othersIn(p.Text, ps.MetaInfo.Name) {
+			return SeverityInfo, fmt.Sprintf("uses %q; check it is not about %s, who uses %s", w, ps.MetaInfo.Name, want)
+		}
+		return SeverityError, fmt.Sprintf("uses %q for %s, who uses %s", w, ps.MetaInfo.Name, want)
+	})
+}
@@ -0,0 +1,102 @@
+package problemgenerator
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestPersonalizeExampleNames(t *testing.T) {
+	tests := []struct {
+		name string
+		in   Problem
+		want Problem
+	}{
+		{
+			name: "protagonist renamed everywhere",
+			in:   Problem{Kind: KindArithmetic, Text: "Sam has 3 apples and buys 2 more. How many does Sam have?", Answer: "5"},
+			want: Problem{Kind: KindArithmetic, Text: "Maya has 3 apples and buys 2 more. How many does Maya have?", Answer: "5"},
+		},
+		{
+			name: "comparison keeps the other character",
+			in: Problem{
+				Kind: KindComparison, Operation: OpWhoHasMore,
+				Text:    "Amelia has 12 stickers. Sam has 7 stickers. Who has more stickers?",
+				Answer:  "Amelia",
+				Choices: []string{"Amelia", "Sam"},
+				Accept:  []string{"amelia"},
+			},
+			want: Problem{
+				Kind: KindComparison, Operation: OpWhoHasMore,
+				Text:    "Maya has 12 stickers. Sam has 7 stickers. Who has more stickers?",
+				Answer:  "Maya",
+				Choices: []string{"Maya", "Sam"},
+				Accept:  []string{"Maya"},
+			},
+		},
+		{
+			name: "other character answers",
+			in: Problem{
+				Kind: KindComparison, Operation: OpWhoHasMore,
+				Text:    "Sam builds 9 robots and Amelia builds 14 robots. Who builds more robots?",
+				Answer:  "Amelia",
+				Choices: []string{"Sam", "Amelia"},
+			},
+			want: Problem{
+				Kind: KindComparison, Operation: OpWhoHasMore,
+				Text:    "Maya builds 9 robots and Amelia builds 14 robots. Who builds more robots?",
+				Answer:  "Amelia",
+				Choices: []string{"Maya", "Amelia"},
+			},
+		},
+		{
+			name: "student already in the story",
+			in:   Problem{Kind: KindComparison, Text: "Maya has 5 shells. Sam has 8 shells. How many more shells does Sam have?", Answer: "3"},
+			want: Problem{Kind: KindComparison, Text: "Maya has 5 shells. Sam has 8 shells. How many more shells does Sam have?", Answer: "3"},
+		},
+		{
+			name: "name inside another word",
+			in:   Problem{Kind: KindArithmetic, Text: "Maya eats 4 samosas and 2 more. How many samosas?", Answer: "6"},
+			want: Problem{Kind: KindArithmetic, Text: "Maya eats 4 samosas and 2 more. How many samosas?", Answer: "6"},
+		},
+		{
+			name: "local kinds untouched",
+			in:   Problem{Kind: KindEquation, Text: "Sam 3 + 4 = ?", Answer: "7"},
+			want: Problem{Kind: KindEquation, Text: "Sam 3 + 4 = ?", Answer: "7"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ps := &ProblemSet{MetaInfo: GenerateRequest{Name: "Maya", Pronouns: "she/her"}, Problems: []Problem{tt.in}}
+			Personalize(ps)
+			got := ps.Problems[0]
+			if got.Text != tt.want.Text || got.Answer != tt.want.Answer ||
+				!slices.Equal(got.Choices, tt.want.Choices) || !slices.Equal(got.Accept, tt.want.Accept) {
+				t.Errorf("Personalize:\n got %q %q %q %q\nwant %q %q %q %q",
+					got.Text, got.Answer, got.Choices, got.Accept,
+					tt.want.Text, tt.want.Answer, tt.want.Choices, tt.want.Accept)
+			}
+		})
+	}
+}
+
+func TestFixPronouns(t *testing.T) {
+	tests := []struct {
+		text string
+		want Pronouns
+		out  string
+	}{
+		{"Maya has 3 kites. She buys 2 more.", He, "Maya has 3 kites. He buys 2 more."},
+		{"Maya has 3 kites. He buys 2 more for his sister.", They, "Maya has 3 kites. They buy 2 more for their sister."},
+		{"How many does she have?", They, "How many do they have?"},
+		{"Maya gave her 3 cards.", He, "Maya gave him 3 cards."},
+		{"Maya packs her bag.", He, "Maya packs his bag."},
+		{"He catches 4 fish.", They, "They catch 4 fish."},
+		{"She studies 5 maps.", They, "They study 5 maps."},
+		{"They have 5 maps.", She, "They have 5 maps."},
+	}
+	for _, tt := range tests {
+		if got := fixPronouns(tt.text, tt.want); got != tt.out {
+			t.Errorf("fixPronouns(%q, %s) = %q, want %q", tt.text, tt.want, got, tt.out)
+		}
+	}
+}
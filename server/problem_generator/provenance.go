@@ -0,0 +1,217 @@
+package problemgenerator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ProvenanceVersion is the version of the Provenance record format. It goes
+// up whenever a field is renamed or changes meaning, so old records can
+// still be read.
+const ProvenanceVersion = 1
+
+// Provenance is everything that went into one problem set: the request, the
+// model and options, every prompt and raw reply, what each attempt's
+// validation found, and the set that came out. It is kept to debug a set
+// and to render it again.
+type Provenance struct {
+	Version  int             `json:"version"`
+	ID       string          `json:"id"`
+	Kind     string          `json:"kind"` // generate | translate
+	Started  time.Time       `json:"started"`
+	Finished time.Time       `json:"finished"`
+	Model    string          `json:"model"`
+	Options  RunOptions      `json:"options"`
+	Request  json.RawMessage `json:"request"` // as the client sent it
+
+	Calls    []ModelCall `json:"calls"`    // in the order they were made
+	Attempts []Attempt   `json:"attempts"` // generate: one per pass of the validation loop
+
+	ProblemSet *ProblemSet `json:"problem_set,omitempty"`
+	Error      string      `json:"error,omitempty"` // why no set came out
+
+	mu sync.Mutex
+}
+
+// RunOptions are the server settings a set was made with.
+type RunOptions struct {
+	Agent      string   `json:"agent"`
+	Hybrid     bool     `json:"hybrid,omitempty"`
+	Offline    bool     `json:"offline,omitempty"`
+	Classifier bool     `json:"classifier,omitempty"`
+	Explain    bool     `json:"explain,omitempty"`
+	Validators []string `json:"validators"`
+	Language   string   `json:"language,omitempty"` // translate: the language translated into
+}
+
+// ModelCall is one prompt sent to the model and what came back.
+type ModelCall struct {
+	Purpose    string        `json:"purpose"` // generate | regenerate | simplify | safety | translate | explain
+	System     string        `json:"system"`
+	User       string        `json:"user"`
+	Response   string        `json:"response"`
+	Error      string        `json:"error,omitempty"`       // the call failed
+	ParseError string        `json:"parse_error,omitempty"` // the reply could not be used
+	Duration   time.Duration `json:"duration_ns"`
+}
+
+// Attempt is what validation found on one pass of generating a set.
+type Attempt struct {
+	Number int     `json:"number"`
+	Issues []Issue `json:"issues"`
+}
+
+// NewProvenance starts the record of a set made with id.
+func NewProvenance(id, kind, model string) *Provenance {
+	return &Provenance{Version: ProvenanceVersion, ID: id, Kind: kind, Started: time.Now(), Model: model}
+}
+
+// AddCall records a call to the model. A nil Provenance drops it.
+func (p *Provenance) AddCall(c ModelCall) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.Calls = append(p.Calls, c)
+}
+
+// ParseFailed notes that the reply of the last call could not be used.
+func (p *Provenance) ParseFailed(err error) {
+	if p == nil || err == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if n := len(p.Calls); n > 0 {
+		p.Calls[n-1].ParseError = err.Error()
+	}
+}
+
+// AddAttempt records what validation found on attempt number.
+func (p *Provenance) AddAttempt(number int, issues []Issue) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.Attempts = append(p.Attempts, Attempt{Number: number, Issues: slices.Clone(issues)})
+}
+
+// Renumber moves the issues of every attempt to the problems' new numbers,
+// once Arrange has put the set in its printed order.
+func (p *Provenance) Renumber(renumber map[int]int) {
+	if p == nil || len(renumber) == 0 {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, a := range p.Attempts {
+		for i, issue := range a.Issues {
+			if n, ok := renumber[issue.Index]; ok {
+				a.Issues[i].Index = n
+			}
+		}
+	}
+}
+
+// Finish records the set that came out, or the error that stopped it.
+func (p *Provenance) Finish(ps *ProblemSet, err error) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.Finished = time.Now()
+	p.ProblemSet = ps
+	if err != nil {
+		p.Error = err.Error()
+	}
+}
+
+// ---------- store ----------
+
+// Errors Load returns for an ID with no record, and for one that cannot be
+// an ID at all.
+var (
+	ErrNoProvenance = errors.New("no provenance record")
+	ErrProvenanceID = errors.New("invalid provenance id")
+)
+
+var reProvenanceID = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// ProvenanceStore keeps provenance records as one JSON file per set, named
+// after its ID, in a directory.
+type ProvenanceStore struct {
+	dir string
+}
+
+// NewProvenanceStore keeps records in dir, which is created if need be.
+func NewProvenanceStore(dir string) (*ProvenanceStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &ProvenanceStore{dir: dir}, nil
+}
+
+// Save writes p, replacing any earlier record with its ID. A nil store
+// drops it.
+func (s *ProvenanceStore) Save(p *Provenance) error {
+	if s == nil {
+		return nil
+	}
+	path, err := s.path(p.ID)
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	data, err := json.MarshalIndent(p, "", "  ")
+	p.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path) // readers never see half a record
+}
+
+// Load reads the record with id.
+func (s *ProvenanceStore) Load(id string) (*Provenance, error) {
+	path, err := s.path(id)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w for %s", ErrNoProvenance, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var p Provenance
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("provenance %s: %v", id, err)
+	}
+	if p.Version > ProvenanceVersion {
+		return nil, fmt.Errorf("provenance %s: version %d is newer than this server reads (%d)", id, p.Version, ProvenanceVersion)
+	}
+	return &p, nil
+}
+
+// path is the file of the record with id. IDs are checked so a request
+// cannot read outside the store.
+func (s *ProvenanceStore) path(id string) (string, error) {
+	if !reProvenanceID.MatchString(id) {
+		return "", fmt.Errorf("%w %q", ErrProvenanceID, id)
+	}
+	return filepath.Join(s.dir, id+".json"), nil
+}
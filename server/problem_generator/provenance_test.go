@@ -0,0 +1,94 @@
+package problemgenerator
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProvenanceStore(t *testing.T) {
+	s, err := NewProvenanceStore(filepath.Join(t.TempDir(), "provenance"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := NewProvenance("set-1", "generate", "gemma3")
+	rec.Request = json.RawMessage(`{"name":"Ana"}`)
+	rec.Options = RunOptions{Agent: "json", Hybrid: true, Validators: []string{"range", "safety"}}
+	rec.AddCall(ModelCall{Purpose: "generate", System: "sys", User: "user", Response: "not json"})
+	rec.ParseFailed(errors.New("bad reply"))
+	rec.AddAttempt(1, []Issue{{Index: 2, Check: "range", Severity: SeverityWarning, Message: "too big"}})
+	ps := &ProblemSet{Problems: []Problem{{Index: 1, Text: "Ana has 3 cats.", Answer: "3"}}}
+	rec.Finish(ps, nil)
+	if err := s.Save(rec); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := s.Load("set-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var req struct{ Name string }
+	if err := json.Unmarshal(got.Request, &req); err != nil {
+		t.Fatal(err)
+	}
+	switch {
+	case got.Version != ProvenanceVersion || got.ID != "set-1" || got.Kind != "generate" || got.Model != "gemma3":
+		t.Errorf("Load = %+v, want the saved header", got)
+	case req.Name != "Ana" || !got.Options.Hybrid || len(got.Options.Validators) != 2:
+		t.Errorf("Load request %s, options %+v", got.Request, got.Options)
+	case len(got.Calls) != 1 || got.Calls[0].ParseError != "bad reply" || got.Calls[0].Response != "not json":
+		t.Errorf("Load calls = %+v", got.Calls)
+	case len(got.Attempts) != 1 || got.Attempts[0].Issues[0].Message != "too big":
+		t.Errorf("Load attempts = %+v", got.Attempts)
+	case got.ProblemSet == nil || got.ProblemSet.Problems[0].Text != "Ana has 3 cats." || got.Error != "":
+		t.Errorf("Load set = %+v, error %q", got.ProblemSet, got.Error)
+	case !got.Finished.Equal(rec.Finished):
+		t.Errorf("Load finished %v, want %v", got.Finished, rec.Finished)
+	}
+
+	failed := NewProvenance("set-2", "translate", "gemma3")
+	failed.Finish(nil, errors.New("model is down"))
+	if err := s.Save(failed); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := s.Load("set-2"); err != nil || got.Error != "model is down" || got.ProblemSet != nil {
+		t.Errorf("Load(set-2) = %+v, %v; want the error kept", got, err)
+	}
+}
+
+func TestProvenanceStoreErrors(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewProvenanceStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Load("missing"); !errors.Is(err, ErrNoProvenance) {
+		t.Errorf("Load(missing) error = %v, want ErrNoProvenance", err)
+	}
+	for _, id := range []string{"", "../secret", "a/b", "set.json"} {
+		if _, err := s.Load(id); !errors.Is(err, ErrProvenanceID) {
+			t.Errorf("Load(%q) error = %v, want ErrProvenanceID", id, err)
+		}
+		if err := s.Save(&Provenance{ID: id}); !errors.Is(err, ErrProvenanceID) {
+			t.Errorf("Save(%q) error = %v, want ErrProvenanceID", id, err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "future.json"), []byte(`{"version": 99, "id": "future"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Load("future"); err == nil {
+		t.Error("Load of a newer record version succeeded")
+	}
+
+	var none *ProvenanceStore
+	if err := none.Save(NewProvenance("x", "generate", "")); err != nil {
+		t.Errorf("nil store Save = %v", err)
+	}
+	var rec *Provenance
+	rec.AddCall(ModelCall{})
+	rec.AddAttempt(1, nil)
+	rec.Finish(nil, nil)
+}
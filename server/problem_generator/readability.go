@@ -0,0 +1,260 @@
+package problemgenerator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Readability describes how hard a problem's text is to read.
+type Readability struct {
+	Words            int
+	Sentences        int
+	WordsPerSentence float64
+	SyllablesPerWord float64
+	Grade            float64  // Flesch-Kincaid grade
+	HardWords        []string // long words that are not sight words or familiar
+}
+
+// ReadingTarget is the most a grade can be expected to read alone.
+type ReadingTarget struct {
+	MaxGrade            float64
+	MaxWordsPerSentence float64
+	MaxHardWords        int
+}
+
+// ReadingTargetFor returns the reading target for a grade level.
+func ReadingTargetFor(level string) ReadingTarget {
+	switch grade := GradeNumber(level); {
+	case grade <= 0:
+		return ReadingTarget{MaxGrade: 2, MaxWordsPerSentence: 8, MaxHardWords: 0}
+	case grade == 1:
+		return ReadingTarget{MaxGrade: 3, MaxWordsPerSentence: 10, MaxHardWords: 1}
+	case grade == 2:
+		return ReadingTarget{MaxGrade: 4, MaxWordsPerSentence: 12, MaxHardWords: 2}
+	case grade == 3:
+		return ReadingTarget{MaxGrade: 5, MaxWordsPerSentence: 14, MaxHardWords: 3}
+	default:
+		return ReadingTarget{MaxGrade: float64(grade) + 2, MaxWordsPerSentence: 16, MaxHardWords: 5}
+	}
+}
+
+// Exceeded lists the ways r is above the target, or nothing when the text is
+// fine for the grade.
+func (t ReadingTarget) Exceeded(r Readability) []string {
+	var reasons []string
+	if r.Grade > t.MaxGrade {
+		reasons = append(reasons, fmt.Sprintf("reads at grade %.1f", r.Grade))
+	}
+	if r.WordsPerSentence > t.MaxWordsPerSentence {
+		reasons = append(reasons, fmt.Sprintf("%.0f words per sentence", r.WordsPerSentence))
+	}
+	if len(r.HardWords) > t.MaxHardWords {
+		reasons = append(reasons, "hard words: "+strings.Join(r.HardWords, ", "))
+	}
+	return reasons
+}
+
+var (
+	reSentence = regexp.MustCompile(`[.!?]+`)
+	reWord     = regexp.MustCompile(`[A-Za-z']+`)
+	reVowels   = regexp.MustCompile(`[aeiouy]+`)
+)
+
+// MeasureReadability scores text. Numbers are not counted as words, and
+// familiar words (the student's name and interests) count as one-syllable
+// words the student knows, however long they are.
+func MeasureReadability(text string, familiar ...string) Readability {
+	words := reWord.FindAllString(text, -1)
+	if len(words) == 0 {
+		return Readability{}
+	}
+	sentences := 0
+	for _, s := range reSentence.Split(text, -1) {
+		if reWord.MatchString(s) {
+			sentences++
+		}
+	}
+	sentences = max(sentences, 1)
+
+	known := map[string]bool{}
+	for _, f := range familiar {
+		for _, w := range reWord.FindAllString(strings.ToLower(f), -1) {
+			known[w] = true
+		}
+	}
+	r := Readability{Words: len(words), Sentences: sentences}
+	syllables := 0
+	seen := map[string]bool{}
+	for _, w := range words {
+		lower := strings.ToLower(w)
+		if isFamiliar(known, lower) {
+			syllables++
+			continue
+		}
+		n := countSyllables(lower)
+		syllables += n
+		if n >= 3 && !isSightWord(lower) && !seen[lower] {
+			seen[lower] = true
+			r.HardWords = append(r.HardWords, w)
+		}
+	}
+	r.WordsPerSentence = float64(len(words)) / float64(sentences)
+	r.SyllablesPerWord = float64(syllables) / float64(len(words))
+	r.Grade = max(0.39*r.WordsPerSentence+11.8*r.SyllablesPerWord-15.59, 0)
+	return r
+}
+
+// ReadingGrade is the Flesch-Kincaid grade of text, see MeasureReadability.
+func ReadingGrade(text string, familiar ...string) float64 {
+	return MeasureReadability(text, familiar...).Grade
+}
+
+// familiarWords are the words a student knows from their own request.
+func familiarWords(meta GenerateRequest) []string {
+	familiar := append([]string{meta.Name}, meta.LikesNouns...)
+	return append(familiar, meta.LikesVerbs...)
+}
+
+// isFamiliar matches a word against known words, allowing plurals and -ing
+// forms ("dinosaurs", "dancing").
+func isFamiliar(known map[string]bool, w string) bool {
+	if known[w] || known[strings.TrimSuffix(w, "s")] || known[w+"s"] {
+		return true
+	}
+	if stem, ok := strings.CutSuffix(w, "ing"); ok {
+		return known[stem] || known[stem+"e"] || (len(stem) > 1 && known[stem[:len(stem)-1]])
+	}
+	return false
+}
+
+// countSyllables counts vowel groups, dropping a silent final "e".
+func countSyllables(word string) int {
+	w := strings.ToLower(strings.Trim(word, "'"))
+	if len(w) > 2 && strings.HasSuffix(w, "e") && !strings.HasSuffix(w, "le") {
+		w = w[:len(w)-1]
+	}
+	return max(len(reVowels.FindAllString(w, -1)), 1)
+}
+
+func isSightWord(w string) bool {
+	return sightWords[w] || sightWords[strings.TrimSuffix(w, "s")]
+}
+
+// dolchWords are the Dolch sight words from pre-primer to 3rd grade, the
+// words that hold a sentence together.
+const dolchWords = `
+		a and away big blue can come down find for funny go help here i in is it jump little look make me my not one play red run said see the three to two up we where yellow you
+		all am are at ate be black brown but came did do eat four get good have he into like must new no now on our out please pretty ran ride saw say she so soon that there they this too under want was well went what white who will with yes
+		after again an any as ask by could every fly from give going had has her him his how just know let live may of old once open over put round some stop take thank them then think walk were when
+		always around because been before best both buy call cold does don't fast first five found gave goes green its made many off or pull read right sing sit sleep tell their these those upon us use very wash which why wish work would write your
+		about better bring carry clean cut done draw drink eight fall far full got grow hold hot hurt if keep kind laugh light long much myself never only own pick seven shall show six small start ten today together try warm
+`
+
+// dolchNouns are the Dolch sight-word nouns.
+const dolchNouns = `
+		apple baby back ball bear bed bell bird birthday boat box boy bread brother cake car cat chair chicken children christmas coat corn cow day dog doll door duck egg eye farm farmer father feet fire fish floor flower game garden girl goodbye grass ground hand head hill home horse house kitty leg letter man men milk money morning mother name nest night paper party picture pig rabbit rain ring robin santa school seed sheep shoe sister snow song squirrel stick street sun table thing time top toy tree watch water way wind window wood
+`
+
+// mathWords are the words every math story uses.
+const mathWords = `
+		altogether total more less left each equal equally share number numbers many how much group groups row rows another everyone everybody
+`
+
+// sightWords are the words a young reader knows on sight.
+var sightWords = wordSet(dolchWords, dolchNouns, mathWords)
+
+func wordSet(lists ...string) map[string]bool {
+	m := map[string]bool{}
+	for _, list := range lists {
+		for _, w := range strings.Fields(list) {
+			m[w] = true
+		}
+	}
+	return m
+}
+
+// ---------- scoring and rewriting ----------
+
+// ScoreReadability sets the ReadingGrade of every problem in ps. Stories in
+// other languages than English are not scored.
+func ScoreReadability(ps *ProblemSet) {
+	if !isEnglish(ps.MetaInfo) {
+		return
+	}
+	familiar := familiarWords(ps.MetaInfo)
+	for i := range ps.Problems {
+		ps.Problems[i].ReadingGrade = ReadingGrade(ps.Problems[i].Text, familiar...)
+	}
+}
+
+// NeedsSimplifying reports whether p is a model-written story that is too
+// hard to read for meta's grade, along with how it measured.
+func NeedsSimplifying(p Problem, meta GenerateRequest) (Readability, bool) {
+	if !isStory(p) || !isEnglish(meta) {
+		return Readability{}, false
+	}
+	r := MeasureReadability(p.Text, familiarWords(meta)...)
+	return r, len(ReadingTargetFor(meta.GradeLevel).Exceeded(r)) > 0
+}
+
+// AcceptRewrite checks a simplified version of p's text. It must state the
+// same numbers in the same order, keep the student's name, still ask a
+// question, solve to the same answer with the solver of p's kind and be
+// easier to read than the original; the cleaned-up text is returned.
+func AcceptRewrite(p Problem, text string, meta GenerateRequest) (string, error) {
+	text = strings.Trim(strings.TrimSpace(cleanCodeBlock(text)), `"`)
+	if text == "" {
+		return "", fmt.Errorf("rewrite is empty")
+	}
+	before, after := reInts.FindAllString(p.Text, -1), reInts.FindAllString(text, -1)
+	if strings.Join(before, ",") != strings.Join(after, ",") {
+		return "", fmt.Errorf("rewrite changed the numbers from [%s] to [%s]",
+			strings.Join(before, ", "), strings.Join(after, ", "))
+	}
+	if name := strings.TrimSpace(meta.Name); name != "" && mentions(p.Text, name) && !mentions(text, name) {
+		return "", fmt.Errorf("rewrite lost the name %s", name)
+	}
+	if strings.HasSuffix(strings.TrimSpace(p.Text), "?") && !strings.HasSuffix(text, "?") {
+		return "", fmt.Errorf("rewrite does not end with a question")
+	}
+	// The numbers alone settle arithmetic; units and who owns which amount
+	// are in the words, so those kinds are solved again.
+	if want, err := solveStory(p, p.Text, meta); err == nil {
+		got, err := solveStory(p, text, meta)
+		if err != nil {
+			return "", fmt.Errorf("rewrite cannot be solved: %v", err)
+		}
+		if got != want {
+			return "", fmt.Errorf("rewrite changed the problem from %s to %s", want, got)
+		}
+	}
+	familiar := familiarWords(meta)
+	if ReadingGrade(text, familiar...) >= ReadingGrade(p.Text, familiar...) {
+		return "", fmt.Errorf("rewrite is no easier to read")
+	}
+	return text, nil
+}
+
+// solveStory works text out as a problem of p's kind and describes what it
+// found (the amounts and answer), so two versions of a story can be
+// compared. Kinds decided by their numbers alone give "".
+func solveStory(p Problem, text string, meta GenerateRequest) (string, error) {
+	switch NormalizeKind(p.Kind) {
+	case KindMeasurement:
+		nums, answer, _, err := solveMeasurement(text, p.Operation, "", PolicyFor(meta.GradeLevel, meta.UnitSystem))
+		return fmt.Sprintf("%v → %s", nums, answer), err
+	case KindComparison:
+		// Name questions keep the compared names as their choices.
+		var names []string
+		if op := normalizeComparisonOp(p.Operation); op == OpWhoHasMore || op == OpWhoHasFewer {
+			names = p.Choices
+		}
+		nums, answer, _, err := solveComparison(text, p.Operation, names)
+		return fmt.Sprintf("%v → %s", nums, answer), err
+	case KindMissing:
+		_, _, equation, err := solveMissingStory(text, p.Operation, p.Unknown)
+		return equation, err
+	}
+	return "", nil
+}
@@ -0,0 +1,128 @@
+package problemgenerator
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMeasureReadability(t *testing.T) {
+	tests := []struct {
+		text               string
+		familiar           []string
+		words, sentences   int
+		hard               []string
+		minGrade, maxGrade float64
+	}{
+		{text: "", words: 0, sentences: 0},
+		{text: "Ana has 3 cats. She gets 2 more. How many now?", familiar: []string{"Ana"}, words: 9, sentences: 3, minGrade: 0, maxGrade: 1},
+		{text: "Leo has 12 dinosaurs.", familiar: []string{"Leo", "dinosaurs"}, words: 3, sentences: 1, maxGrade: 1},
+		{text: "Leo has 12 dinosaurs.", familiar: []string{"Leo"}, words: 3, sentences: 1, hard: []string{"dinosaurs"}, minGrade: 3, maxGrade: 20},
+		{text: "Ana is dancing with everybody", familiar: []string{"Ana", "dance"}, words: 5, sentences: 1, maxGrade: 20},
+		{
+			text:  "The extraordinarily enthusiastic photographer photographed approximately 40 butterflies.",
+			words: 7, sentences: 1,
+			hard:     []string{"extraordinarily", "enthusiastic", "photographer", "photographed", "approximately", "butterflies"},
+			minGrade: 12, maxGrade: 40,
+		},
+	}
+	for _, tt := range tests {
+		r := MeasureReadability(tt.text, tt.familiar...)
+		if r.Words != tt.words || r.Sentences != tt.sentences || !slices.Equal(r.HardWords, tt.hard) {
+			t.Errorf("MeasureReadability(%q) = %d words, %d sentences, hard %q; want %d, %d, %q",
+				tt.text, r.Words, r.Sentences, r.HardWords, tt.words, tt.sentences, tt.hard)
+		}
+		if r.Grade < tt.minGrade || r.Grade > tt.maxGrade {
+			t.Errorf("MeasureReadability(%q).Grade = %.1f, want %.0f to %.0f", tt.text, r.Grade, tt.minGrade, tt.maxGrade)
+		}
+	}
+}
+
+func TestReadingTargetFor(t *testing.T) {
+	tests := []struct {
+		level string
+		want  ReadingTarget
+	}{
+		{"K", ReadingTarget{MaxGrade: 2, MaxWordsPerSentence: 8, MaxHardWords: 0}},
+		{"grade 1", ReadingTarget{MaxGrade: 3, MaxWordsPerSentence: 10, MaxHardWords: 1}},
+		{"2nd grade", ReadingTarget{MaxGrade: 4, MaxWordsPerSentence: 12, MaxHardWords: 2}},
+		{"grade 3", ReadingTarget{MaxGrade: 5, MaxWordsPerSentence: 14, MaxHardWords: 3}},
+		{"grade 5", ReadingTarget{MaxGrade: 7, MaxWordsPerSentence: 16, MaxHardWords: 5}},
+	}
+	for _, tt := range tests {
+		if got := ReadingTargetFor(tt.level); got != tt.want {
+			t.Errorf("ReadingTargetFor(%q) = %+v, want %+v", tt.level, got, tt.want)
+		}
+	}
+
+	k := ReadingTargetFor("K")
+	if got := k.Exceeded(Readability{Grade: 1.5, WordsPerSentence: 6}); got != nil {
+		t.Errorf("Exceeded(easy) = %q, want nothing", got)
+	}
+	got := k.Exceeded(Readability{Grade: 3.2, WordsPerSentence: 11, HardWords: []string{"dinosaurs"}})
+	want := []string{"reads at grade 3.2", "11 words per sentence", "hard words: dinosaurs"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Exceeded(hard) = %q, want %q", got, want)
+	}
+}
+
+func TestAcceptRewrite(t *testing.T) {
+	meta := GenerateRequest{Name: "Ana", GradeLevel: "grade 1"}
+	p := Problem{
+		Kind: KindArithmetic, Operation: OpAddition,
+		Text: "Ana meticulously accumulated 3 extraordinarily iridescent seashells and subsequently discovered 4 additional seashells. How many seashells does Ana possess altogether?",
+	}
+	tests := []struct {
+		name, text, want string
+		err              bool
+	}{
+		{name: "simpler", text: "Ana has 3 shells. She finds 4 more. How many shells does Ana have now?", want: "Ana has 3 shells. She finds 4 more. How many shells does Ana have now?"},
+		{name: "quoted in a fence", text: "```\n\"Ana has 3 shells. She finds 4 more. How many now?\"\n```", want: "Ana has 3 shells. She finds 4 more. How many now?"},
+		{name: "empty", text: "  ", err: true},
+		{name: "numbers changed", text: "Ana has 3 shells. She finds 5 more. How many now?", err: true},
+		{name: "numbers swapped", text: "Ana has 4 shells. She finds 3 more. How many now?", err: true},
+		{name: "no question", text: "Ana has 3 shells. She finds 4 more.", err: true},
+		{name: "no easier", text: p.Text, err: true},
+	}
+	for _, tt := range tests {
+		got, err := AcceptRewrite(p, tt.text, meta)
+		if (err != nil) != tt.err || got != tt.want {
+			t.Errorf("%s: AcceptRewrite = %q, %v; want %q, error %v", tt.name, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestAcceptRewriteKeepsTheMath(t *testing.T) {
+	meta := GenerateRequest{Name: "Ana", GradeLevel: "grade 3"}
+	measurement := Problem{
+		Kind: KindMeasurement, Operation: OpSubtraction,
+		Text: "Ana's extraordinarily magnificent ribbon measured 230 cm originally, but she subsequently removed 45 cm. What length remains?",
+	}
+	comparison := Problem{
+		Kind: KindComparison, Operation: OpWhoHasMore, Choices: []string{"Ana", "Leo"},
+		Text: "Ana meticulously accumulated 3 iridescent seashells, whereas Leo accumulated 7 iridescent seashells. Who accumulated more?",
+	}
+	missing := Problem{
+		Kind: KindMissing, Operation: OpAddition, Unknown: SlotB,
+		Text: "Ana possessed 5 extraordinarily colorful marbles and subsequently acquired additional marbles, possessing 9 altogether. How many were acquired?",
+	}
+	tests := []struct {
+		name string
+		p    Problem
+		text string
+		err  bool
+	}{
+		{name: "measurement kept", p: measurement, text: "Ana has a ribbon 230 cm long. She cuts off 45 cm. How long is it now?"},
+		{name: "measurement unit changed", p: measurement, text: "Ana has a ribbon 230 m long. She cuts off 45 cm. How long is it now?", err: true},
+		{name: "measurement unit dropped", p: measurement, text: "Ana has a ribbon 230 long. She cuts off 45 cm. How long is it now?", err: true},
+		{name: "comparison kept", p: comparison, text: "Ana has 3 shells. Leo has 7 shells. Who has more?"},
+		{name: "comparison names swapped", p: comparison, text: "Leo has 3 shells. Ana has 7 shells. Who has more?", err: true},
+		{name: "comparison name lost", p: comparison, text: "Ana has 3 shells. Her friend has 7 shells. Who has more?", err: true},
+		{name: "missing kept", p: missing, text: "Ana has 5 marbles. She gets some more. Now she has 9. How many did she get?"},
+		{name: "student name lost", p: missing, text: "Sam has 5 marbles. He gets some more. Now he has 9. How many did he get?", err: true},
+	}
+	for _, tt := range tests {
+		if _, err := AcceptRewrite(tt.p, tt.text, meta); (err != nil) != tt.err {
+			t.Errorf("%s: AcceptRewrite error = %v, want error %v", tt.name, err, tt.err)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package problemgenerator
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	pb "github.com/qjs/mathgen_gemma/server/proto"
+)
+
+// Regrouping says which addition and subtraction problems need carrying or
+// borrowing. It is one of the Regroup* modes, or the place values that must
+// regroup, such as "tens" or "ones,tens"; "" allows anything.
+type Regrouping string
+
+const (
+	RegroupNone = "none" // no problem carries or borrows
+	RegroupSome = "some" // every other problem does
+	RegroupAll  = "all"  // every problem does
+)
+
+// ParseRegrouping reads a regrouping rule from a form or request. Places may
+// be separated by commas, "+" or "and".
+func ParseRegrouping(s string) (Regrouping, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch s {
+	case "", "any":
+		return "", nil
+	case "none", "no", "without":
+		return RegroupNone, nil
+	case "some", "mixed":
+		return RegroupSome, nil
+	case "all", "with", "every":
+		return RegroupAll, nil
+	}
+	var places []string
+	for _, f := range strings.FieldsFunc(strings.ReplaceAll(s, " and ", ","), func(r rune) bool { return r == ',' || r == '+' || r == ' ' }) {
+		if f == "place" || f == "places" || f == "only" {
+			continue
+		}
+		f = strings.TrimSuffix(f, "s") + "s" // "ten" or "tens"
+		if !slices.Contains(placeNames, f) {
+			return "", fmt.Errorf("unknown regrouping %q: want none, some, all or places such as tens", s)
+		}
+		if !slices.Contains(places, f) {
+			places = append(places, f)
+		}
+	}
+	slices.SortFunc(places, func(a, b string) int { return slices.Index(placeNames, a) - slices.Index(placeNames, b) })
+	return Regrouping(strings.Join(places, ",")), nil
+}
+
+// normalizeRegrouping is the canonical form of a rule, or "" when it
+// cannot be read (ResolveRegrouping reports that).
+func normalizeRegrouping(s string) string {
+	r, _ := ParseRegrouping(s)
+	return string(r)
+}
+
+// ResolveRegrouping checks the regrouping rule of req and writes it in
+// canonical form.
+func ResolveRegrouping(req *pb.GenerateRequest) error {
+	r, err := ParseRegrouping(req.Regrouping)
+	if err != nil {
+		return err
+	}
+	req.Regrouping = string(r)
+	return nil
+}
+
+// top raises a planner's largest number so the places a rule names can
+// regroup at all: carrying in the tens needs sums past 100.
+func (r Regrouping) top(top int) int {
+	for _, col := range r.places() {
+		need := 2
+		for i := 0; i <= col; i++ {
+			need *= 10
+		}
+		top = max(top, need)
+	}
+	return top
+}
+
+// places is the columns a places rule names, as 0 for ones, 1 for tens, ….
+func (r Regrouping) places() []int {
+	var cols []int
+	for _, name := range strings.Split(string(r), ",") {
+		if i := slices.Index(placeNames, name); i >= 0 {
+			cols = append(cols, i)
+		}
+	}
+	return cols
+}
+
+// ForSlot is the rule for the problem with index: RegroupSome alternates
+// between none and all, starting without.
+func (r Regrouping) ForSlot(index int) Regrouping {
+	if r != RegroupSome {
+		return r
+	}
+	if index%2 == 0 {
+		return RegroupAll
+	}
+	return RegroupNone
+}
+
+// Allows reports whether a op b, the problem with index, follows the rule.
+// Only addition and subtraction regroup; everything else is allowed.
+func (r Regrouping) Allows(op string, a, b, index int) bool {
+	if r == "" || (op != OpAddition && op != OpSubtraction) {
+		return true
+	}
+	cols := regroupColumns(op, a, b)
+	switch rule := r.ForSlot(index); rule {
+	case RegroupNone:
+		return len(cols) == 0
+	case RegroupAll:
+		return len(cols) > 0
+	default:
+		return slices.Equal(cols, rule.places())
+	}
+}
+
+// Describe is how prompts and issues speak of the rule for one problem.
+func (r Regrouping) Describe(op string) string {
+	verb := "carrying"
+	if op == OpSubtraction {
+		verb = "borrowing"
+	} else if op != OpAddition {
+		verb = "carrying or borrowing"
+	}
+	switch r {
+	case RegroupNone:
+		return "no " + verb
+	case RegroupAll:
+		return verb + " in at least one place"
+	}
+	return verb + " in the " + strings.ReplaceAll(string(r), ",", " and ") + " place only"
+}
+
+// regroupColumns lists the columns of a op b that carry (addition) or
+// borrow (subtraction), ones being 0. A carry or borrow passed on from the
+// column before counts, as in 95 + 5.
+func regroupColumns(op string, a, b int) []int {
+	var cols []int
+	carry := 0
+	for col := 0; a > 0 || b > 0; col, a, b = col+1, a/10, b/10 {
+		da, db := a%10, b%10
+		switch op {
+		case OpAddition:
+			if da+db+carry >= 10 {
+				cols, carry = append(cols, col), 1
+			} else {
+				carry = 0
+			}
+		case OpSubtraction:
+			if da-carry < db {
+				cols, carry = append(cols, col), 1
+			} else {
+				carry = 0
+			}
+		}
+	}
+	return cols
+}
+
+// ---------- validator ----------
+
+// RegroupingValidator checks parsed problems against the requested
+// regrouping rule. The planner always follows it; the model may not.
+type RegroupingValidator struct{}
+
+func (RegroupingValidator) Name() string { return "regrouping" }
+
+func (v RegroupingValidator) Validate(ps *ProblemSet) []Issue {
+	rule := Regrouping(ps.MetaInfo.Regrouping)
+	return eachProblem(ps, v.Name(), func(p Problem) (Severity, string) {
+		kind := NormalizeKind(p.Kind)
+		if rule == "" || len(p.Numbers) < 2 || (kind != KindArithmetic && kind != KindMissing && kind != KindEquation) {
+			return "", ""
+		}
+		op := NormalizeOperation(p.Operation)
+		if rule.Allows(op, p.Numbers[0], p.Numbers[1], PlannedSlot(p)) {
+			return "", ""
+		}
+		sev := SeverityError
+		if rule == RegroupSome {
+			sev = SeverityWarning // the mix is off, but each problem is still fine
+		}
+		return sev, fmt.Sprintf("%d %s %d should need %s", p.Numbers[0], OpSymbol(op), p.Numbers[1], rule.ForSlot(PlannedSlot(p)).Describe(op))
+	})
+}
@@ -0,0 +1,91 @@
+package problemgenerator
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseRegrouping(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Regrouping
+		err  bool
+	}{
+		{in: "", want: ""},
+		{in: "without", want: RegroupNone},
+		{in: "Mixed", want: RegroupSome},
+		{in: "every", want: RegroupAll},
+		{in: "ten", want: "tens"},
+		{in: "tens and ones", want: "ones,tens"},
+		{in: "hundreds + tens only", want: "tens,hundreds"},
+		{in: "tens,tens", want: "tens"},
+		{in: "dozens", err: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseRegrouping(tt.in)
+		if (err != nil) != tt.err || got != tt.want {
+			t.Errorf("ParseRegrouping(%q) = %q, %v; want %q, error %v", tt.in, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestRegroupColumns(t *testing.T) {
+	tests := []struct {
+		op   string
+		a, b int
+		want []int
+	}{
+		{OpAddition, 23, 45, nil},
+		{OpAddition, 27, 15, []int{0}},
+		{OpAddition, 95, 5, []int{0, 1}}, // the carry into the tens carries again
+		{OpAddition, 999, 1, []int{0, 1, 2}},
+		{OpAddition, 150, 70, []int{1}},
+		{OpSubtraction, 58, 23, nil},
+		{OpSubtraction, 42, 17, []int{0}},
+		{OpSubtraction, 100, 1, []int{0, 1}}, // the tens had nothing to lend
+		{OpSubtraction, 305, 126, []int{0, 1}},
+		{OpSubtraction, 520, 310, nil},
+	}
+	for _, tt := range tests {
+		if got := regroupColumns(tt.op, tt.a, tt.b); !slices.Equal(got, tt.want) {
+			t.Errorf("regroupColumns(%d %s %d) = %v, want %v", tt.a, OpSymbol(tt.op), tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestRegroupingAllows(t *testing.T) {
+	tests := []struct {
+		rule  Regrouping
+		op    string
+		a, b  int
+		index int
+		want  bool
+	}{
+		{"", OpAddition, 27, 15, 1, true},
+		{RegroupNone, OpAddition, 23, 45, 1, true},
+		{RegroupNone, OpAddition, 27, 15, 1, false},
+		{RegroupAll, OpSubtraction, 42, 17, 1, true},
+		{RegroupAll, OpSubtraction, 58, 23, 1, false},
+		{RegroupSome, OpAddition, 23, 45, 1, true}, // odd slots do not regroup
+		{RegroupSome, OpAddition, 27, 15, 1, false},
+		{RegroupSome, OpAddition, 27, 15, 2, true}, // even slots do
+		{RegroupSome, OpAddition, 23, 45, 2, false},
+		{"tens", OpAddition, 150, 70, 1, true},
+		{"tens", OpAddition, 95, 5, 1, false}, // the ones carry too
+		{"ones,tens", OpAddition, 95, 5, 1, true},
+		{RegroupNone, "multiplication", 27, 15, 1, true},
+	}
+	for _, tt := range tests {
+		if got := tt.rule.Allows(tt.op, tt.a, tt.b, tt.index); got != tt.want {
+			t.Errorf("%q.Allows(%d %s %d, slot %d) = %v, want %v", tt.rule, tt.a, OpSymbol(tt.op), tt.b, tt.index, got, tt.want)
+		}
+	}
+	for index, want := range map[int]Regrouping{1: RegroupNone, 2: RegroupAll, 3: RegroupNone, 4: RegroupAll} {
+		if got := Regrouping(RegroupSome).ForSlot(index); got != want {
+			t.Errorf("some.ForSlot(%d) = %q, want %q", index, got, want)
+		}
+	}
+	if got := Regrouping("tens").ForSlot(2); got != "tens" {
+		t.Errorf("tens.ForSlot(2) = %q, want tens", got)
+	}
+}
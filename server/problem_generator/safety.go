@@ -0,0 +1,330 @@
+package problemgenerator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// SafetyRule flags a scenario that is not right for a child's worksheet,
+// such as a scary or adult situation, by a pattern over the whole text. A
+// Soft rule is about taste rather than harm: the student's interests are
+// exempt from it.
+type SafetyRule struct {
+	Name    string `json:"name"`
+	Pattern string `json:"pattern"`
+	Soft    bool   `json:"soft,omitempty"`
+
+	re *regexp.Regexp
+}
+
+// SafetyConfig is the JSON form of a SafetyFilter. Block lists words that
+// are never allowed, Allow lists words and phrases that never count against
+// a text (so "fire truck" can pass a rule about fires), and Rules describe
+// scenarios to keep out. They screen English stories; Languages holds the
+// same lists for stories in other languages, by tag.
+type SafetyConfig struct {
+	Block []string     `json:"block"`
+	Allow []string     `json:"allow"`
+	Rules []SafetyRule `json:"rules"`
+
+	Languages map[string]SafetyConfig `json:"languages,omitempty"` // es | fr | vi
+}
+
+// DefaultSafetyConfig is what every worksheet is screened against.
+func DefaultSafetyConfig() SafetyConfig {
+	return SafetyConfig{
+		Block: []string{
+			"kill", "killed", "kills", "blood", "bloody", "gun", "guns", "knife", "knives", "weapon", "weapons",
+			"died", "dead", "death", "hate", "stupid", "dumb", "idiot", "ugly", "beer", "wine", "drunk",
+			"cigarette", "cigarettes", "vape", "bomb", "bombs", "drugs",
+		},
+		Allow: []string{"fire truck", "fire trucks", "firefighter", "firefighters", "fire station", "campfire", "campfires"},
+		Rules: []SafetyRule{
+			{Name: "violence", Pattern: `\b(fight|fights|fighting|punch|punched|stab|stabbed|attack|attacked|attacks|war|shooting)\b`},
+			{Name: "stranger danger", Pattern: `\bstrangers?\b`},
+			{Name: "being left alone", Pattern: `\b(home alone|all alone|left alone|abandoned|kidnapped|runaway)\b`},
+			{Name: "injury or illness", Pattern: `\b(hospital|ambulance|injured|bleeding|broke (his|her|their|a) (arm|leg)|cancer)\b`},
+			{Name: "scary", Pattern: `\b(scary|terrified|nightmare|nightmares|haunted|zombie|zombies|ghost|ghosts|monster|monsters|skeleton|skeletons)\b`, Soft: true},
+			{Name: "disaster", Pattern: `\b(on fire|caught fire|house fire|forest fire|wildfire|burned down|earthquake|flood|tornado|(car|plane|train) crash|crashed into|drowned|drowning)\b`},
+			{Name: "adult topics", Pattern: `\b(gamble|gambling|casino|lottery|betting|placed a bet|debt|dating|boyfriend|girlfriend|divorce)\b`},
+			{Name: "body image", Pattern: `\b(diet|dieting|fat|skinny|calories|weight loss)\b`},
+			{Name: "bullying", Pattern: `\b(bully|bullies|bullied|bullying|teased|nobody likes|made fun of)\b`},
+		},
+		Languages: map[string]SafetyConfig{
+			"es": {
+				Block: []string{
+					"matar", "mató", "matan", "sangre", "pistola", "pistolas", "cuchillo", "cuchillos", "un arma", "el arma",
+					"las armas", "unas armas", "sus armas", "armas de fuego", "morir", "murió", "muerto", "muerta", "muertos", "muerte", "odio", "odia", "estúpido", "estúpida",
+					"tonto", "tonta", "idiota", "feo", "fea", "cerveza", "borracho", "borracha", "cigarro", "cigarrillo",
+					"cigarrillos", "bomba", "bombas", "drogas",
+				},
+				Allow: []string{"camión de bomberos", "bombero", "bomberos", "fogata", "fogatas"},
+				Rules: []SafetyRule{
+					{Name: "violence", Pattern: anyWord("pelea", "peleas", "pelear", "peleó", "golpeó", "apuñaló", "atacó", "ataque", "guerra", "disparos")},
+					{Name: "stranger danger", Pattern: anyWord("desconocido", "desconocida", "desconocidos")},
+					{Name: "being left alone", Pattern: anyWord("solo en casa", "sola en casa", "abandonado", "abandonada", "secuestrado", "secuestrada")},
+					{Name: "injury or illness", Pattern: anyWord("hospital", "ambulancia", "herido", "herida", "sangrando", "cáncer")},
+					{Name: "scary", Pattern: anyWord("aterrador", "aterrorizado", "aterrorizada", "pesadilla", "pesadillas", "embrujada", "embrujado", "zombi", "zombis", "fantasma", "fantasmas", "monstruo", "monstruos", "esqueleto", "esqueletos"), Soft: true},
+					{Name: "disaster", Pattern: anyWord("incendio", "se quemó", "terremoto", "inundación", "tornado", "choque", "se ahogó", "ahogado", "ahogada")},
+					{Name: "adult topics", Pattern: anyWord("apostar", "apuesta", "apuestas", "casino", "lotería", "deuda", "novio", "novia", "divorcio")},
+					{Name: "body image", Pattern: anyWord("dieta", "gordo", "gorda", "flaco", "flaca", "calorías")},
+					{Name: "bullying", Pattern: anyWord("acoso", "acosador", "acosaron", "se burlaron", "nadie quiere")},
+				},
+			},
+			"fr": {
+				Block: []string{
+					"tuer", "tue", "tué", "tuée", "tués", "sang", "sanglant", "pistolet", "fusil", "couteau", "couteaux",
+					"arme", "armes", "mourir", "meurt", "mort", "morte", "morts", "haine", "déteste", "stupide", "idiot",
+					"idiote", "laid", "laide", "bière", "ivre", "cigarette", "cigarettes", "bombe", "bombes", "drogue", "drogues",
+				},
+				Allow: []string{"camion de pompiers", "pompier", "pompiers", "feu de camp", "feux de camp"},
+				Rules: []SafetyRule{
+					{Name: "violence", Pattern: anyWord("bagarre", "se battre", "se battent", "coup de poing", "poignardé", "attaque", "attaqué", "guerre", "fusillade")},
+					{Name: "stranger danger", Pattern: anyWord("un inconnu", "des inconnus", "un étranger", "des étrangers")},
+					{Name: "being left alone", Pattern: anyWord("seul à la maison", "seule à la maison", "abandonné", "abandonnée", "kidnappé", "kidnappée")},
+					{Name: "injury or illness", Pattern: anyWord("hôpital", "ambulance", "blessé", "blessée", "saigne", "cancer")},
+					{Name: "scary", Pattern: anyWord("effrayant", "effrayante", "terrifié", "terrifiée", "cauchemar", "cauchemars", "hanté", "hantée", "zombie", "zombies", "fantôme", "fantômes", "monstre", "monstres", "squelette", "squelettes"), Soft: true},
+					{Name: "disaster", Pattern: anyWord("incendie", "en feu", "tremblement de terre", "inondation", "tornade", "accident de voiture", "noyé", "noyée", "noyade")},
+					{Name: "adult topics", Pattern: anyWord("parier", "pari", "casino", "loterie", "dette", "petit ami", "petite amie", "divorce")},
+					{Name: "body image", Pattern: anyWord("régime", "maigre", "calories")},
+					{Name: "bullying", Pattern: anyWord("harcèlement", "harcelé", "harcelée", "se moquent", "se sont moqués", "personne ne l'aime")},
+				},
+			},
+			"vi": {
+				Block: []string{
+					"giết", "máu", "súng", "dao", "vũ khí", "chết", "bom", "ghét", "ngu", "ngốc", "xấu xí", "bia",
+					"rượu", "say rượu", "thuốc lá", "ma túy",
+				},
+				Allow: []string{"xe cứu hỏa", "lính cứu hỏa", "lửa trại"},
+				Rules: []SafetyRule{
+					{Name: "violence", Pattern: anyWord("đánh nhau", "đánh đập", "đấm", "đâm", "tấn công", "chiến tranh", "bắn súng", "bắn nhau")},
+					{Name: "stranger danger", Pattern: anyWord("người lạ")},
+					{Name: "being left alone", Pattern: anyWord("ở nhà một mình", "bỏ rơi", "bắt cóc")},
+					{Name: "injury or illness", Pattern: anyWord("bệnh viện", "xe cứu thương", "bị thương", "chảy máu", "ung thư")},
+					{Name: "scary", Pattern: anyWord("đáng sợ", "sợ hãi", "ác mộng", "ma", "hồn ma", "ma quỷ", "xác sống", "quái vật", "bộ xương"), Soft: true},
+					{Name: "disaster", Pattern: anyWord("cháy nhà", "hỏa hoạn", "động đất", "lũ lụt", "lốc xoáy", "tai nạn", "đuối nước", "chết đuối")},
+					{Name: "adult topics", Pattern: anyWord("cờ bạc", "đánh bạc", "sòng bạc", "xổ số", "cá cược", "nợ nần", "hẹn hò", "bạn trai", "bạn gái", "ly hôn")},
+					{Name: "body image", Pattern: anyWord("ăn kiêng", "béo phì", "mập", "calo")},
+					{Name: "bullying", Pattern: anyWord("bắt nạt", "trêu chọc", "chế giễu", "không ai thích")},
+				},
+			},
+		},
+	}
+}
+
+// LoadSafetyConfig reads a JSON SafetyConfig from path and adds it to the
+// defaults.
+func LoadSafetyConfig(path string) (SafetyConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return SafetyConfig{}, err
+	}
+	var extra SafetyConfig
+	if err := json.Unmarshal(data, &extra); err != nil {
+		return SafetyConfig{}, fmt.Errorf("%s: %v", path, err)
+	}
+	c := DefaultSafetyConfig()
+	c.Block = append(c.Block, extra.Block...)
+	c.Allow = append(c.Allow, extra.Allow...)
+	c.Rules = append(c.Rules, extra.Rules...)
+	for tag, more := range extra.Languages {
+		tag = NormalizeLanguage(tag)
+		if tag == "en" {
+			return SafetyConfig{}, fmt.Errorf("%s: English lists go at the top level, not under languages", path)
+		}
+		l := c.Languages[tag]
+		l.Block = append(l.Block, more.Block...)
+		l.Allow = append(l.Allow, more.Allow...)
+		l.Rules = append(l.Rules, more.Rules...)
+		c.Languages[tag] = l
+	}
+	return c, nil
+}
+
+// SafetyFilter screens generated text with word-boundary matching against a
+// blocklist and a set of scenario rules.
+type SafetyFilter struct {
+	block *regexp.Regexp
+	allow *regexp.Regexp
+	rules []SafetyRule
+	langs map[string]*SafetyFilter // other languages than English, by tag
+}
+
+// NewSafetyFilter compiles c.
+func NewSafetyFilter(c SafetyConfig) (*SafetyFilter, error) {
+	f := &SafetyFilter{block: wordsRegexp(c.Block), allow: wordsRegexp(c.Allow), langs: map[string]*SafetyFilter{}}
+	for _, r := range c.Rules {
+		re, err := regexp.Compile(`(?i)` + r.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("safety rule %q: %v", r.Name, err)
+		}
+		r.re = re
+		f.rules = append(f.rules, r)
+	}
+	for tag, l := range c.Languages {
+		lf, err := NewSafetyFilter(SafetyConfig{Block: l.Block, Allow: l.Allow, Rules: l.Rules})
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", tag, err)
+		}
+		f.langs[NormalizeLanguage(tag)] = lf
+	}
+	return f, nil
+}
+
+// For is the filter for stories in lang, or nil when f has no lists for
+// it.
+func (f *SafetyFilter) For(lang string) *SafetyFilter {
+	if tag := NormalizeLanguage(lang); tag != "en" {
+		return f.langs[tag]
+	}
+	return f
+}
+
+var defaultSafetyFilter = sync.OnceValue(func() *SafetyFilter {
+	f, err := NewSafetyFilter(DefaultSafetyConfig())
+	if err != nil {
+		panic(err)
+	}
+	return f
+})
+
+// DefaultSafetyFilter screens with DefaultSafetyConfig.
+func DefaultSafetyFilter() *SafetyFilter { return defaultSafetyFilter() }
+
+// anyWord is a pattern matching any of words as whole words. Unlike \b it
+// knows letters outside ASCII, so "đánh" or "arme" match only alone; the
+// word itself is its second group.
+func anyWord(words ...string) string {
+	var quoted []string
+	for _, w := range words {
+		if w = strings.TrimSpace(w); w != "" {
+			quoted = append(quoted, regexp.QuoteMeta(w))
+		}
+	}
+	return `(^|[^\p{L}])(` + strings.Join(quoted, "|") + `)($|[^\p{L}])`
+}
+
+// wordsRegexp matches any of words as whole words, or nothing when there are
+// none.
+func wordsRegexp(words []string) *regexp.Regexp {
+	if strings.TrimSpace(strings.Join(words, "")) == "" {
+		return nil
+	}
+	return regexp.MustCompile(`(?i)` + anyWord(words...))
+}
+
+// removeWords blanks out every word re, a wordsRegexp, matches. Matches
+// take the space around them, so it goes again until words next to each
+// other are all gone.
+func removeWords(re *regexp.Regexp, text string) string {
+	for {
+		out := re.ReplaceAllString(text, "${1} ${3}")
+		if out == text {
+			return text
+		}
+		text = out
+	}
+}
+
+// trimMatch drops what a match took around its words.
+func trimMatch(m string) string {
+	return strings.TrimFunc(m, func(r rune) bool { return !unicode.IsLetter(r) })
+}
+
+// Check returns why text is not safe for a worksheet, or "" when it is.
+// Words in interests, the student's own likes, never trip a soft rule, so a
+// child who loves monsters can get monster stories; blocked words and the
+// other rules count whatever the interests are.
+func (f *SafetyFilter) Check(text string, interests ...string) string {
+	if f.allow != nil {
+		text = removeWords(f.allow, text)
+	}
+	if f.block != nil {
+		if m := f.block.FindStringSubmatch(text); m != nil {
+			return fmt.Sprintf("uses the word %q", m[2])
+		}
+	}
+	soft := text
+	if re := wordsRegexp(interests); re != nil {
+		soft = removeWords(re, text)
+	}
+	for _, r := range f.rules {
+		t := text
+		if r.Soft {
+			t = soft
+		}
+		if m := r.re.FindString(t); m != "" {
+			return fmt.Sprintf("%s (%q)", r.Name, trimMatch(m))
+		}
+	}
+	return ""
+}
+
+// CheckProblem screens p for the student in meta, with the lists for the
+// set's language. A language f has no lists for passes; SafetyValidator
+// reports that the set went unscreened.
+func (f *SafetyFilter) CheckProblem(p Problem, meta GenerateRequest) string {
+	lf := f.For(meta.Language)
+	if lf == nil {
+		return ""
+	}
+	interests := append(append([]string{}, meta.LikesNouns...), meta.LikesVerbs...)
+	return lf.Check(p.Theme+"\n"+p.Text, interests...)
+}
+
+// ParseSafetyVerdict reads the local model's answer to a safety check,
+// which starts with SAFE or UNSAFE followed by the reason. Anything else
+// is taken as safe so that a rambling model cannot block a worksheet.
+func ParseSafetyVerdict(reply string) string {
+	reply = strings.TrimSpace(cleanCodeBlock(reply))
+	if len(reply) < 6 || !strings.EqualFold(reply[:6], "UNSAFE") {
+		return ""
+	}
+	if reason := strings.Trim(reply[6:], ":-. \n"); reason != "" {
+		return reason
+	}
+	return "flagged by the safety check"
+}
+
+// ---------- rejection log ----------
+
+// Rejection is one problem kept off a worksheet, recorded for later review.
+type Rejection struct {
+	Time    time.Time `json:"time"`
+	Student string    `json:"student"`
+	Grade   string    `json:"grade"`
+	Index   int       `json:"index"`
+	Text    string    `json:"text"`
+	Source  string    `json:"source"` // filter | classifier
+	Reason  string    `json:"reason"`
+}
+
+// RejectionLog writes rejections as JSON lines.
+type RejectionLog struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewRejectionLog(w io.Writer) *RejectionLog { return &RejectionLog{w: w} }
+
+// Log records r. A nil RejectionLog drops it.
+func (l *RejectionLog) Log(r Rejection) error {
+	if l == nil {
+		return nil
+	}
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, err = l.w.Write(append(data, '\n'))
+	return err
+}
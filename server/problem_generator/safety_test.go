@@ -0,0 +1,78 @@
+package problemgenerator
+
+import "testing"
+
+func TestSafetyCheck(t *testing.T) {
+	tests := []struct {
+		text      string
+		interests []string
+		unsafe    bool
+	}{
+		{text: "The dragon breathes fire on 3 candles. How many candles are lit?"},
+		{text: "Mia sits by the campfire with 4 friends. How many are there altogether?"},
+		{text: "Waves crash on the beach 5 times. How many more crash to make 9?"},
+		{text: "I bet Leo can find 6 shells. How many shells does he find?"},
+		{text: "Leo rolls a die 4 times. How many more rolls make 10?"},
+		{text: "The plant died after 3 days.", unsafe: true},
+		{text: "The fire truck has 4 ladders. How many ladders are left?"},
+		{text: "Their house caught fire and 3 rooms burned.", unsafe: true},
+		{text: "The car crash sent 2 people away.", unsafe: true},
+		{text: "Sam sees 3 ghosts. How many ghosts are left?", interests: []string{"ghosts"}},
+		{text: "Sam sees 3 ghosts. How many ghosts are left?", unsafe: true},
+		{text: "Ana and 4 friends went to war.", interests: []string{"war"}, unsafe: true},
+		{text: "Leo loves fighting and punched 3 kids.", interests: []string{"fighting"}, unsafe: true},
+		{text: "Mom went to the casino with $5.", interests: []string{"casino"}, unsafe: true},
+		{text: "The knife cut 4 apples.", interests: []string{"knife"}, unsafe: true},
+	}
+	f := DefaultSafetyFilter()
+	for _, tt := range tests {
+		if got := f.Check(tt.text, tt.interests...); (got != "") != tt.unsafe {
+			t.Errorf("Check(%q, %q) = %q, want unsafe %v", tt.text, tt.interests, got, tt.unsafe)
+		}
+	}
+}
+
+func TestSafetyCheckLanguages(t *testing.T) {
+	tests := []struct {
+		lang, text string
+		interests  []string
+		unsafe     bool
+	}{
+		{lang: "es", text: "Ana tiene 3 manzanas y come 1. ¿Cuántas manzanas le quedan?"},
+		{lang: "es", text: "Había sangre en 2 camisas.", unsafe: true},
+		{lang: "es", text: "Leo quiere matar 4 moscas.", unsafe: true},
+		{lang: "es", text: "Los bomberos tienen 5 mangueras."},
+		{lang: "es", text: "Leo arma 3 torres de bloques. ¿Cuántas torres arma?"},
+		{lang: "es", text: "El pirata esconde un arma en 2 cofres.", unsafe: true},
+		{lang: "es", text: "Los piratas guardan sus armas en 4 barcos.", unsafe: true},
+		{lang: "fr", text: "Léa a 4 pommes. Combien de pommes a-t-elle ?"},
+		{lang: "fr", text: "Le pirate range son arme dans 2 coffres.", unsafe: true},
+		{lang: "fr", text: "Léa voit 3 fantômes.", unsafe: true},
+		{lang: "fr", text: "Léa voit 3 fantômes.", interests: []string{"fantômes"}},
+		{lang: "fr", text: "L'armée défile."},
+		{lang: "vi", text: "Lan có 5 quả táo và ăn 2 quả. Lan còn lại mấy quả táo?"},
+		{lang: "vi", text: "Lan thấy 3 con ma trong nhà.", unsafe: true},
+		{lang: "vi", text: "Nam ngủ 8 giờ mỗi đêm."},
+		{lang: "vi", text: "Hai bạn đánh nhau 3 lần.", unsafe: true},
+	}
+	f := DefaultSafetyFilter()
+	for _, tt := range tests {
+		p := Problem{Text: tt.text}
+		meta := GenerateRequest{Language: tt.lang, LikesNouns: tt.interests}
+		if got := f.CheckProblem(p, meta); (got != "") != tt.unsafe {
+			t.Errorf("%s: CheckProblem(%q) = %q, want unsafe %v", tt.lang, tt.text, got, tt.unsafe)
+		}
+	}
+}
+
+func TestSafetyValidatorWithoutLists(t *testing.T) {
+	f, err := NewSafetyFilter(SafetyConfig{Block: []string{"gun"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ps := &ProblemSet{MetaInfo: GenerateRequest{Language: "fr"}, Problems: []Problem{{Index: 1, Text: "Il a une arme."}}}
+	issues := SafetyValidator{Filter: f}.Validate(ps)
+	if len(issues) != 1 || issues[0].Index != 0 || issues[0].Severity != SeverityWarning {
+		t.Errorf("issues = %v, want one warning that the set was not screened", issues)
+	}
+}
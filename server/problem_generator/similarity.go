@@ -0,0 +1,288 @@
+package problemgenerator
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Near-duplicate thresholds. Two stories whose text shingles overlap by
+// TextSimilarity, or whose sentence shape (the text with its nouns, names
+// and numbers blanked out) overlaps by ShapeSimilarity, count as the same
+// problem told twice.
+const (
+	TextSimilarity  = 0.6
+	ShapeSimilarity = 0.85
+)
+
+// Duplicate is a problem that repeats another one. Of is the index of the
+// earlier problem in the set, or 0 when it repeats a recent worksheet.
+type Duplicate struct {
+	Index  int
+	Of     int
+	Reason string
+}
+
+func (d Duplicate) String() string {
+	if d.Of == 0 {
+		return fmt.Sprintf("problem %d %s a recent worksheet", d.Index, d.Reason)
+	}
+	return fmt.Sprintf("problem %d %s problem %d", d.Index, d.Reason, d.Of)
+}
+
+var reNonWord = regexp.MustCompile(`[^\p{L}\p{N}?]+`)
+
+// shingles are the overlapping three-word runs of a text, used to compare
+// wording without caring about small edits.
+type shingles map[string]bool
+
+func shinglesOf(words []string) shingles {
+	s := shingles{}
+	if len(words) < 3 {
+		s[strings.Join(words, " ")] = true
+		return s
+	}
+	for i := 0; i+3 <= len(words); i++ {
+		s[strings.Join(words[i:i+3], " ")] = true
+	}
+	return s
+}
+
+func jaccard(a, b shingles) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	both := 0
+	for k := range a {
+		if b[k] {
+			both++
+		}
+	}
+	return float64(both) / float64(len(a)+len(b)-both)
+}
+
+// textWords lowercases text into words with every number written as "#".
+func textWords(text string) []string {
+	text = reInts.ReplaceAllString(strings.ToLower(text), " # ")
+	return strings.Fields(reNonWord.ReplaceAllString(text, " "))
+}
+
+// shapeWords are the words that give a sentence its shape: the Dolch
+// sight words apart from the nouns, and the math words.
+var shapeWords = wordSet(dolchWords, mathWords)
+
+// shapeOf is text with only its shape words left: names, nouns and other
+// content words become "_", so "Sam has 3 dinosaurs" and "Sam has 5 rockets"
+// have the same shape.
+func shapeOf(text string) []string {
+	var shape []string
+	for _, w := range textWords(text) {
+		if w != "#" && !shapeWords[strings.TrimSuffix(w, "?")] && !shapeWords[strings.TrimSuffix(w, "s")] {
+			w = "_"
+		}
+		if w == "_" && len(shape) > 0 && shape[len(shape)-1] == "_" {
+			continue
+		}
+		shape = append(shape, w)
+	}
+	return shape
+}
+
+// sameNumbers reports whether two arithmetic stories do the same sum.
+func sameNumbers(a, b Problem) bool {
+	if !isArithmeticStory(a) || !isArithmeticStory(b) || NormalizeOperation(a.Operation) != NormalizeOperation(b.Operation) {
+		return false
+	}
+	x, y := slices.Clone(a.Numbers), slices.Clone(b.Numbers)
+	slices.Sort(x)
+	slices.Sort(y)
+	return len(x) > 0 && slices.Equal(x, y)
+}
+
+// duplicateKey is what two problems share when one repeats the other word
+// for word. Patterns and charts share their wording, so what they show
+// counts too.
+func duplicateKey(p Problem) string {
+	return strings.Join([]string{
+		reNonWord.ReplaceAllString(strings.ToLower(p.Text), " "),
+		p.Equation, strings.Join(p.Sequence, ","), p.Chart.String(),
+	}, "|")
+}
+
+// repeats says how p repeats q, or "" when it does not. Only model-written
+// stories are compared by wording; server-built kinds share their phrasing
+// by design.
+func repeats(p, q Problem) string {
+	switch {
+	case duplicateKey(p) == duplicateKey(q):
+		return "repeats"
+	case !isStory(p) || !isStory(q):
+		return ""
+	case jaccard(shinglesOf(textWords(p.Text)), shinglesOf(textWords(q.Text))) >= TextSimilarity:
+		return "reads almost the same as"
+	case jaccard(shinglesOf(shapeOf(p.Text)), shinglesOf(shapeOf(q.Text))) >= ShapeSimilarity:
+		return "has the same sentences as"
+	case sameNumbers(p, q):
+		return "uses the same numbers as"
+	}
+	return ""
+}
+
+// Similarity scores how alike two problems are, from 0 (nothing shared) to
+// 1 (the same problem).
+func Similarity(p, q Problem) float64 {
+	if duplicateKey(p) == duplicateKey(q) {
+		return 1
+	}
+	s := jaccard(shinglesOf(textWords(p.Text)), shinglesOf(textWords(q.Text)))
+	if isStory(p) && isStory(q) {
+		s = max(s, jaccard(shinglesOf(shapeOf(p.Text)), shinglesOf(shapeOf(q.Text))))
+	}
+	if sameNumbers(p, q) {
+		s = max(s, 0.5)
+	}
+	return s
+}
+
+// Diversity scores how different the problems of ps are from each other:
+// one minus their average Similarity, so 1 means no two are alike.
+func Diversity(ps *ProblemSet) float64 {
+	total, pairs := 0.0, 0
+	for i, p := range ps.Problems {
+		for _, q := range ps.Problems[i+1:] {
+			total += Similarity(p, q)
+			pairs++
+		}
+	}
+	if pairs == 0 {
+		return 1
+	}
+	return 1 - total/float64(pairs)
+}
+
+// FindDuplicates lists the problems of ps that repeat an earlier problem in
+// the set or one of recent.
+func FindDuplicates(ps *ProblemSet, recent []Problem) []Duplicate {
+	var dups []Duplicate
+	for i, p := range ps.Problems {
+		if d, ok := findDuplicate(p, ps.Problems[:i], recent); ok {
+			dups = append(dups, d)
+		}
+	}
+	return dups
+}
+
+func findDuplicate(p Problem, earlier, recent []Problem) (Duplicate, bool) {
+	for _, q := range earlier {
+		if reason := repeats(p, q); reason != "" {
+			return Duplicate{Index: p.Index, Of: q.Index, Reason: reason}, true
+		}
+	}
+	for _, q := range recent {
+		if reason := repeats(p, q); reason != "" {
+			return Duplicate{Index: p.Index, Reason: reason}, true
+		}
+	}
+	return Duplicate{}, false
+}
+
+// ReplaceDuplicates swaps every duplicate in ps for a problem from fresh
+// that repeats nothing, keeping its place and index. It returns the
+// duplicates that are left.
+func ReplaceDuplicates(ps, fresh *ProblemSet, recent []Problem) []Duplicate {
+	used := make([]bool, len(fresh.Problems))
+	for _, d := range FindDuplicates(ps, recent) {
+		i := slices.IndexFunc(ps.Problems, func(p Problem) bool { return p.Index == d.Index })
+		others := slices.Delete(slices.Clone(ps.Problems), i, i+1)
+		for j, c := range fresh.Problems {
+			if used[j] {
+				continue
+			}
+			c.Index = d.Index
+			if !fitsSlot(ps.MetaInfo, d.Index, c) {
+				continue
+			}
+			if _, dup := findDuplicate(c, others, recent); !dup {
+				ps.Problems[i], used[j] = c, true
+				break
+			}
+		}
+	}
+	return FindDuplicates(ps, recent)
+}
+
+// ReplaceProblems swaps the problems of ps with the given indexes for
+// problems from fresh that ok accepts, keeping their places and indexes. It
+// returns the indexes it could not replace.
+func ReplaceProblems(ps, fresh *ProblemSet, indexes []int, ok func(Problem) bool) []int {
+	var left []int
+	used := make([]bool, len(fresh.Problems))
+	for _, index := range indexes {
+		i := slices.IndexFunc(ps.Problems, func(p Problem) bool { return p.Index == index })
+		if i < 0 {
+			continue
+		}
+		replaced := false
+		for j, c := range fresh.Problems {
+			if used[j] || !fitsSlot(ps.MetaInfo, index, c) {
+				continue
+			}
+			c.Index = index
+			used[j] = true // ok may log a rejection; ask once per problem
+			if ok(c) {
+				ps.Problems[i], replaced = c, true
+				break
+			}
+		}
+		if !replaced {
+			left = append(left, index)
+		}
+	}
+	return left
+}
+
+// ---------- history ----------
+
+// History remembers the last problems each student was given, so a new
+// worksheet does not repeat last week's. It is kept in memory only.
+type History struct {
+	mu      sync.Mutex
+	size    int
+	student map[string][]Problem
+}
+
+// NewHistory keeps up to size problems per student.
+func NewHistory(size int) *History {
+	return &History{size: size, student: map[string][]Problem{}}
+}
+
+func historyKey(name string) string { return strings.ToLower(strings.TrimSpace(name)) }
+
+// Recent returns the problems name was given most recently. A nil History
+// remembers nothing.
+func (h *History) Recent(name string) []Problem {
+	if h == nil {
+		return nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return slices.Clone(h.student[historyKey(name)])
+}
+
+// Add remembers the problems of ps for its student, dropping the oldest ones
+// past the History's size.
+func (h *History) Add(ps *ProblemSet) {
+	if h == nil || h.size <= 0 {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	key := historyKey(ps.MetaInfo.Name)
+	recent := append(h.student[key], ps.Problems...)
+	if len(recent) > h.size {
+		recent = recent[len(recent)-h.size:]
+	}
+	h.student[key] = slices.Clip(recent)
+}
@@ -0,0 +1,80 @@
+package problemgenerator
+
+import "testing"
+
+func TestFindDuplicate(t *testing.T) {
+	story := func(index int, text string, nums ...int) Problem {
+		return Problem{Index: index, Kind: KindArithmetic, Operation: OpAddition, Text: text, Numbers: nums}
+	}
+	first := story(1, "Sam has 3 dinosaurs. He finds 4 more dinosaurs. How many dinosaurs does Sam have altogether?", 3, 4)
+	tests := []struct {
+		name   string
+		p      Problem
+		recent []Problem
+		of     int
+		reason string
+	}{
+		{name: "word for word", p: story(2, "Sam has 3 dinosaurs! He finds 4 more dinosaurs. How many dinosaurs does Sam have altogether?", 3, 4), of: 1, reason: "repeats"},
+		{name: "small edits", p: story(2, "Sam has 5 dinosaurs. He finds 2 more dinosaurs. How many dinosaurs does Sam have now?", 5, 2), of: 1, reason: "reads almost the same as"},
+		{name: "same shape", p: story(2, "Max has 6 rockets. He finds 1 more rocket. How many rockets does Max have altogether?", 6, 1), of: 1, reason: "has the same sentences as"},
+		{name: "same numbers", p: story(2, "There are 4 cats on a wall and 3 cats jump up to join them. Count the cats.", 4, 3), of: 1, reason: "uses the same numbers as"},
+		{name: "different", p: story(2, "A baker puts 9 cookies on a tray and 8 on a plate. Count the cookies.", 9, 8)},
+		{
+			name:   "recent worksheet",
+			p:      story(2, "Leo bakes 6 pies and 5 cakes for a party. How many treats is that?", 6, 5),
+			recent: []Problem{story(7, "Leo bakes 6 pies and 5 cakes for a party. How many treats is that?", 6, 5)},
+			reason: "repeats",
+		},
+		{
+			name: "server-built kinds share their wording",
+			p:    Problem{Index: 2, Kind: KindPattern, Text: "Sam lines up dinosaurs in a pattern. What comes next?", Sequence: []string{"1", "2"}},
+			recent: []Problem{
+				{Index: 1, Kind: KindPattern, Text: "Sam lines up dinosaurs in a pattern. What comes next?", Sequence: []string{"1", "3"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		d, ok := findDuplicate(tt.p, []Problem{first}, tt.recent)
+		if ok != (tt.reason != "") || d.Of != tt.of || d.Reason != tt.reason {
+			t.Errorf("%s: findDuplicate = %v, %v; want of %d, reason %q", tt.name, d, ok, tt.of, tt.reason)
+		}
+	}
+}
+
+func TestDiversity(t *testing.T) {
+	same := Problem{Index: 1, Kind: KindArithmetic, Operation: OpAddition, Text: "Ana has 2 cats and 3 dogs. How many pets?", Numbers: []int{2, 3}}
+	other := Problem{Index: 2, Kind: KindArithmetic, Operation: OpSubtraction, Text: "A bus carries 20 kids; 8 get off at the park. Who is still riding?", Numbers: []int{20, 8}}
+	tests := []struct {
+		name     string
+		problems []Problem
+		lo, hi   float64
+	}{
+		{"empty", nil, 1, 1},
+		{"one problem", []Problem{same}, 1, 1},
+		{"all the same", []Problem{same, same}, 0, 0},
+		{"all different", []Problem{same, other}, 0.9, 1},
+	}
+	for _, tt := range tests {
+		if got := Diversity(&ProblemSet{Problems: tt.problems}); got < tt.lo || got > tt.hi {
+			t.Errorf("%s: Diversity = %.2f, want %.2f to %.2f", tt.name, got, tt.lo, tt.hi)
+		}
+	}
+}
+
+func TestHistory(t *testing.T) {
+	h := NewHistory(3)
+	h.Add(&ProblemSet{MetaInfo: GenerateRequest{Name: "Ana"}, Problems: []Problem{{Index: 1}, {Index: 2}}})
+	h.Add(&ProblemSet{MetaInfo: GenerateRequest{Name: " ana "}, Problems: []Problem{{Index: 3}, {Index: 4}}})
+	recent := h.Recent("ANA")
+	if len(recent) != 3 || recent[0].Index != 2 || recent[2].Index != 4 {
+		t.Errorf("Recent = %+v, want problems 2 to 4", recent)
+	}
+	if got := h.Recent("Leo"); len(got) != 0 {
+		t.Errorf("Recent(Leo) = %+v, want nothing", got)
+	}
+	var none *History
+	none.Add(&ProblemSet{Problems: []Problem{{Index: 1}}})
+	if got := none.Recent("Ana"); got != nil {
+		t.Errorf("nil History remembered %+v", got)
+	}
+}
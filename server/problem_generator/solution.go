@@ -0,0 +1,406 @@
+package problemgenerator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Solution is how to work a problem out, for the answer key: the equation
+// it comes down to and the steps to its answer, worked from its numbers.
+type Solution struct {
+	Equation    string   `json:"equation"` // "47 + 25 = 72"
+	Steps       []string `json:"steps"`
+	Explanation string   `json:"explanation,omitempty"` // the model's words for a child, see AcceptExplanation
+}
+
+// solutionWords are the phrases steps are written with. Numbers stay
+// digits in every language, as in the equations.
+type solutionWords struct {
+	Places     []string // ones, tens, hundreds, …
+	Column     string   // place, sum, result
+	Carry      string   // digit written, place the 1 goes to
+	Borrow     string   // place, digit, digit taken, place borrowed from, digit + 10, result
+	BorrowZero string   // place, place borrowed from, digit taken, result
+	CountOn    string   // start, count, result
+	CountBack  string   // start, count, result
+	Groups     string   // a, b, a, b, sum, result
+	Table      string   // equation
+	Split      string   // number, parts
+	AddUp      string   // sum of the parts
+	Share      string   // divisor, dividend
+	Left       string   // equation, remainder
+	Back       string   // equation
+	Check      string   // equation
+	So         string   // equation
+	Diff       string   // equation
+	Compare    string   // comparison
+	Answer     string   // answer
+}
+
+var solutionPhrases = map[string]solutionWords{
+	"en": {
+		Places:     placeNames,
+		Column:     "%s: %s = %d.",
+		Carry:      "Write %d and carry 1 to the %s.",
+		Borrow:     "%[1]s: %[2]d is less than %[3]d, so borrow 1 from the %[4]s: %[5]d − %[3]d = %[6]d.",
+		BorrowZero: "%[1]s: this 0 had to borrow 1 from the %[2]s first, so it is now 9: 9 − %[3]d = %[4]d.",
+		CountOn:    "Start at %d and count on %d more: %d.",
+		CountBack:  "Start at %d and count back %d: %d.",
+		Groups:     "%d × %d means %d groups of %d: %s = %d.",
+		Table:      "Use the times table: %s.",
+		Split:      "Split %d into %s.",
+		AddUp:      "Add the parts: %s.",
+		Share:      "How many groups of %d make %d?",
+		Left:       "%s, with %d left over.",
+		Back:       "Work backwards to find the missing number: %s.",
+		Check:      "Check: %s.",
+		So:         "So %s.",
+		Diff:       "Take the smaller amount from the bigger one: %s.",
+		Compare:    "Compare the amounts: %s.",
+		Answer:     "So the answer is %s.",
+	},
+	"es": {
+		Places:     []string{"unidades", "decenas", "centenas", "unidades de millar", "decenas de millar", "centenas de millar"},
+		Column:     "%s: %s = %d.",
+		Carry:      "Escribe %d y lleva 1 a las %s.",
+		Borrow:     "%[1]s: %[2]d es menor que %[3]d, así que pide 1 prestado a las %[4]s: %[5]d − %[3]d = %[6]d.",
+		BorrowZero: "%[1]s: este 0 primero pidió 1 prestado a las %[2]s, así que ahora es 9: 9 − %[3]d = %[4]d.",
+		CountOn:    "Empieza en %d y cuenta %d más: %d.",
+		CountBack:  "Empieza en %d y cuenta %d hacia atrás: %d.",
+		Groups:     "%d × %d son %d grupos de %d: %s = %d.",
+		Table:      "Usa la tabla de multiplicar: %s.",
+		Split:      "Separa %d en %s.",
+		AddUp:      "Suma las partes: %s.",
+		Share:      "¿Cuántos grupos de %d forman %d?",
+		Left:       "%s, y sobran %d.",
+		Back:       "Haz la operación contraria para hallar el número que falta: %s.",
+		Check:      "Comprueba: %s.",
+		So:         "Entonces %s.",
+		Diff:       "Resta la cantidad menor de la mayor: %s.",
+		Compare:    "Compara las cantidades: %s.",
+		Answer:     "Entonces la respuesta es %s.",
+	},
+	"fr": {
+		Places:     []string{"unités", "dizaines", "centaines", "milliers", "dizaines de mille", "centaines de mille"},
+		Column:     "%s : %s = %d.",
+		Carry:      "On écrit %d et on retient 1 pour les %s.",
+		Borrow:     "%[1]s : %[2]d est plus petit que %[3]d, donc on emprunte 1 aux %[4]s : %[5]d − %[3]d = %[6]d.",
+		BorrowZero: "%[1]s : ce 0 a d'abord emprunté 1 aux %[2]s, il vaut donc 9 : 9 − %[3]d = %[4]d.",
+		CountOn:    "Pars de %d et compte %d de plus : %d.",
+		CountBack:  "Pars de %d et recule de %d : %d.",
+		Groups:     "%d × %d, c'est %d groupes de %d : %s = %d.",
+		Table:      "Utilise la table de multiplication : %s.",
+		Split:      "Décompose %d en %s.",
+		AddUp:      "Additionne les parties : %s.",
+		Share:      "Combien de groupes de %d font %d ?",
+		Left:       "%s, et il reste %d.",
+		Back:       "Fais l'opération inverse pour trouver le nombre manquant : %s.",
+		Check:      "Vérifie : %s.",
+		So:         "Donc %s.",
+		Diff:       "Enlève la plus petite quantité de la plus grande : %s.",
+		Compare:    "Compare les quantités : %s.",
+		Answer:     "Donc la réponse est %s.",
+	},
+	"vi": {
+		Places:     []string{"hàng đơn vị", "hàng chục", "hàng trăm", "hàng nghìn", "hàng chục nghìn", "hàng trăm nghìn"},
+		Column:     "%s: %s = %d.",
+		Carry:      "Viết %d, nhớ 1 sang %s.",
+		Borrow:     "%[1]s: %[2]d bé hơn %[3]d nên mượn 1 từ %[4]s: %[5]d − %[3]d = %[6]d.",
+		BorrowZero: "%[1]s: số 0 này đã mượn 1 từ %[2]s nên giờ là 9: 9 − %[3]d = %[4]d.",
+		CountOn:    "Bắt đầu từ %d, đếm thêm %d: %d.",
+		CountBack:  "Bắt đầu từ %d, đếm lùi %d: %d.",
+		Groups:     "%d × %d là %d nhóm, mỗi nhóm %d: %s = %d.",
+		Table:      "Dùng bảng cửu chương: %s.",
+		Split:      "Tách %d thành %s.",
+		AddUp:      "Cộng các phần lại: %s.",
+		Share:      "Bao nhiêu nhóm %d thì được %d?",
+		Left:       "%s, còn dư %d.",
+		Back:       "Làm phép tính ngược lại để tìm số còn thiếu: %s.",
+		Check:      "Thử lại: %s.",
+		So:         "Vậy %s.",
+		Diff:       "Lấy số lớn trừ số bé: %s.",
+		Compare:    "So sánh hai số: %s.",
+		Answer:     "Vậy đáp án là %s.",
+	},
+}
+
+// Solve works out the Solution of every problem of ps it can explain:
+// arithmetic, missing-number, equation and comparison problems. Other
+// problems, and any explanation from before, are left without one.
+func Solve(ps *ProblemSet) {
+	w := solutionPhrases[NormalizeLanguage(ps.MetaInfo.Language)]
+	for i, p := range ps.Problems {
+		ps.Problems[i].Solution = solve(p, w)
+	}
+}
+
+func solve(p Problem, w solutionWords) *Solution {
+	op := NormalizeOperation(p.Operation)
+	switch NormalizeKind(p.Kind) {
+	case KindArithmetic:
+		if len(p.Numbers) >= 2 {
+			return worked(op, p.Numbers[0], p.Numbers[1], w)
+		}
+	case KindMissing, KindEquation:
+		if len(p.Numbers) >= 3 {
+			return backwards(op, p.Unknown, p.Numbers[0], p.Numbers[1], p.Numbers[2], w)
+		}
+	case KindComparison:
+		if len(p.Numbers) >= 2 {
+			return compared(p, w)
+		}
+	}
+	return nil
+}
+
+// worked solves a op b the way it is taught: counting on or back for
+// small numbers, in columns for bigger ones, and by splitting a factor
+// into its places for long multiplication.
+func worked(op string, a, b int, w solutionWords) *Solution {
+	steps, result, ok := workSteps(op, a, b, w)
+	if !ok {
+		return nil
+	}
+	eq := equation(op, a, b, result)
+	return &Solution{Equation: eq, Steps: append(steps, fmt.Sprintf(w.So, eq))}
+}
+
+// workSteps are the steps of a op b before its conclusion, and the result.
+func workSteps(op string, a, b int, w solutionWords) (steps []string, result int, ok bool) {
+	if a < 0 || b < 0 || max(a, b) >= pow10(len(w.Places)-1) {
+		return nil, 0, false
+	}
+	switch op {
+	case OpAddition:
+		return addSteps(a, b, w), a + b, true
+	case OpSubtraction:
+		if a < b {
+			return nil, 0, false
+		}
+		return subSteps(a, b, w), a - b, true
+	case "multiplication":
+		return mulSteps(a, b, w), a * b, true
+	case "division":
+		if b == 0 {
+			return nil, 0, false
+		}
+		q, r := a/b, a%b
+		fact := equation("multiplication", b, q, b*q)
+		if r > 0 {
+			fact = fmt.Sprintf(w.Left, fact, r)
+		} else {
+			fact += "."
+		}
+		return []string{fmt.Sprintf(w.Share, b, a), fact}, q, true
+	}
+	return nil, 0, false
+}
+
+func addSteps(a, b int, w solutionWords) []string {
+	if a+b <= 20 {
+		return []string{fmt.Sprintf(w.CountOn, max(a, b), min(a, b), a+b)}
+	}
+	var steps []string
+	carry := 0
+	for place := 0; pow10(place) <= max(a, b); place++ {
+		da, db := Digit(a, place), Digit(b, place)
+		expr := fmt.Sprintf("%d + %d", da, db)
+		if carry > 0 {
+			expr += " + 1"
+		}
+		sum := da + db + carry
+		step := fmt.Sprintf(w.Column, upperFirst(w.Places[place]), expr, sum)
+		if carry = sum / 10; carry > 0 {
+			step += " " + fmt.Sprintf(w.Carry, sum%10, w.Places[place+1])
+		}
+		steps = append(steps, step)
+	}
+	return steps
+}
+
+func subSteps(a, b int, w solutionWords) []string {
+	if a <= 20 && b < 10 {
+		return []string{fmt.Sprintf(w.CountBack, a, b, a-b)}
+	}
+	var steps []string
+	borrowed := false // the place to the right borrowed from this one
+	for place := 0; pow10(place) <= a; place++ {
+		d, db := Digit(a, place), Digit(b, place)
+		name := upperFirst(w.Places[place])
+		if borrowed && d == 0 { // it had nothing to lend, so it borrowed in turn
+			steps = append(steps, fmt.Sprintf(w.BorrowZero, name, w.Places[place+1], db, 9-db))
+			continue
+		}
+		top, expr := d, fmt.Sprintf("%d − %d", d, db)
+		if borrowed {
+			top, expr = d-1, fmt.Sprintf("%d − 1 − %d", d, db)
+		}
+		if borrowed = top < db; borrowed {
+			steps = append(steps, fmt.Sprintf(w.Borrow, name, top, db, w.Places[place+1], top+10, top+10-db))
+			continue
+		}
+		if pow10(place+1) > a && top == 0 {
+			continue // the leading place lent all it had
+		}
+		steps = append(steps, fmt.Sprintf(w.Column, name, expr, top-db))
+	}
+	return steps
+}
+
+func mulSteps(a, b int, w solutionWords) []string {
+	eq := equation("multiplication", a, b, a*b)
+	if a <= 10 && b <= 10 {
+		if a < 2 || a > 5 {
+			return []string{fmt.Sprintf(w.Table, eq)}
+		}
+		sum := strings.TrimSuffix(strings.Repeat(strconv.Itoa(b)+" + ", a), " + ")
+		return []string{fmt.Sprintf(w.Groups, a, b, a, b, sum, a*b)}
+	}
+	// Split the bigger factor into its places and multiply each part.
+	big, small := a, b
+	if b > a {
+		big, small = b, a
+	}
+	var parts []int
+	for place := 0; pow10(place) <= big; place++ {
+		if d := Digit(big, place); d > 0 {
+			parts = append([]int{d * pow10(place)}, parts...)
+		}
+	}
+	if len(parts) < 2 {
+		return nil
+	}
+	steps := []string{fmt.Sprintf(w.Split, big, joinWith(parts, " + "))}
+	var products []int
+	for _, part := range parts {
+		x, y := part, small
+		if big == b && b != a {
+			x, y = small, part
+		}
+		products = append(products, x*y)
+		steps = append(steps, equation("multiplication", x, y, x*y)+".")
+	}
+	return append(steps, fmt.Sprintf(w.AddUp, joinWith(products, " + ")+" = "+strconv.Itoa(a*b)))
+}
+
+// backwards solves a missing-number problem by undoing its operation, then
+// checks the answer in the original equation.
+func backwards(op, unknown string, a, b, result int, w solutionWords) *Solution {
+	eq := equation(op, a, b, result)
+	var inverse string
+	switch slot := normalizeSlot(unknown); {
+	case slot == SlotResult:
+		steps, _, ok := workSteps(op, a, b, w)
+		if !ok {
+			return nil
+		}
+		return &Solution{Equation: eq, Steps: append(steps, fmt.Sprintf(w.So, eq))}
+	case slot == SlotA && op == OpAddition:
+		inverse = equation(OpSubtraction, result, b, a)
+	case slot == SlotA && op == OpSubtraction:
+		inverse = equation(OpAddition, result, b, a)
+	case slot == SlotA && op == "multiplication":
+		inverse = equation("division", result, b, a)
+	case slot == SlotA && op == "division":
+		inverse = equation("multiplication", result, b, a)
+	case slot == SlotB && op == OpAddition:
+		inverse = equation(OpSubtraction, result, a, b)
+	case slot == SlotB && op == OpSubtraction:
+		inverse = equation(OpSubtraction, a, result, b)
+	case slot == SlotB && op == "multiplication":
+		inverse = equation("division", result, a, b)
+	case slot == SlotB && op == "division":
+		inverse = equation("division", a, result, b)
+	default:
+		return nil
+	}
+	return &Solution{Equation: eq, Steps: []string{fmt.Sprintf(w.Back, inverse), fmt.Sprintf(w.Check, eq)}}
+}
+
+func compared(p Problem, w solutionWords) *Solution {
+	a, b := p.Numbers[0], p.Numbers[1]
+	symbol := "="
+	if a < b {
+		symbol = "<"
+	} else if a > b {
+		symbol = ">"
+	}
+	comparison := fmt.Sprintf("%d %s %d", a, symbol, b)
+	switch normalizeComparisonOp(p.Operation) {
+	case OpCompare:
+		return &Solution{Equation: comparison, Steps: []string{fmt.Sprintf(w.Compare, comparison)}}
+	case OpHowManyMore:
+		eq := equation(OpSubtraction, max(a, b), min(a, b), max(a, b)-min(a, b))
+		return &Solution{Equation: eq, Steps: []string{fmt.Sprintf(w.Diff, eq)}}
+	case OpWhoHasMore, OpWhoHasFewer:
+		return &Solution{Equation: comparison, Steps: []string{fmt.Sprintf(w.Compare, comparison), fmt.Sprintf(w.Answer, p.Answer)}}
+	}
+	return nil
+}
+
+// equation writes "a op b = result".
+func equation(op string, a, b, result int) string {
+	return fmt.Sprintf("%d %s %d = %d", a, OpSymbol(op), b, result)
+}
+
+func joinWith(nums []int, sep string) string {
+	s := make([]string, len(nums))
+	for i, n := range nums {
+		s[i] = strconv.Itoa(n)
+	}
+	return strings.Join(s, sep)
+}
+
+func upperFirst(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToUpper(r)) + s[size:]
+}
+
+// AcceptExplanation checks the model's explanation of how to solve p, which
+// must already have its Solution, in lang. It may only use numbers from the
+// problem and its steps, and must give the answer; the cleaned-up text is
+// returned.
+func AcceptExplanation(p Problem, reply, lang string) (string, error) {
+	text := strings.Trim(strings.TrimSpace(cleanCodeBlock(reply)), `"`)
+	if text == "" {
+		return "", fmt.Errorf("explanation is empty")
+	}
+	if utf8.RuneCountInString(text) > 600 {
+		return "", fmt.Errorf("explanation is too long for a child")
+	}
+	known := numberText(p.Text, lang) + " " + p.Solution.Equation + " " + strings.Join(p.Solution.Steps, " ") + " " + p.Answer
+	allowed := map[string]bool{}
+	for _, n := range reInts.FindAllString(known, -1) {
+		allowed[n] = true
+	}
+	said := numberText(text, lang)
+	for _, n := range reInts.FindAllString(said, -1) {
+		if !allowed[n] {
+			return "", fmt.Errorf("explanation has %s, which is not in the problem or its steps", n)
+		}
+	}
+	answer := reInts.FindAllString(p.Answer, -1)
+	switch {
+	case len(answer) > 0 && !containsAll(reInts.FindAllString(said, -1), answer):
+		return "", fmt.Errorf("explanation does not give the answer %s", p.Answer)
+	case len(answer) == 0 && !strings.Contains(strings.ToLower(text), strings.ToLower(p.Answer)):
+		return "", fmt.Errorf("explanation does not give the answer %s", p.Answer)
+	}
+	return text, nil
+}
+
+func containsAll(have, want []string) bool {
+	for _, w := range want {
+		found := false
+		for _, h := range have {
+			found = found || h == w
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
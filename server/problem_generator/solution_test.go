@@ -0,0 +1,124 @@
+package problemgenerator
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestWorked(t *testing.T) {
+	tests := []struct {
+		op    string
+		a, b  int
+		steps []string
+	}{
+		{OpAddition, 7, 5, []string{
+			"Start at 7 and count on 5 more: 12.",
+			"So 7 + 5 = 12.",
+		}},
+		{OpAddition, 47, 25, []string{
+			"Ones: 7 + 5 = 12. Write 2 and carry 1 to the tens.",
+			"Tens: 4 + 2 + 1 = 7.",
+			"So 47 + 25 = 72.",
+		}},
+		{OpAddition, 999, 1, []string{
+			"Ones: 9 + 1 = 10. Write 0 and carry 1 to the tens.",
+			"Tens: 9 + 0 + 1 = 10. Write 0 and carry 1 to the hundreds.",
+			"Hundreds: 9 + 0 + 1 = 10. Write 0 and carry 1 to the thousands.",
+			"So 999 + 1 = 1000.",
+		}},
+		{OpSubtraction, 15, 3, []string{
+			"Start at 15 and count back 3: 12.",
+			"So 15 − 3 = 12.",
+		}},
+		{OpSubtraction, 72, 45, []string{
+			"Ones: 2 is less than 5, so borrow 1 from the tens: 12 − 5 = 7.",
+			"Tens: 7 − 1 − 4 = 2.",
+			"So 72 − 45 = 27.",
+		}},
+		{OpSubtraction, 100, 1, []string{
+			"Ones: 0 is less than 1, so borrow 1 from the tens: 10 − 1 = 9.",
+			"Tens: this 0 had to borrow 1 from the hundreds first, so it is now 9: 9 − 0 = 9.",
+			"So 100 − 1 = 99.",
+		}},
+		{OpSubtraction, 305, 126, []string{
+			"Ones: 5 is less than 6, so borrow 1 from the tens: 15 − 6 = 9.",
+			"Tens: this 0 had to borrow 1 from the hundreds first, so it is now 9: 9 − 2 = 7.",
+			"Hundreds: 3 − 1 − 1 = 1.",
+			"So 305 − 126 = 179.",
+		}},
+		{OpSubtraction, 90, 90, []string{
+			"Ones: 0 − 0 = 0.",
+			"Tens: 9 − 9 = 0.",
+			"So 90 − 90 = 0.",
+		}},
+		{"division", 17, 5, []string{
+			"How many groups of 5 make 17?",
+			"5 × 3 = 15, with 2 left over.",
+			"So 17 ÷ 5 = 3.",
+		}},
+		{OpSubtraction, 3, 8, nil},      // below zero
+		{"division", 8, 0, nil},         // by zero
+		{OpAddition, 1_000_000, 1, nil}, // more places than there are names for
+	}
+	w := solutionPhrases["en"]
+	for _, tt := range tests {
+		s := worked(tt.op, tt.a, tt.b, w)
+		var steps []string
+		if s != nil {
+			steps = s.Steps
+		}
+		if !slices.Equal(steps, tt.steps) {
+			t.Errorf("worked(%d %s %d) steps:\n%q\nwant:\n%q", tt.a, OpSymbol(tt.op), tt.b, steps, tt.steps)
+		}
+	}
+}
+
+func TestWorkedLanguages(t *testing.T) {
+	tests := []struct {
+		lang  string
+		steps []string
+	}{
+		{"es", []string{
+			"Unidades: 0 es menor que 1, así que pide 1 prestado a las decenas: 10 − 1 = 9.",
+			"Decenas: este 0 primero pidió 1 prestado a las centenas, así que ahora es 9: 9 − 0 = 9.",
+			"Entonces 100 − 1 = 99.",
+		}},
+		{"fr", []string{
+			"Unités : 0 est plus petit que 1, donc on emprunte 1 aux dizaines : 10 − 1 = 9.",
+			"Dizaines : ce 0 a d'abord emprunté 1 aux centaines, il vaut donc 9 : 9 − 0 = 9.",
+			"Donc 100 − 1 = 99.",
+		}},
+		{"vi", []string{
+			"Hàng đơn vị: 0 bé hơn 1 nên mượn 1 từ hàng chục: 10 − 1 = 9.",
+			"Hàng chục: số 0 này đã mượn 1 từ hàng trăm nên giờ là 9: 9 − 0 = 9.",
+			"Vậy 100 − 1 = 99.",
+		}},
+	}
+	for _, tt := range tests {
+		s := worked(OpSubtraction, 100, 1, solutionPhrases[tt.lang])
+		if s == nil || !slices.Equal(s.Steps, tt.steps) {
+			t.Errorf("%s: worked(100 − 1) = %+v, want steps %q", tt.lang, s, tt.steps)
+		}
+	}
+}
+
+func TestBackwards(t *testing.T) {
+	tests := []struct {
+		op, unknown       string
+		a, b, result      int
+		equation, inverse string
+	}{
+		{OpAddition, SlotA, 5, 4, 9, "5 + 4 = 9", "Work backwards to find the missing number: 9 − 4 = 5."},
+		{OpSubtraction, SlotB, 12, 8, 4, "12 − 8 = 4", "Work backwards to find the missing number: 12 − 4 = 8."},
+		{"multiplication", SlotB, 3, 4, 12, "3 × 4 = 12", "Work backwards to find the missing number: 12 ÷ 3 = 4."},
+		{"division", SlotA, 18, 3, 6, "18 ÷ 3 = 6", "Work backwards to find the missing number: 6 × 3 = 18."},
+		{OpAddition, SlotResult, 3, 7, 10, "3 + 7 = 10", "Start at 7 and count on 3 more: 10."},
+	}
+	w := solutionPhrases["en"]
+	for _, tt := range tests {
+		s := backwards(tt.op, tt.unknown, tt.a, tt.b, tt.result, w)
+		if s == nil || s.Equation != tt.equation || s.Steps[0] != tt.inverse {
+			t.Errorf("backwards(%s, %s) = %+v, want %q then %q", tt.equation, tt.unknown, s, tt.equation, tt.inverse)
+		}
+	}
+}
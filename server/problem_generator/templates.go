@@ -0,0 +1,262 @@
+package problemgenerator
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+
+	pb "github.com/qjs/mathgen_gemma/server/proto"
+)
+
+// TemplateData is what a mad-lib template is filled with. Num1 and Num2 are
+// the operands in equation order, so "Num1 − Num2" or "Num1 ÷ Num2" is the
+// problem the child solves.
+type TemplateData struct {
+	Name  string
+	Noun1 string
+	Noun2 string
+	Noun3 string
+	Verb1 string
+	Verb2 string
+	Num1  int
+	Num2  int
+}
+
+var templateFuncs = template.FuncMap{"ing": ingForm}
+
+// ingForm turns a verb into its -ing form ("dance" → "dancing",
+// "run" → "running", "play" → "playing").
+func ingForm(verb string) string {
+	v := strings.TrimSpace(verb)
+	r := []rune(v)
+	n := len(r)
+	switch {
+	case n == 0:
+		return v
+	case strings.HasSuffix(v, "ie"):
+		return string(r[:n-2]) + "ying"
+	case strings.HasSuffix(v, "e") && !strings.HasSuffix(v, "ee") && n > 2:
+		return string(r[:n-1]) + "ing"
+	case n >= 3 && n <= 4 && !isVowel(r[n-1]) && !strings.ContainsRune("wxy", r[n-1]) &&
+		isVowel(r[n-2]) && !isVowel(r[n-3]):
+		return v + string(r[n-1]) + "ing" // short consonant-vowel-consonant verbs double
+	}
+	return v + "ing"
+}
+
+func isVowel(r rune) bool { return strings.ContainsRune("aeiou", unicode.ToLower(r)) }
+
+// parseTemplate compiles a template and checks it by filling it with sample
+// data: it must use both numbers and only the fields TemplateData has.
+func parseTemplate(t Template) (*template.Template, error) {
+	tmpl, err := template.New(strconv.Itoa(t.ID)).Funcs(templateFuncs).Parse(t.Template)
+	if err != nil {
+		return nil, fmt.Errorf("template %d: %v", t.ID, err)
+	}
+	if !strings.Contains(t.Template, ".Num1") || !strings.Contains(t.Template, ".Num2") {
+		return nil, fmt.Errorf("template %d: must use both {{.Num1}} and {{.Num2}}", t.ID)
+	}
+	sample := TemplateData{Name: "Sam", Noun1: "apples", Noun2: "pears", Noun3: "plums", Verb1: "jump", Verb2: "sing", Num1: 8, Num2: 2}
+	if err := tmpl.Execute(io.Discard, sample); err != nil {
+		return nil, fmt.Errorf("template %d: %v", t.ID, err)
+	}
+	return tmpl, nil
+}
+
+// ---------- file-backed repository ----------
+
+// FileTemplateRepo holds templates read from a CSV file with the header
+// id,operation,template.
+type FileTemplateRepo struct {
+	byOp map[string][]Template
+}
+
+// NewFileTemplateRepo reads and checks every template in path.
+func NewFileTemplateRepo(path string) (*FileTemplateRepo, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	templates, err := ReadTemplates(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	repo := &FileTemplateRepo{byOp: map[string][]Template{}}
+	for _, t := range templates {
+		repo.byOp[t.Operation] = append(repo.byOp[t.Operation], t)
+	}
+	return repo, nil
+}
+
+// ReadTemplates parses templates in CSV form, normalizing operations and
+// rejecting any template that does not compile.
+func ReadTemplates(r io.Reader) ([]Template, error) {
+	rows, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, errors.New("no templates")
+	}
+	var out []Template
+	for i, row := range rows[1:] { // skip header
+		if len(row) != 3 {
+			return nil, fmt.Errorf("row %d: want id,operation,template, got %d fields", i+2, len(row))
+		}
+		id, err := strconv.Atoi(strings.TrimSpace(row[0]))
+		if err != nil {
+			return nil, fmt.Errorf("row %d: bad id %q", i+2, row[0])
+		}
+		t := Template{ID: id, Operation: NormalizeOperation(row[1]), Template: strings.TrimSpace(row[2])}
+		if _, ok := opSymbols[t.Operation]; !ok {
+			return nil, fmt.Errorf("row %d: unknown operation %q", i+2, row[1])
+		}
+		if _, err := parseTemplate(t); err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+// All returns every template in the file.
+func (r *FileTemplateRepo) All() []Template {
+	var out []Template
+	for _, op := range []string{OpAddition, OpSubtraction, "multiplication", "division"} {
+		out = append(out, r.byOp[op]...)
+	}
+	return out
+}
+
+func (r *FileTemplateRepo) ListByOperation(_ context.Context, op string) ([]Template, error) {
+	return append([]Template(nil), r.byOp[NormalizeOperation(op)]...), nil
+}
+
+// ---------- template agent ----------
+
+// TemplateAgent builds problem sets from mad-lib templates with no model at
+// all. The numbers are picked for the grade and the answers computed, so it
+// works offline and as a fallback when Ollama is unavailable.
+type TemplateAgent struct {
+	repo TemplateRepo
+}
+
+func NewTemplateAgent(repo TemplateRepo) *TemplateAgent {
+	return &TemplateAgent{repo: repo}
+}
+
+// Parse ignores the model output and fills templates instead, so a
+// TemplateAgent can stand in wherever an Agent is expected.
+func (a *TemplateAgent) Parse(_ string, req *pb.GenerateRequest) (*ProblemSet, error) {
+	return a.Generate(context.Background(), req)
+}
+
+// Generate fills one template per problem, cycling through the templates
+// for each problem's operation in a random order.
+func (a *TemplateAgent) Generate(ctx context.Context, req *pb.GenerateRequest) (*ProblemSet, error) {
+	if kind := NormalizeKind(req.Kind); kind != KindArithmetic {
+		return nil, fmt.Errorf("templates only cover arithmetic, not %s", kind)
+	}
+	ops, err := OperationSlots(req)
+	if err != nil {
+		return nil, err
+	}
+	rng := rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+	byOp := map[string][]Template{}
+	for _, op := range ops {
+		if _, ok := byOp[op]; ok {
+			continue
+		}
+		templates, err := a.repo.ListByOperation(ctx, op)
+		if err != nil {
+			return nil, err
+		}
+		if len(templates) == 0 {
+			return nil, fmt.Errorf("no templates for %s", op)
+		}
+		rng.Shuffle(len(templates), func(i, j int) { templates[i], templates[j] = templates[j], templates[i] })
+		byOp[op] = templates
+	}
+
+	nouns := req.LikesNouns
+	if len(nouns) == 0 {
+		nouns = []string{"stars", "apples", "blocks"}
+	}
+	verbs := req.LikesVerbs
+	if len(verbs) == 0 {
+		verbs = []string{"play", "read"}
+	}
+	used := map[string]int{}
+	grade := GradeNumber(req.GradeLevel)
+	specs := slotSpecs(req, len(ops))
+
+	problems := make([]Problem, 0, len(ops))
+	for i := 1; i <= len(ops); i++ {
+		op := ops[i-1]
+		templates := byOp[op]
+		t := templates[used[op]%len(templates)]
+		used[op]++
+		tmpl, err := parseTemplate(t)
+		if err != nil {
+			return nil, fmt.Errorf("problem %d: %v", i, err)
+		}
+		// Stories read badly with "0 of them" or "1 piles", so both
+		// numbers are at least 2.
+		num1, num2, _ := pickFor(op, grade, specs[i-1], rng)
+		for tries := 0; (num1 < 2 || num2 < 2) && tries < 50; tries++ {
+			num1, num2, _ = pickFor(op, grade, specs[i-1], rng)
+		}
+		data := TemplateData{
+			Name:  req.Name,
+			Noun1: nouns[(i-1)%len(nouns)],
+			Noun2: nouns[i%len(nouns)],
+			Noun3: nouns[(i+1)%len(nouns)],
+			Verb1: verbs[(i-1)%len(verbs)],
+			Verb2: verbs[i%len(verbs)],
+			Num1:  num1,
+			Num2:  num2,
+		}
+		var text strings.Builder
+		if err := tmpl.Execute(&text, data); err != nil {
+			return nil, fmt.Errorf("problem %d: %v", i, err)
+		}
+		ans, err := computeAnswer(op, num1, num2)
+		if err != nil {
+			return nil, fmt.Errorf("problem %d: %v", i, err)
+		}
+		theme := data.Noun1
+		for _, n := range []struct{ field, noun string }{{".Noun2", data.Noun2}, {".Noun3", data.Noun3}} {
+			if !strings.Contains(t.Template, ".Noun1") && strings.Contains(t.Template, n.field) {
+				theme = n.noun
+				break
+			}
+		}
+		interest := ""
+		if len(req.LikesNouns) > 0 {
+			interest = theme
+		}
+		if e, ok := emojiFor(theme); ok {
+			theme += " " + e
+		}
+		problems = append(problems, Problem{
+			Index:     i,
+			Theme:     theme,
+			Text:      text.String(),
+			Numbers:   []int{num1, num2},
+			Operation: op,
+			Answer:    ans,
+			Kind:      KindArithmetic,
+			Interest:  interest,
+		})
+	}
+	return &ProblemSet{Problems: problems, MetaInfo: metaFromRequest(req)}, nil
+}
@@ -0,0 +1,102 @@
+package problemgenerator
+
+import (
+	"context"
+	"database/sql"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const templateSchema = `CREATE TABLE IF NOT EXISTS templates (
+	id        INTEGER PRIMARY KEY,
+	operation TEXT NOT NULL,
+	template  TEXT NOT NULL
+)`
+
+// SQLiteTemplateRepo keeps templates in a SQLite database so they can be
+// authored and edited without touching the files the server ships with.
+type SQLiteTemplateRepo struct {
+	db *sql.DB
+}
+
+// OpenSQLiteTemplateRepo opens (creating if needed) the database at path.
+func OpenSQLiteTemplateRepo(path string) (*SQLiteTemplateRepo, error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(templateSchema); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &SQLiteTemplateRepo{db: db}, nil
+}
+
+func (r *SQLiteTemplateRepo) Close() error { return r.db.Close() }
+
+// Seed adds templates when the table is still empty, so a new database
+// starts out with the shipped set and later edits are left alone.
+func (r *SQLiteTemplateRepo) Seed(ctx context.Context, templates []Template) error {
+	var n int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM templates`).Scan(&n); err != nil {
+		return err
+	}
+	if n > 0 {
+		return nil
+	}
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	for _, t := range templates {
+		if err := r.insert(ctx, tx, t); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Add stores a template after checking that it compiles. A zero ID gets
+// the next free one.
+func (r *SQLiteTemplateRepo) Add(ctx context.Context, t Template) error {
+	t.Operation = NormalizeOperation(t.Operation)
+	if _, err := parseTemplate(t); err != nil {
+		return err
+	}
+	return r.insert(ctx, r.db, t)
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+func (r *SQLiteTemplateRepo) insert(ctx context.Context, db execer, t Template) error {
+	var id any
+	if t.ID > 0 {
+		id = t.ID // otherwise SQLite picks the next id
+	}
+	_, err := db.ExecContext(ctx,
+		`INSERT INTO templates (id, operation, template) VALUES (?, ?, ?)`,
+		id, NormalizeOperation(t.Operation), t.Template)
+	return err
+}
+
+func (r *SQLiteTemplateRepo) ListByOperation(ctx context.Context, op string) ([]Template, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT id, operation, template FROM templates WHERE operation = ? ORDER BY id`,
+		NormalizeOperation(op))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []Template
+	for rows.Next() {
+		var t Template
+		if err := rows.Scan(&t.ID, &t.Operation, &t.Template); err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,217 @@
+package problemgenerator
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Theme is a canonical worksheet theme, such as "Dinosaurs 🦖", and the
+// words that mean it.
+type Theme struct {
+	Name  string
+	Emoji string
+	Words []string
+}
+
+func (t Theme) String() string {
+	if t.Emoji == "" {
+		return t.Name
+	}
+	return t.Name + " " + t.Emoji
+}
+
+// ThemeDictionary maps interests and their synonyms to themes.
+type ThemeDictionary struct {
+	themes []Theme
+	byWord map[string]int // word or phrase → index into themes
+}
+
+// ReadThemes parses a theme dictionary in CSV form with the header
+// theme,emoji,words, where words are separated by "|".
+func ReadThemes(r io.Reader) (*ThemeDictionary, error) {
+	rows, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, errors.New("no themes")
+	}
+	d := &ThemeDictionary{byWord: map[string]int{}}
+	for i, row := range rows[1:] { // skip header
+		if len(row) != 3 {
+			return nil, fmt.Errorf("row %d: want theme,emoji,words, got %d fields", i+2, len(row))
+		}
+		t := Theme{Name: strings.TrimSpace(row[0]), Emoji: strings.TrimSpace(row[1])}
+		if t.Name == "" {
+			return nil, fmt.Errorf("row %d: no theme name", i+2)
+		}
+		t.Words = append(t.Words, strings.ToLower(t.Name))
+		for _, w := range strings.Split(row[2], "|") {
+			if w = strings.ToLower(strings.TrimSpace(w)); w != "" {
+				t.Words = append(t.Words, w)
+			}
+		}
+		for _, w := range t.Words {
+			if _, dup := d.byWord[w]; !dup {
+				d.byWord[w] = len(d.themes)
+			}
+		}
+		d.themes = append(d.themes, t)
+	}
+	return d, nil
+}
+
+// LoadThemes reads a theme dictionary from a CSV file.
+func LoadThemes(path string) (*ThemeDictionary, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	d, err := ReadThemes(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return d, nil
+}
+
+//go:embed themes/themes.csv
+var defaultThemesCSV string
+
+var (
+	themesMu sync.RWMutex
+	themes   = func() *ThemeDictionary {
+		d, err := ReadThemes(strings.NewReader(defaultThemesCSV))
+		if err != nil {
+			panic(err)
+		}
+		return d
+	}()
+)
+
+// Themes returns the dictionary in use: the built-in themes/themes.csv, or
+// the one set with SetThemes.
+func Themes() *ThemeDictionary {
+	themesMu.RLock()
+	defer themesMu.RUnlock()
+	return themes
+}
+
+// SetThemes replaces the theme dictionary, e.g. with an edited copy of
+// themes.csv loaded at startup.
+func SetThemes(d *ThemeDictionary) {
+	themesMu.Lock()
+	defer themesMu.Unlock()
+	themes = d
+}
+
+var reThemeWord = regexp.MustCompile(`[\p{L}\p{N}'-]+`)
+
+// Lookup finds the theme s is about: the whole of s first, then two-word
+// phrases and single words, allowing plurals ("Dinosaurs!" or "dinos 🦖"
+// are both Dinosaurs).
+func (d *ThemeDictionary) Lookup(s string) (Theme, bool) {
+	words := reThemeWord.FindAllString(strings.ToLower(s), -1)
+	candidates := []string{strings.Join(words, " ")}
+	for i := 0; i+1 < len(words); i++ {
+		candidates = append(candidates, words[i]+" "+words[i+1])
+	}
+	candidates = append(candidates, words...)
+	for _, c := range candidates {
+		forms := []string{c, strings.TrimSuffix(c, "s"), strings.TrimSuffix(c, "es")}
+		if stem, ok := strings.CutSuffix(c, "ies"); ok {
+			forms = append(forms, stem+"y")
+		}
+		for _, form := range forms {
+			if i, ok := d.byWord[form]; ok && form != "" {
+				return d.themes[i], true
+			}
+		}
+	}
+	return Theme{}, false
+}
+
+// Normalize returns the canonical form of a theme the model wrote. Themes
+// not in the dictionary keep their name, tidied, and get the emoji of the
+// first dictionary word in text when they have none.
+func (d *ThemeDictionary) Normalize(theme, text string) string {
+	if t, ok := d.Lookup(theme); ok {
+		return t.String()
+	}
+	name := strings.Trim(strings.TrimSpace(theme), "!.,:;")
+	if name == "" {
+		if t, ok := d.Lookup(text); ok {
+			return t.String()
+		}
+		return ""
+	}
+	if hasEmoji(name) {
+		return name
+	}
+	for _, w := range reThemeWord.FindAllString(text, -1) {
+		if t, ok := d.Lookup(w); ok && t.Emoji != "" {
+			return name + " " + t.Emoji
+		}
+	}
+	return name
+}
+
+// hasEmoji reports whether s has a pictograph in it.
+func hasEmoji(s string) bool {
+	for _, r := range s {
+		if r >= 0x1F000 || (r >= 0x2600 && r <= 0x27BF) || r == 0x2B50 {
+			return true
+		}
+	}
+	return false
+}
+
+// emojiFor is the emoji of the theme noun belongs to.
+func emojiFor(noun string) (string, bool) {
+	if t, ok := Themes().Lookup(noun); ok && t.Emoji != "" {
+		return t.Emoji, true
+	}
+	return "", false
+}
+
+// NormalizeThemes rewrites every problem's Theme in canonical form. Sets in
+// other languages keep the themes the model wrote.
+func NormalizeThemes(ps *ProblemSet) {
+	if !isEnglish(ps.MetaInfo) {
+		return // the dictionary is English: "Animales" is not "Animals 🐾"
+	}
+	d := Themes()
+	for i, p := range ps.Problems {
+		ps.Problems[i].Theme = d.Normalize(p.Theme, p.Text)
+	}
+}
+
+// ThemeGroup is the problems of a set that share a theme.
+type ThemeGroup struct {
+	Theme    string
+	Problems []Problem
+}
+
+// GroupByTheme gathers problems by theme, in the order each theme first
+// appears.
+func GroupByTheme(problems []Problem) []ThemeGroup {
+	var groups []ThemeGroup
+	at := map[string]int{}
+	for _, p := range problems {
+		i, ok := at[p.Theme]
+		if !ok {
+			i = len(groups)
+			at[p.Theme] = i
+			groups = append(groups, ThemeGroup{Theme: p.Theme})
+		}
+		groups[i].Problems = append(groups[i].Problems, p)
+	}
+	return groups
+}
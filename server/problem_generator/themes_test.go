@@ -0,0 +1,70 @@
+package problemgenerator
+
+import (
+	"strings"
+	"testing"
+)
+
+const testThemes = `theme,emoji,words
+Dinosaurs,🦖,dinosaur|dino|t-rex
+Space,🚀,rocket|astronaut|planet
+Ice Cream,🍦,ice cream|gelato
+Puppies,🐶,puppy|dog
+Stories,,book
+`
+
+func TestThemeNormalize(t *testing.T) {
+	d, err := ReadThemes(strings.NewReader(testThemes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		theme, text, want string
+	}{
+		{"dinosaurs", "", "Dinosaurs 🦖"},
+		{"Dinos!", "", "Dinosaurs 🦖"},
+		{"T-Rex", "", "Dinosaurs 🦖"},
+		{"rockets and planets", "", "Space 🚀"},
+		{"Yummy ice cream", "", "Ice Cream 🍦"},
+		{"puppies", "", "Puppies 🐶"},
+		{"books", "", "Stories"},
+		{"Baking", "Leo bakes 3 cakes for his dog.", "Baking 🐶"},
+		{"Baking 🎂", "Leo bakes 3 cakes for his dog.", "Baking 🎂"},
+		{"  Baking. ", "Leo bakes 3 cakes.", "Baking"},
+		{"", "Ana flies her rocket.", "Space 🚀"},
+		{"", "Ana bakes.", ""},
+	}
+	for _, tt := range tests {
+		if got := d.Normalize(tt.theme, tt.text); got != tt.want {
+			t.Errorf("Normalize(%q, %q) = %q, want %q", tt.theme, tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestReadThemes(t *testing.T) {
+	tests := []struct {
+		name, csv string
+	}{
+		{"empty", ""},
+		{"missing field", "theme,emoji,words\nDinosaurs,🦖\n"},
+		{"no name", "theme,emoji,words\n ,🦖,dino\n"},
+	}
+	for _, tt := range tests {
+		if _, err := ReadThemes(strings.NewReader(tt.csv)); err == nil {
+			t.Errorf("%s: ReadThemes succeeded, want error", tt.name)
+		}
+	}
+	if _, ok := Themes().Lookup("dinosaurs"); !ok {
+		t.Error("the built-in dictionary has no dinosaurs")
+	}
+}
+
+func TestGroupByTheme(t *testing.T) {
+	groups := GroupByTheme([]Problem{
+		{Index: 1, Theme: "Space 🚀"}, {Index: 2, Theme: "Puppies 🐶"}, {Index: 3, Theme: "Space 🚀"},
+	})
+	if len(groups) != 2 || groups[0].Theme != "Space 🚀" || len(groups[0].Problems) != 2 ||
+		groups[0].Problems[1].Index != 3 || groups[1].Theme != "Puppies 🐶" {
+		t.Errorf("GroupByTheme = %+v, want Space (1, 3) then Puppies (2)", groups)
+	}
+}
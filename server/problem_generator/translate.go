@@ -0,0 +1,95 @@
+package problemgenerator
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Translate starts translating ps into lang: a copy of ps whose MetaInfo is
+// in lang and, with dual, whose problems keep their text in SourceText for a
+// side-by-side worksheet. The texts stay the originals until the
+// translations are accepted.
+func Translate(ps *ProblemSet, lang string, dual bool) *ProblemSet {
+	out := *ps
+	out.Problems = slices.Clone(ps.Problems)
+	out.Issues = nil
+	out.SourceLanguage = NormalizeLanguage(ps.MetaInfo.Language)
+	out.MetaInfo.Language = NormalizeLanguage(lang)
+	for i, p := range out.Problems {
+		out.Problems[i].ReadingGrade = 0 // measured on the old text
+		out.Problems[i].SourceText = ""
+		if dual {
+			out.Problems[i].SourceText = p.Text
+		}
+	}
+	return &out
+}
+
+// AcceptTranslation reads the model's translation of p, a problem of a set
+// described by meta, into lang. The translation must state the same numbers
+// in the same order, keep the student's name and still ask its question, so
+// the answer key holds; the translated theme and text are returned.
+func AcceptTranslation(p Problem, reply string, meta GenerateRequest, lang string) (theme, text string, err error) {
+	var t struct {
+		Theme string `json:"theme"`
+		Text  string `json:"text"`
+	}
+	if err := json.Unmarshal([]byte(cleanCodeBlock(reply)), &t); err != nil {
+		return "", "", fmt.Errorf("translation is not JSON: %v", err)
+	}
+	text = strings.TrimSpace(t.Text)
+	if text == "" {
+		return "", "", fmt.Errorf("translation is empty")
+	}
+	before := reInts.FindAllString(numberText(p.Text, meta.Language), -1)
+	after := reInts.FindAllString(numberText(text, lang), -1)
+	if strings.Join(before, ",") != strings.Join(after, ",") {
+		return "", "", fmt.Errorf("translation changed the numbers from [%s] to [%s]",
+			strings.Join(before, ", "), strings.Join(after, ", "))
+	}
+	if name := strings.TrimSpace(meta.Name); name != "" && mentions(p.Text, name) && !mentions(text, name) {
+		return "", "", fmt.Errorf("translation lost the name %s", name)
+	}
+	if strings.HasSuffix(strings.TrimSpace(p.Text), "?") && !strings.HasSuffix(text, "?") {
+		return "", "", fmt.Errorf("translation does not end with a question")
+	}
+	theme = strings.TrimSpace(t.Theme)
+	if theme == "" {
+		theme = p.Theme
+	}
+	return theme, text, nil
+}
+
+// mentions reports whether text has name as a whole word.
+func mentions(text, name string) bool { return nameIndex(text, name) >= 0 }
+
+// nameIndex is where name first appears in text as a whole word, so "Sam"
+// is not found in "Samantha", or -1 when it does not.
+func nameIndex(text, name string) int {
+	m := regexp.MustCompile(`(?i)(^|[^\p{L}])(` + regexp.QuoteMeta(name) + `)($|[^\p{L}])`).FindStringSubmatchIndex(text)
+	if m == nil {
+		return -1
+	}
+	return m[4]
+}
+
+// RecomputeAnswers works out again the answer of every a op b problem of
+// ps from its numbers, so a translated set's answer key is the math's, not
+// whatever the stored set said. Other answers (units, names, choices) do not
+// depend on the language and are kept.
+func RecomputeAnswers(ps *ProblemSet) {
+	for i, p := range ps.Problems {
+		op := NormalizeOperation(p.Operation)
+		switch kind := NormalizeKind(p.Kind); {
+		case (kind == KindMissing || kind == KindEquation) && len(p.Numbers) >= 3:
+			ps.Problems[i].Answer = slotValue(p.Unknown, p.Numbers[0], p.Numbers[1], p.Numbers[2])
+		case kind == KindArithmetic && len(p.Numbers) >= 2:
+			if answer, err := computeAnswer(op, p.Numbers[0], p.Numbers[1]); err == nil {
+				ps.Problems[i].Answer = answer
+			}
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package problemgenerator
+
+import "testing"
+
+func TestRecomputeAnswers(t *testing.T) {
+	ps := &ProblemSet{Problems: []Problem{
+		{Kind: KindArithmetic, Operation: "add", Numbers: []int{12, 30}, Answer: "40"},
+		{Kind: KindArithmetic, Operation: "division", Numbers: []int{20, 4}, Answer: "4"},
+		{Kind: KindMissing, Operation: "sub", Unknown: "start", Numbers: []int{13, 5, 8}, Answer: "8"},
+		{Kind: KindEquation, Operation: "add", Unknown: "b", Numbers: []int{3, 7, 10}, Answer: "3"},
+		{Kind: KindEquation, Operation: "add", Unknown: "result", Numbers: []int{3, 7, 10}, Answer: "7"},
+		{Kind: KindMeasurement, Operation: "add", Numbers: []int{2, 30}, Answer: "230 cm"},
+		{Kind: KindComparison, Operation: OpWhoHasMore, Numbers: []int{3, 7}, Answer: "Leo"},
+	}}
+	RecomputeAnswers(ps)
+	want := []string{"42", "5", "13", "7", "10", "230 cm", "Leo"}
+	for i, p := range ps.Problems {
+		if p.Answer != want[i] {
+			t.Errorf("problem %d (%s): answer %q, want %q", i+1, p.Kind, p.Answer, want[i])
+		}
+	}
+}
@@ -4,26 +4,62 @@ import "context"
 
 type GenerateRequest struct {
 	Name        string   `json:"name"`
-	Gender      string   `json:"gender"`
-	Operation   string   `json:"operation"` // add | sub | mul | div
+	Gender      string   `json:"gender,omitempty"` // older requests; see Pronouns
+	Pronouns    string   `json:"pronouns"`         // she/her | he/him | they/them
+	Operation   string   `json:"operation"`        // add | sub | mul | div | mixed
 	NumProblems int      `json:"num_problems"`
 	GradeLevel  string   `json:"grade_level"`
 	LikesNouns  []string `json:"likes_nouns"`
 	LikesVerbs  []string `json:"likes_verbs"`
+	Kind        string   `json:"kind,omitempty"`        // arithmetic | measurement | comparison | missing_number | equation | pattern | chart | place_value | rounding | estimation
+	UnitSystem  string   `json:"unit_system,omitempty"` // metric | imperial
+
+	InterestBalance string           `json:"interest_balance,omitempty"` // round_robin | weighted
+	Operations      []OperationShare `json:"operations,omitempty"`       // mixed sets; Operation is then OpMixed
+	Arrange         string           `json:"arrange,omitempty"`          // interleaved | grouped | shuffled
+	Progression     string           `json:"progression,omitempty"`      // ramp | uniform | spiral
+	Regrouping      string           `json:"regrouping,omitempty"`       // none | some | all | places such as "tens", see Regrouping
+	Language        string           `json:"language,omitempty"`         // en | es | fr | vi
+	Agent           string           `json:"agent,omitempty"`            // the agent that made the set, e.g. json
 }
 
 type Problem struct {
-	Index     int    `json:"index"`
-	Theme     string `json:"theme"`
-	Text      string `json:"text"`
-	Numbers   []int  `json:"numbers"`
-	Operation string `json:"operation"`
-	Answer    string `json:"answer"`
+	Index     int      `json:"index"`
+	Slot      int      `json:"slot,omitempty"` // Index it was planned as, before Arrange numbered it again; see PlannedSlot
+	Theme     string   `json:"theme"`
+	Text      string   `json:"text"`
+	Numbers   []int    `json:"numbers"`
+	Operation string   `json:"operation"`
+	Answer    string   `json:"answer"`
+	Kind      string   `json:"kind,omitempty"`
+	Accept    []string `json:"accept,omitempty"`   // equivalent spellings of Answer
+	Choices   []string `json:"choices,omitempty"`  // options for pick-one answers
+	Unknown   string   `json:"unknown,omitempty"`  // missing slot: a | b | result
+	Equation  string   `json:"equation,omitempty"` // "? + 4 = 9", Blank marks Unknown
+	Sequence  []string `json:"sequence,omitempty"` // pattern terms, Blank marks the one to find
+	Rule      string   `json:"rule,omitempty"`     // pattern rule, e.g. "add 3"
+	Expanded  string   `json:"expanded,omitempty"` // expanded form of the number, "300 + 40 + 7"
+
+	ReadingGrade float64 `json:"reading_grade,omitempty"` // Flesch-Kincaid grade of Text, see ScoreReadability
+	Interest     string  `json:"interest,omitempty"`      // the student's interest it was written about
+	Difficulty   int     `json:"difficulty,omitempty"`    // 1 (warm-up) to 3 (challenge) stars, see ScoreDifficulty
+	SourceText   string  `json:"source_text,omitempty"`   // Text before translation, for dual-language worksheets
+
+	Chart    *Chart    `json:"chart_data,omitempty"` // what a chart question is about, see RenderChart
+	Solution *Solution `json:"solution,omitempty"`   // how to work out Answer, see Solve
+	Hints    []string  `json:"hints,omitempty"`      // gentlest first, none giving Answer away; see AddHints
 }
 
 type ProblemSet struct {
+	ID       string          `json:"id,omitempty"` // of its Provenance record
 	Problems []Problem       `json:"problems"`
 	MetaInfo GenerateRequest `json:"MetaInfo"`
+	Issues   []Issue         `json:"issues,omitempty"` // what validation found, see Chain
+
+	Diversity float64    `json:"diversity"`          // how different the problems are, see Diversity
+	Coverage  []Coverage `json:"coverage,omitempty"` // which problems use each interest
+
+	SourceLanguage string `json:"source_language,omitempty"` // what a translated set was written in, see Translate
 }
 
 /* ---- repository abstraction ---- */
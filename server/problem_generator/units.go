@@ -0,0 +1,279 @@
+package problemgenerator
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Dimension is the kind of quantity a Unit measures.
+type Dimension string
+
+const (
+	Length Dimension = "length"
+	Weight Dimension = "weight"
+	Volume Dimension = "volume"
+	Time   Dimension = "time"
+)
+
+// Unit systems a measurement worksheet can be written in. Time units belong
+// to both.
+const (
+	SystemMetric   = "metric"
+	SystemImperial = "imperial"
+)
+
+// Unit is a single measurement unit. Factor is the size of the unit in the
+// smallest unit of the same Dimension and System, so conversions stay in
+// integers (1 m = 1000 mm, 1 ft = 12 in).
+type Unit struct {
+	Symbol    string
+	Singular  string
+	Plural    string
+	Dimension Dimension
+	System    string // "" for time
+	Factor    int
+	MinGrade  int // first grade the unit appears on a worksheet
+}
+
+// Units lists every unit the generator knows, smallest first within each
+// dimension and system.
+var Units = []Unit{
+	{"mm", "millimeter", "millimeters", Length, SystemMetric, 1, 4},
+	{"cm", "centimeter", "centimeters", Length, SystemMetric, 10, 0},
+	{"m", "meter", "meters", Length, SystemMetric, 1000, 2},
+	{"km", "kilometer", "kilometers", Length, SystemMetric, 1000000, 4},
+	{"in", "inch", "inches", Length, SystemImperial, 1, 0},
+	{"ft", "foot", "feet", Length, SystemImperial, 12, 2},
+	{"yd", "yard", "yards", Length, SystemImperial, 36, 3},
+	{"mi", "mile", "miles", Length, SystemImperial, 63360, 4},
+	{"g", "gram", "grams", Weight, SystemMetric, 1, 3},
+	{"kg", "kilogram", "kilograms", Weight, SystemMetric, 1000, 3},
+	{"oz", "ounce", "ounces", Weight, SystemImperial, 1, 3},
+	{"lb", "pound", "pounds", Weight, SystemImperial, 16, 3},
+	{"mL", "milliliter", "milliliters", Volume, SystemMetric, 1, 3},
+	{"L", "liter", "liters", Volume, SystemMetric, 1000, 3},
+	{"c", "cup", "cups", Volume, SystemImperial, 1, 3},
+	{"pt", "pint", "pints", Volume, SystemImperial, 2, 4},
+	{"qt", "quart", "quarts", Volume, SystemImperial, 4, 4},
+	{"gal", "gallon", "gallons", Volume, SystemImperial, 16, 4},
+	{"sec", "second", "seconds", Time, "", 1, 3},
+	{"min", "minute", "minutes", Time, "", 60, 2},
+	{"hr", "hour", "hours", Time, "", 3600, 1},
+	{"day", "day", "days", Time, "", 86400, 3},
+	{"wk", "week", "weeks", Time, "", 604800, 3},
+}
+
+// convertFromGrade is the first grade where a problem may ask to change
+// units; younger students only add and subtract quantities of one unit.
+const convertFromGrade = 2
+
+// Quantity is an amount of a Unit.
+type Quantity struct {
+	Amount int
+	Unit   Unit
+}
+
+// Base returns q in the smallest unit of its dimension and system.
+func (q Quantity) Base() int { return q.Amount * q.Unit.Factor }
+
+// String formats q with the unit symbol for metric units ("230 cm") and the
+// unit name otherwise ("3 feet").
+func (q Quantity) String() string {
+	if q.Unit.System == SystemMetric {
+		return fmt.Sprintf("%d %s", q.Amount, q.Unit.Symbol)
+	}
+	return q.Named()
+}
+
+// Named formats q with the unit name ("1 meter", "230 centimeters").
+func (q Quantity) Named() string {
+	if q.Amount == 1 {
+		return fmt.Sprintf("%d %s", q.Amount, q.Unit.Singular)
+	}
+	return fmt.Sprintf("%d %s", q.Amount, q.Unit.Plural)
+}
+
+// Measure is one measurement written as one or more quantities, e.g.
+// "2 m 30 cm". All parts share a dimension and system.
+type Measure []Quantity
+
+// Base returns the total of m in the smallest unit.
+func (m Measure) Base() int {
+	total := 0
+	for _, q := range m {
+		total += q.Base()
+	}
+	return total
+}
+
+func (m Measure) String() string {
+	parts := make([]string, len(m))
+	for i, q := range m {
+		parts[i] = q.String()
+	}
+	return strings.Join(parts, " ")
+}
+
+// Named is String with unit names instead of symbols.
+func (m Measure) Named() string {
+	parts := make([]string, len(m))
+	for i, q := range m {
+		parts[i] = q.Named()
+	}
+	return strings.Join(parts, " ")
+}
+
+// InUnit expresses base (in the smallest unit) as a whole number of u.
+func InUnit(base int, u Unit) (Quantity, bool) {
+	if base%u.Factor != 0 {
+		return Quantity{}, false
+	}
+	return Quantity{Amount: base / u.Factor, Unit: u}, true
+}
+
+// Compound splits base into the given units, largest first, dropping zero
+// parts: 2300 mm with [m, cm] gives "2 m 30 cm". ok is false when the
+// smallest unit does not divide base.
+func Compound(base int, units []Unit) (m Measure, ok bool) {
+	us := append([]Unit(nil), units...)
+	sort.Slice(us, func(i, j int) bool { return us[i].Factor > us[j].Factor })
+	for _, u := range us {
+		if n := base / u.Factor; n > 0 {
+			m = append(m, Quantity{Amount: n, Unit: u})
+			base -= n * u.Factor
+		}
+	}
+	if len(m) == 0 && len(us) > 0 {
+		m = Measure{{Amount: 0, Unit: us[len(us)-1]}}
+	}
+	return m, base == 0
+}
+
+// LookupUnit finds a unit by symbol or (singular/plural) name.
+func LookupUnit(s string) (Unit, bool) {
+	u, ok := unitIndex[unitKey(s)]
+	return u, ok
+}
+
+func unitKey(s string) string {
+	s = strings.TrimSuffix(strings.TrimSpace(s), ".")
+	// "mL"/"L" are the only case-sensitive symbols; everything else is
+	// matched case-insensitively.
+	if s == "L" || s == "mL" {
+		return s
+	}
+	return strings.ToLower(s)
+}
+
+var (
+	unitIndex  = map[string]Unit{}
+	reQuantity *regexp.Regexp
+)
+
+func init() {
+	var names []string
+	for _, u := range Units {
+		for _, n := range []string{u.Symbol, u.Singular, u.Plural} {
+			unitIndex[unitKey(n)] = u
+			// "in" and "c" are ordinary words in a story ("5 in the box"),
+			// so in text those units must be spelled out.
+			if n != "in" && n != "c" {
+				names = append(names, regexp.QuoteMeta(n))
+			}
+		}
+	}
+	for alias, sym := range map[string]string{
+		"hrs": "hr", "mins": "min", "secs": "sec", "lbs": "lb",
+		"metre": "m", "metres": "m", "centimetre": "cm", "centimetres": "cm",
+		"kilometre": "km", "kilometres": "km", "millimetre": "mm", "millimetres": "mm",
+		"litre": "L", "litres": "L", "millilitre": "mL", "millilitres": "mL", "ml": "mL", "l": "L",
+	} {
+		unitIndex[alias] = unitIndex[unitKey(sym)]
+		names = append(names, regexp.QuoteMeta(alias))
+	}
+	// Longest names first so "minutes" wins over "min" and "m".
+	sort.Slice(names, func(i, j int) bool { return len(names[i]) > len(names[j]) })
+	reQuantity = regexp.MustCompile(`(?i)\b(\d+)\s*(` + strings.Join(names, "|") + `)\b`)
+}
+
+// reJoin matches what may sit between the parts of one compound measure.
+var reJoin = regexp.MustCompile(`(?i)^\s*(and\s+)?$`)
+
+// ParseMeasures finds every measurement in text, in order. Adjacent
+// quantities of one dimension going from larger to smaller units ("2 m 30
+// cm", "1 hour and 15 minutes") are grouped into a single Measure.
+func ParseMeasures(text string) ([]Measure, error) {
+	var out []Measure
+	prevEnd := -1
+	for _, loc := range reQuantity.FindAllStringSubmatchIndex(text, -1) {
+		amount, err := strconv.Atoi(text[loc[2]:loc[3]])
+		if err != nil {
+			return nil, err
+		}
+		u, ok := LookupUnit(text[loc[4]:loc[5]])
+		if !ok {
+			return nil, fmt.Errorf("unknown unit %q", text[loc[4]:loc[5]])
+		}
+		q := Quantity{Amount: amount, Unit: u}
+		if n := len(out); n > 0 && reJoin.MatchString(text[prevEnd:loc[0]]) {
+			last := out[n-1][len(out[n-1])-1].Unit
+			if last.Dimension == u.Dimension && last.System == u.System && last.Factor > u.Factor {
+				out[n-1] = append(out[n-1], q)
+				prevEnd = loc[1]
+				continue
+			}
+		}
+		out = append(out, Measure{q})
+		prevEnd = loc[1]
+	}
+	return out, nil
+}
+
+// MeasurementPolicy says which units and conversions a grade may use.
+type MeasurementPolicy struct {
+	Grade   int
+	System  string
+	Units   []Unit
+	Convert bool
+}
+
+// PolicyFor returns the measurement policy for a grade level and unit
+// system. An unknown system falls back to metric.
+func PolicyFor(gradeLevel, system string) MeasurementPolicy {
+	system = strings.ToLower(strings.TrimSpace(system))
+	if system != SystemImperial {
+		system = SystemMetric
+	}
+	p := MeasurementPolicy{Grade: GradeNumber(gradeLevel), System: system}
+	p.Convert = p.Grade >= convertFromGrade
+	for _, u := range Units {
+		if u.MinGrade <= p.Grade && (u.System == "" || u.System == system) {
+			p.Units = append(p.Units, u)
+		}
+	}
+	return p
+}
+
+// Allows reports whether u may appear on a worksheet under p.
+func (p MeasurementPolicy) Allows(u Unit) bool {
+	for _, a := range p.Units {
+		if a.Symbol == u.Symbol {
+			return true
+		}
+	}
+	return false
+}
+
+// UnitsOf returns the allowed units of one dimension.
+func (p MeasurementPolicy) UnitsOf(d Dimension) []Unit {
+	var out []Unit
+	for _, u := range p.Units {
+		if u.Dimension == d {
+			out = append(out, u)
+		}
+	}
+	return out
+}
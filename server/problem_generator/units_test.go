@@ -0,0 +1,124 @@
+package problemgenerator
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCompound(t *testing.T) {
+	m, cm, mm := mustUnit(t, "m"), mustUnit(t, "cm"), mustUnit(t, "mm")
+	hr, min := mustUnit(t, "hr"), mustUnit(t, "min")
+	tests := []struct {
+		base  int
+		units []Unit
+		want  string
+		ok    bool
+	}{
+		{2300, []Unit{cm, m}, "2 m 30 cm", true},
+		{2000, []Unit{m, cm}, "2 m", true},
+		{300, []Unit{m, cm}, "30 cm", true},
+		{0, []Unit{m, cm}, "0 cm", true},
+		{2305, []Unit{m, cm}, "2 m 30 cm", false}, // 5 mm left over
+		{2305, []Unit{m, cm, mm}, "2 m 30 cm 5 mm", true},
+		{4500, []Unit{min, hr}, "1 hour 15 minutes", true},
+	}
+	for _, tt := range tests {
+		got, ok := Compound(tt.base, tt.units)
+		if got.String() != tt.want || ok != tt.ok {
+			t.Errorf("Compound(%d) = %q, %v; want %q, %v", tt.base, got, ok, tt.want, tt.ok)
+		}
+		if ok && got.Base() != tt.base {
+			t.Errorf("Compound(%d).Base() = %d", tt.base, got.Base())
+		}
+	}
+}
+
+func TestParseMeasures(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"A rope is 2 m 30 cm long. Ana cuts off 45 cm.", []string{"2 m 30 cm", "45 cm"}},
+		{"The movie lasts 1 hour and 15 minutes.", []string{"1 hour 15 minutes"}},
+		{"Leo ran 3 km, then 2 km.", []string{"3 km", "2 km"}},
+		{"A board is 4 feet long and 6 inches wide.", []string{"4 feet", "6 inches"}},
+		{"She puts 5 in the box.", nil},
+		{"The jug holds 2 L and 500 mL.", []string{"2 L 500 mL"}},
+		{"The fence is 3 metres long.", []string{"3 m"}},
+	}
+	for _, tt := range tests {
+		ms, err := ParseMeasures(tt.text)
+		if err != nil {
+			t.Errorf("ParseMeasures(%q): %v", tt.text, err)
+			continue
+		}
+		var got []string
+		for _, m := range ms {
+			got = append(got, m.String())
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("ParseMeasures(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestPolicyFor(t *testing.T) {
+	tests := []struct {
+		grade, system string
+		unit          string
+		allows        bool
+		convert       bool
+	}{
+		{"K", "metric", "cm", true, false},
+		{"K", "metric", "m", false, false},
+		{"grade 2", "metric", "m", true, true},
+		{"grade 2", "metric", "in", false, true},
+		{"grade 2", "imperial", "ft", true, true},
+		{"grade 2", "klingon", "cm", true, true}, // unknown systems are metric
+		{"grade 3", "imperial", "lb", true, true},
+		{"grade 3", "imperial", "gal", false, true},
+		{"grade 1", "imperial", "hr", true, false},
+	}
+	for _, tt := range tests {
+		p := PolicyFor(tt.grade, tt.system)
+		if got := p.Allows(mustUnit(t, tt.unit)); got != tt.allows {
+			t.Errorf("PolicyFor(%q, %q).Allows(%s) = %v, want %v", tt.grade, tt.system, tt.unit, got, tt.allows)
+		}
+		if p.Convert != tt.convert {
+			t.Errorf("PolicyFor(%q, %q).Convert = %v, want %v", tt.grade, tt.system, p.Convert, tt.convert)
+		}
+	}
+}
+
+func TestSolveMeasurement(t *testing.T) {
+	tests := []struct {
+		text, op, unit, grade, system string
+		answer                        string
+		err                           bool
+	}{
+		{text: "A rope is 2 m 30 cm long. Ana cuts off 45 cm. How long is it now?", op: "sub", grade: "grade 3", answer: "1 m 85 cm"},
+		{text: "Leo walks 3 km and then 2 km. How far?", op: "add", grade: "grade 4", answer: "5 km"},
+		{text: "How many centimeters are in 2 m?", op: "convert", unit: "cm", grade: "grade 2", answer: "200 cm"},
+		{text: "A pencil is 12 cm and a crayon is 8 cm.", op: "add", grade: "K", answer: "20 cm"},
+		{text: "How many centimeters are in 2 m?", op: "convert", unit: "cm", grade: "K", err: true},
+		{text: "A board is 2 feet. Add 5 cm.", op: "add", grade: "grade 3", system: "imperial", err: true},
+		{text: "Ana has 3 cm of ribbon and gives away 5 cm.", op: "sub", grade: "grade 2", err: true},
+		{text: "Ana has 3 apples.", op: "add", grade: "grade 2", err: true},
+		{text: "A path is 4 ft and 1 ft long.", op: "add", grade: "grade 3", system: "imperial", answer: "5 feet"},
+	}
+	for _, tt := range tests {
+		_, answer, _, err := solveMeasurement(tt.text, tt.op, tt.unit, PolicyFor(tt.grade, tt.system))
+		if (err != nil) != tt.err || answer != tt.answer {
+			t.Errorf("solveMeasurement(%q, %s) = %q, %v; want %q, error %v", tt.text, tt.op, answer, err, tt.answer, tt.err)
+		}
+	}
+}
+
+func mustUnit(t *testing.T, s string) Unit {
+	t.Helper()
+	u, ok := LookupUnit(s)
+	if !ok {
+		t.Fatalf("unknown unit %q", s)
+	}
+	return u
+}
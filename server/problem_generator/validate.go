@@ -0,0 +1,315 @@
+package problemgenerator
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Severity says how bad an Issue is. Sets with SeverityError issues are worth
+// regenerating; warnings are shown to the adult but the set is usable.
+type Severity string
+
+const (
+	SeverityInfo    Severity = "info"
+	SeverityWarning Severity = "warning"
+	SeverityError   Severity = "error"
+)
+
+// Issue is one thing a Validator found. Index is the problem it is about, or
+// 0 for the set as a whole.
+type Issue struct {
+	Index    int      `json:"index"`
+	Check    string   `json:"check"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+}
+
+func (i Issue) String() string {
+	if i.Index == 0 {
+		return fmt.Sprintf("%s [%s]: %s", i.Severity, i.Check, i.Message)
+	}
+	return fmt.Sprintf("problem %d %s [%s]: %s", i.Index, i.Severity, i.Check, i.Message)
+}
+
+// HasErrors reports whether any issue is SeverityError.
+func HasErrors(issues []Issue) bool {
+	for _, i := range issues {
+		if i.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Validator checks a ProblemSet and reports what is wrong with it.
+type Validator interface {
+	Name() string
+	Validate(ps *ProblemSet) []Issue
+}
+
+// Chain runs validators in order.
+type Chain []Validator
+
+// DefaultChain is the validation every generated set goes through.
+func DefaultChain() Chain {
+	return Chain{
+		OperationValidator{},
+		RangeValidator{},
+		QuestionValidator{},
+		NameValidator{},
+		ExampleNameValidator{},
+		PronounValidator{},
+		DuplicateValidator{},
+		InterestValidator{},
+		ReadingLevelValidator{},
+		ProgressionValidator{},
+		RegroupingValidator{},
+		SafetyValidator{},
+	}
+}
+
+// Replace swaps the validator with v's name for v, or adds v when there is
+// none.
+func (c Chain) Replace(v Validator) Chain {
+	out := append(Chain(nil), c...)
+	for i, old := range out {
+		if old.Name() == v.Name() {
+			out[i] = v
+			return out
+		}
+	}
+	return append(out, v)
+}
+
+// Validate runs every validator and stores what they found in ps.Issues.
+func (c Chain) Validate(ps *ProblemSet) []Issue {
+	var issues []Issue
+	for _, v := range c {
+		issues = append(issues, v.Validate(ps)...)
+	}
+	ps.Issues = issues
+	return issues
+}
+
+// eachProblem runs check on every problem, tagging what it returns with the
+// problem's index and the check name.
+func eachProblem(ps *ProblemSet, name string, check func(p Problem) (Severity, string)) []Issue {
+	var issues []Issue
+	for _, p := range ps.Problems {
+		if sev, msg := check(p); msg != "" {
+			issues = append(issues, Issue{Index: p.Index, Check: name, Severity: sev, Message: msg})
+		}
+	}
+	return issues
+}
+
+// isStory reports whether p's text was written by the model, as opposed to
+// built by the server from fixed phrasing.
+func isStory(p Problem) bool { return !IsLocalKind(p.Kind) }
+
+// isArithmeticStory reports whether p is "a op b" math told as a story.
+func isArithmeticStory(p Problem) bool {
+	kind := NormalizeKind(p.Kind)
+	return kind == KindArithmetic || kind == KindMissing
+}
+
+// ---------- operation ----------
+
+// opCues are words a story for each operation usually contains.
+var opCues = map[string]*regexp.Regexp{
+	OpAddition:       cueRegexp("altogether", "in all", "total", "more", "together", "combined", "both", "sum"),
+	OpSubtraction:    cueRegexp("left", "fewer", "less", "gave away", "ate", "lost", "remain", "remaining", "difference", "how many more", "away", "still", "gave", "used", "went"),
+	"multiplication": cueRegexp("each", "every", "groups of", "rows", "times", "per", "bags of", "boxes"),
+	"division":       cueRegexp("share", "shared", "equally", "each", "split", "divide", "divided", "per", "evenly", "groups"),
+}
+
+func cueRegexp(cues ...string) *regexp.Regexp {
+	return regexp.MustCompile(`(?i)\b(` + strings.Join(cues, "|") + `)\b`)
+}
+
+// OperationValidator checks that stories use the requested operation, or in
+// a mixed set the operation planned for their slot, and read like it.
+type OperationValidator struct{}
+
+func (OperationValidator) Name() string { return "operation" }
+
+func (v OperationValidator) Validate(ps *ProblemSet) []Issue {
+	return eachProblem(ps, v.Name(), func(p Problem) (Severity, string) {
+		if !isArithmeticStory(p) {
+			return "", ""
+		}
+		want := NormalizeOperation(ps.MetaInfo.Operation)
+		if slot := slotOperation(ps.MetaInfo, PlannedSlot(p)); slot != "" {
+			want = slot
+		}
+		op := NormalizeOperation(p.Operation)
+		if _, ok := opSymbols[op]; !ok {
+			return SeverityError, fmt.Sprintf("unknown operation %q", p.Operation)
+		}
+		if _, ok := opSymbols[want]; ok && op != want {
+			return SeverityError, fmt.Sprintf("is %s, but %s was asked for", op, want)
+		}
+		if !isEnglish(ps.MetaInfo) || opCues[op].MatchString(p.Text) { // the cues are English words
+			return "", ""
+		}
+		for _, other := range []string{OpAddition, OpSubtraction, "multiplication", "division"} {
+			if other != op && opCues[other].MatchString(p.Text) {
+				return SeverityWarning, fmt.Sprintf("reads like %s rather than %s", other, op)
+			}
+		}
+		return SeverityInfo, fmt.Sprintf("has no words that suggest %s", op)
+	})
+}
+
+// ---------- number range ----------
+
+// RangeValidator checks that numbers suit the grade and that answers are
+// whole numbers that are not negative.
+type RangeValidator struct{}
+
+func (RangeValidator) Name() string { return "range" }
+
+func (v RangeValidator) Validate(ps *ProblemSet) []Issue {
+	grade := GradeNumber(ps.MetaInfo.GradeLevel)
+	return eachProblem(ps, v.Name(), func(p Problem) (Severity, string) {
+		if !isArithmeticStory(p) {
+			return "", ""
+		}
+		op := NormalizeOperation(p.Operation)
+		if op == "division" && len(p.Numbers) >= 2 && p.Numbers[1] != 0 && p.Numbers[0]%p.Numbers[1] != 0 {
+			return SeverityError, fmt.Sprintf("%d ÷ %d does not divide evenly", p.Numbers[0], p.Numbers[1])
+		}
+		ans, err := strconv.Atoi(p.Answer)
+		if err != nil {
+			return SeverityError, fmt.Sprintf("answer %q is not a whole number", p.Answer)
+		}
+		if ans < 0 {
+			return SeverityError, fmt.Sprintf("answer %d is negative", ans)
+		}
+		limit := Regrouping(ps.MetaInfo.Regrouping).top(factRange(grade, op))
+		if op == "multiplication" || op == "division" {
+			limit *= limit
+		}
+		for _, n := range append([]int{ans}, p.Numbers...) {
+			if n > limit {
+				return SeverityWarning, fmt.Sprintf("%d is above %d, the largest number for grade %s", n, limit, ps.MetaInfo.GradeLevel)
+			}
+		}
+		return "", ""
+	})
+}
+
+// ---------- question ----------
+
+// QuestionValidator checks that every story ends by asking something.
+type QuestionValidator struct{}
+
+func (QuestionValidator) Name() string { return "question" }
+
+func (v QuestionValidator) Validate(ps *ProblemSet) []Issue {
+	return eachProblem(ps, v.Name(), func(p Problem) (Severity, string) {
+		if isStory(p) && !strings.HasSuffix(strings.TrimSpace(p.Text), "?") {
+			return SeverityWarning, "does not end with a question"
+		}
+		return "", ""
+	})
+}
+
+// ---------- name ----------
+
+// NameValidator checks that stories are about the student.
+type NameValidator struct{}
+
+func (NameValidator) Name() string { return "name" }
+
+func (v NameValidator) Validate(ps *ProblemSet) []Issue {
+	name := strings.ToLower(strings.TrimSpace(ps.MetaInfo.Name))
+	if name == "" {
+		return nil
+	}
+	return eachProblem(ps, v.Name(), func(p Problem) (Severity, string) {
+		if isStory(p) && !strings.Contains(strings.ToLower(p.Text), name) {
+			return SeverityWarning, fmt.Sprintf("does not mention %s", ps.MetaInfo.Name)
+		}
+		return "", ""
+	})
+}
+
+// ---------- duplicates ----------
+
+// DuplicateValidator flags problems that repeat an earlier one, word for
+// word or as the same story with different nouns or the same numbers.
+type DuplicateValidator struct{}
+
+func (DuplicateValidator) Name() string { return "duplicate" }
+
+func (v DuplicateValidator) Validate(ps *ProblemSet) []Issue {
+	var earlier []Problem
+	return eachProblem(ps, v.Name(), func(p Problem) (Severity, string) {
+		d, ok := findDuplicate(p, earlier, nil)
+		earlier = append(earlier, p)
+		if !ok {
+			return "", ""
+		}
+		// Server-built kinds can run out of distinct numbers on small
+		// ranges, where regenerating would not help, and near duplicates
+		// are still different problems to solve.
+		if !isStory(p) || d.Reason != "repeats" {
+			return SeverityWarning, fmt.Sprintf("%s problem %d", d.Reason, d.Of)
+		}
+		return SeverityError, fmt.Sprintf("%s problem %d", d.Reason, d.Of)
+	})
+}
+
+// ---------- reading level ----------
+
+// ReadingLevelValidator flags stories the student could not read alone.
+type ReadingLevelValidator struct{}
+
+func (ReadingLevelValidator) Name() string { return "reading_level" }
+
+func (v ReadingLevelValidator) Validate(ps *ProblemSet) []Issue {
+	if !isEnglish(ps.MetaInfo) {
+		return nil // the word lists and grade formulas are for English
+	}
+	target := ReadingTargetFor(ps.MetaInfo.GradeLevel)
+	familiar := familiarWords(ps.MetaInfo)
+	return eachProblem(ps, v.Name(), func(p Problem) (Severity, string) {
+		if !isStory(p) {
+			return "", ""
+		}
+		if reasons := target.Exceeded(MeasureReadability(p.Text, familiar...)); len(reasons) > 0 {
+			return SeverityWarning, "too hard to read: " + strings.Join(reasons, "; ")
+		}
+		return "", ""
+	})
+}
+
+// ---------- safety ----------
+
+// SafetyValidator rejects stories with violent, hurtful, scary or adult
+// content. A nil Filter means DefaultSafetyFilter.
+type SafetyValidator struct {
+	Filter *SafetyFilter
+}
+
+func (SafetyValidator) Name() string { return "safety" }
+
+func (v SafetyValidator) Validate(ps *ProblemSet) []Issue {
+	filter := v.Filter
+	if filter == nil {
+		filter = DefaultSafetyFilter()
+	}
+	if filter.For(ps.MetaInfo.Language) == nil {
+		return []Issue{{Check: v.Name(), Severity: SeverityWarning,
+			Message: fmt.Sprintf("no safety lists for %s; the stories were not screened", LanguageOf(NormalizeLanguage(ps.MetaInfo.Language)).Name)}}
+	}
+	return eachProblem(ps, v.Name(), func(p Problem) (Severity, string) {
+		if reason := filter.CheckProblem(p, ps.MetaInfo); reason != "" {
+			return SeverityError, reason
+		}
+		return "", ""
+	})
+}
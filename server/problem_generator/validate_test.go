@@ -0,0 +1,82 @@
+package problemgenerator
+
+import "testing"
+
+func TestValidators(t *testing.T) {
+	meta := GenerateRequest{Name: "Ana", Operation: "add", GradeLevel: "grade 1", LikesNouns: []string{"shells"}}
+	story := func(text, answer string, nums ...int) Problem {
+		return Problem{Index: 1, Kind: KindArithmetic, Operation: OpAddition, Text: text, Answer: answer, Numbers: nums}
+	}
+	tests := []struct {
+		name     string
+		v        Validator
+		problems []Problem
+		want     []Severity // one per issue, in order
+	}{
+		{"operation ok", OperationValidator{}, []Problem{story("Ana has 3 shells and finds 4 more. How many in all?", "7", 3, 4)}, nil},
+		{"operation wrong", OperationValidator{}, []Problem{{Index: 1, Kind: KindArithmetic, Operation: OpSubtraction, Text: "Ana has 7 shells and 3 are left. How many?", Answer: "4"}}, []Severity{SeverityError}},
+		{"operation unknown", OperationValidator{}, []Problem{{Index: 1, Kind: KindArithmetic, Operation: "juggle", Answer: "4"}}, []Severity{SeverityError}},
+		{"operation reads like another", OperationValidator{}, []Problem{story("Ana gave away 3 shells and has 4 left. How many?", "7", 3, 4)}, []Severity{SeverityWarning}},
+		{"operation no cue", OperationValidator{}, []Problem{story("Ana sees 3 shells and 4 shells. How many?", "7", 3, 4)}, []Severity{SeverityInfo}},
+		{"range ok", RangeValidator{}, []Problem{story("", "7", 3, 4)}, nil},
+		{"range negative", RangeValidator{}, []Problem{story("", "-2", 3, 5)}, []Severity{SeverityError}},
+		{"range not whole", RangeValidator{}, []Problem{story("", "seven", 3, 4)}, []Severity{SeverityError}},
+		{"range too big", RangeValidator{}, []Problem{story("", "1200", 600, 600)}, []Severity{SeverityWarning}},
+		{"question ok", QuestionValidator{}, []Problem{story("How many shells? ", "7")}, nil},
+		{"question missing", QuestionValidator{}, []Problem{story("Ana has 7 shells.", "7")}, []Severity{SeverityWarning}},
+		{"question skips local kinds", QuestionValidator{}, []Problem{{Index: 1, Kind: KindPattern, Text: "Ana lines up shells."}}, nil},
+		{"name ok", NameValidator{}, []Problem{story("ANA has 7 shells. How many?", "7")}, nil},
+		{"name missing", NameValidator{}, []Problem{story("Leo has 7 shells. How many?", "7")}, []Severity{SeverityWarning}},
+		{"duplicate", DuplicateValidator{}, []Problem{
+			story("Ana has 3 shells and finds 4 more. How many in all?", "7", 3, 4),
+			{Index: 2, Kind: KindArithmetic, Operation: OpAddition, Text: "Ana has 3 shells, and finds 4 more! How many in all?", Answer: "7", Numbers: []int{3, 4}},
+		}, []Severity{SeverityError}},
+		{"reading level ok", ReadingLevelValidator{}, []Problem{story("Ana has 3 shells. She finds 4 more. How many now?", "7")}, nil},
+		{"reading level too hard", ReadingLevelValidator{}, []Problem{story("Ana, an extraordinarily enthusiastic conchologist, meticulously catalogued approximately 3 iridescent specimens, subsequently accumulating 4 additional, comparatively unremarkable specimens; consequently, determine the cumulative quantity?", "7")}, []Severity{SeverityWarning}},
+		{"safety ok", SafetyValidator{}, []Problem{story("Ana has 3 shells and finds 4 more. How many in all?", "7")}, nil},
+		{"safety unsafe word", SafetyValidator{}, []Problem{story("Ana has 3 guns and finds 4 more. How many in all?", "7")}, []Severity{SeverityError}},
+	}
+	for _, tt := range tests {
+		ps := &ProblemSet{Problems: tt.problems, MetaInfo: meta}
+		issues := tt.v.Validate(ps)
+		var got []Severity
+		for _, i := range issues {
+			if i.Check != tt.v.Name() {
+				t.Errorf("%s: issue from check %q, want %q", tt.name, i.Check, tt.v.Name())
+			}
+			got = append(got, i.Severity)
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: issues %v, want severities %v", tt.name, issues, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s: issues %v, want severities %v", tt.name, issues, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestChain(t *testing.T) {
+	ps := &ProblemSet{
+		MetaInfo: GenerateRequest{Name: "Ana", Operation: "add", GradeLevel: "grade 1"},
+		Problems: []Problem{
+			{Index: 1, Kind: KindArithmetic, Operation: OpAddition, Text: "Ana has 3 shells and finds 4 more. How many in all?", Answer: "7", Numbers: []int{3, 4}},
+			{Index: 2, Kind: KindArithmetic, Operation: OpAddition, Text: "Leo has 3 shells and finds 4 more", Answer: "-1", Numbers: []int{3, 4}},
+		},
+	}
+	issues := DefaultChain().Validate(ps)
+	if len(ps.Issues) != len(issues) || !HasErrors(issues) {
+		t.Fatalf("Validate = %v, want the errors stored in the set", issues)
+	}
+	for _, i := range issues {
+		if i.Index != 2 {
+			t.Errorf("unexpected issue %v", i)
+		}
+	}
+	if HasErrors([]Issue{{Severity: SeverityWarning}, {Severity: SeverityInfo}}) {
+		t.Error("HasErrors(warning, info) = true")
+	}
+}
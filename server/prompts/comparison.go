@@ -0,0 +1,71 @@
+package prompts
+
+import (
+	"fmt"
+	"strings"
+
+	pb "github.com/qjs/mathgen_gemma/server/proto"
+)
+
+// comparisonUser builds the user prompt for comparison problems. Every story
+// compares exactly two amounts so the answer can be computed from the text.
+// {friend} is the other character, see friendName.
+func comparisonUser(req *pb.GenerateRequest) string {
+	topics := append([]string{}, req.LikesNouns...)
+	topics = append(topics, req.LikesVerbs...)
+
+	return fmt.Sprintf(`
+Here's the user's information:
+- Name: %s
+- Pronouns: %s
+- Grade Level: %s
+- Preferred Topics: %s
+- Number of Problems: %d
+
+Please generate %d unique comparison word problems for a %s student that incorporate the user's interests.
+Mix these question types and set "operation" to match:
+- "how_many_more": how many more one character has than the other
+- "who_has_more": which character has more
+- "who_has_fewer": which character has fewer
+- "compare": which symbol (<, > or =) goes between two numbers
+
+ **Example Problem Structure:**
+  {
+    "index": 1,
+    "theme": "Dinosaur 🦖",
+    "text": "%s has 12 dinosaur stickers. {friend} has 7 dinosaur stickers. How many more stickers does %s have than {friend}?",
+    "operation": "how_many_more",
+    "names": ["%s", "{friend}"]
+  },
+  {
+    "index": 2,
+    "theme": "Robots 🤖",
+    "text": "{friend} builds 9 robots and %s builds 14 robots. Who builds more robots?",
+    "operation": "who_has_more",
+    "names": ["{friend}", "%s"]
+  },
+  {
+    "index": 3,
+    "theme": "Cookies 🍪",
+    "text": "Compare the cookie jars: 15 __ 18. Which symbol goes in the blank: <, > or =?",
+    "operation": "compare",
+    "names": []
+  }
+ **Remember to:**
+*   Use exactly two numbers in each problem, and write them as digits.
+
+*   Give each character a different name and say each name before that character's number.
+
+*   For "who_has_more" and "who_has_fewer" never give both characters the same amount.
+
+*   List the two names in "names" in the order they appear in the text.
+
+*   Add an emoji of the topic of the question next to the interest.
+
+*   Do not generate markdown blocks, only the JSON array.
+     `,
+		req.Name, pronounsOf(req), req.GradeLevel, strings.Join(topics, ", "), req.NumProblems,
+		req.NumProblems, req.GradeLevel,
+		req.Name, req.Name, req.Name, req.Name, req.Name,
+	)
+}
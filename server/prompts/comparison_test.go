@@ -0,0 +1,31 @@
+package prompts
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	pg "github.com/qjs/mathgen_gemma/server/problem_generator"
+	pb "github.com/qjs/mathgen_gemma/server/proto"
+)
+
+func TestComparisonExampleNames(t *testing.T) {
+	for _, name := range append([]string{"Maya", "", "jordan"}, friendNames...) {
+		req := &pb.GenerateRequest{Name: name, Operation: "comparison", NumProblems: 3, GradeLevel: "2nd"}
+		prompt, err := Builder{Style: StyleComparisonJSON}.Build(req)
+		if err != nil {
+			t.Fatalf("Build(%q): %v", name, err)
+		}
+		if strings.Contains(prompt.User, "{friend}") {
+			t.Errorf("Build(%q) left {friend} in the prompt", name)
+		}
+		for _, ex := range pg.ExampleNames {
+			if regexp.MustCompile(`\b` + ex + `\b`).MatchString(prompt.User) {
+				t.Errorf("Build(%q) uses example name %s", name, ex)
+			}
+		}
+		if friend := friendName(req); strings.EqualFold(friend, exampleName(req)) {
+			t.Errorf("friendName(%q) = %s, the student", name, friend)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package prompts
+
+import (
+	"fmt"
+	"strings"
+
+	pg "github.com/qjs/mathgen_gemma/server/problem_generator"
+)
+
+// Explain builds the prompt that explains to a child how to solve p, along
+// its worked steps, in lang. Like Simplify it must not touch the math: the
+// explanation may only use the numbers already worked out.
+func Explain(p pg.Problem, gradeLevel, lang string) Prompt {
+	l := pg.LanguageOf(pg.NormalizeLanguage(lang))
+	var steps strings.Builder
+	for i, step := range p.Solution.Steps {
+		fmt.Fprintf(&steps, "%d. %s\n", i+1, step)
+	}
+	return Prompt{
+		System: "You are a patient math tutor who explains word problems to young children. You explain the math already worked out, never new math.",
+		User: fmt.Sprintf(`
+Explain to a %s student, in %s (%s), how to solve this word problem:
+
+%s
+
+Worked steps:
+%s
+The answer is %s.
+
+ **Remember to:**
+*   Write 2 or 3 short, warm sentences a parent could read aloud.
+
+*   Follow the worked steps above and end by giving the answer.
+
+*   Use only numbers from the problem and the steps, written as digits, and add no new numbers.
+
+*   Reply with only the explanation: no quotes, no markdown, no notes.
+     `, gradeLevel, l.Name, l.Native, p.Text, steps.String(), p.Answer),
+	}
+}
@@ -0,0 +1,79 @@
+package prompts
+
+import (
+	"fmt"
+	"strings"
+
+	pg "github.com/qjs/mathgen_gemma/server/problem_generator"
+	pb "github.com/qjs/mathgen_gemma/server/proto"
+)
+
+// hybridUser builds the user prompt for hybrid mode. The math is already
+// planned, so the model is only asked to write a story around each plan.
+func hybridUser(req *pb.GenerateRequest, plans []pg.Plan) string {
+	topics := append([]string{}, req.LikesNouns...)
+	topics = append(topics, req.LikesVerbs...)
+
+	var list strings.Builder
+	for _, p := range plans {
+		known := p.Known()
+		fmt.Fprintf(&list, "%d. %s — write the numbers %d and %d in that order; the question asks for %s.\n",
+			p.Index, p.Equation(), known[0], known[1], askFor(p))
+	}
+
+	return fmt.Sprintf(`
+Here's the user's information:
+- Name: %s
+- Pronouns: %s
+- Grade Level: %s
+- Preferred Topics: %s
+
+The math for each problem is already decided. Write one short word problem for a %s student around each equation below, where "?" is the number the student finds:
+
+%s
+ **Example Problem Structure:**
+  {
+    "index": 1,
+    "theme": "Dinosaur 🦖",
+    "text": "%s sees 12 Stegosauruses and 9 Brachiosauruses. How many dinosaurs does %s see in all?"
+  }
+ **Remember to:**
+*   Write one story per equation, with the same index.
+
+*   Use exactly the two numbers given for each story, written as digits, and no other numbers (no ages, times or prices).
+
+*   Never write the answer in the story.
+
+*   Incorporate the user's interests naturally and keep the tone positive.
+
+*   Add an emoji of the topic of the question next to the interest.
+
+*   Do not generate markdown blocks, only the JSON array.
+     `,
+		req.Name, pronounsOf(req), req.GradeLevel, strings.Join(topics, ", "),
+		req.GradeLevel, list.String(),
+		req.Name, req.Name,
+	)
+}
+
+// askFor says in words which part of the plan the question is about.
+func askFor(p pg.Plan) string {
+	switch p.Unknown {
+	case pg.SlotA:
+		return "the amount at the start"
+	case pg.SlotB:
+		if p.Operation == pg.OpAddition || p.Operation == pg.OpSubtraction {
+			return "how many were added or taken away"
+		}
+		return "the size or number of the groups"
+	}
+	switch p.Operation {
+	case pg.OpAddition:
+		return "the total"
+	case pg.OpSubtraction:
+		return "how many are left"
+	case "multiplication":
+		return "how many there are in all"
+	}
+	return "how many are in each group"
+}
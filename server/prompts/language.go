@@ -0,0 +1,43 @@
+package prompts
+
+import (
+	"fmt"
+	"strings"
+
+	pg "github.com/qjs/mathgen_gemma/server/problem_generator"
+	pb "github.com/qjs/mathgen_gemma/server/proto"
+)
+
+// languageExamples are two stories in each language other than English, for
+// the model to copy the tone and number style of. {name} is the student.
+var languageExamples = map[string][]string{
+	"es": {
+		`{"index": 1, "theme": "Dinosaurios 🦖", "text": "{name} explora una selva llena de dinosaurios. Ve 12 estegosaurios y 9 braquiosaurios. ¿Cuántos dinosaurios ve en total?", "operation": "addition"}`,
+		`{"index": 2, "theme": "Espacio 🚀", "text": "{name} tiene 15 pegatinas de estrellas y regala 6 a su amiga. ¿Cuántas pegatinas le quedan?", "operation": "subtraction"}`,
+	},
+	"fr": {
+		`{"index": 1, "theme": "Dinosaures 🦖", "text": "{name} explore une forêt pleine de dinosaures. Il y a 12 stégosaures et 9 brachiosaures. Combien de dinosaures y a-t-il en tout ?", "operation": "addition"}`,
+		`{"index": 2, "theme": "Espace 🚀", "text": "{name} a 15 autocollants d'étoiles et en donne 6 à une amie. Combien d'autocollants reste-t-il ?", "operation": "subtraction"}`,
+	},
+	"vi": {
+		`{"index": 1, "theme": "Khủng long 🦖", "text": "{name} đi khám phá một khu rừng đầy khủng long. Có 12 con khủng long gai và 9 con khủng long cổ dài. Có tất cả bao nhiêu con khủng long?", "operation": "addition"}`,
+		`{"index": 2, "theme": "Vũ trụ 🚀", "text": "{name} có 15 hình dán ngôi sao và tặng bạn 6 hình. Hỏi còn lại bao nhiêu hình dán?", "operation": "subtraction"}`,
+	},
+}
+
+// languageSection tells the model to write the stories in the requested
+// language, or nothing for English, which the prompts are written in.
+func languageSection(req *pb.GenerateRequest) string {
+	lang := pg.LanguageOf(pg.NormalizeLanguage(req.Language))
+	if lang.Tag == "en" {
+		return ""
+	}
+	var s strings.Builder
+	fmt.Fprintf(&s, "\n **Language:** write every \"theme\" and \"text\" in %s (%s), for a child who reads %s.\n", lang.Name, lang.Native, lang.Name)
+	s.WriteString("Keep the JSON keys, and the values of \"operation\", \"unit\" and \"unknown\", in English exactly as above. Write every number in digits without thousands separators, never in words.\n")
+	fmt.Fprintf(&s, "\nExamples in %s:\n", lang.Name)
+	for _, ex := range languageExamples[lang.Tag] {
+		s.WriteString(ex + "\n")
+	}
+	return s.String()
+}
@@ -0,0 +1,110 @@
+package prompts
+
+import (
+	"fmt"
+	"strings"
+
+	pg "github.com/qjs/mathgen_gemma/server/problem_generator"
+	pb "github.com/qjs/mathgen_gemma/server/proto"
+)
+
+// measurementUser builds the user prompt for measurement problems. The unit
+// list comes from the grade's MeasurementPolicy so the model only sees units
+// the parser will accept.
+func measurementUser(req *pb.GenerateRequest) string {
+	policy := pg.PolicyFor(req.GradeLevel, req.UnitSystem)
+
+	var units []string
+	for _, d := range []pg.Dimension{pg.Length, pg.Weight, pg.Volume, pg.Time} {
+		var names []string
+		for _, u := range policy.UnitsOf(d) {
+			names = append(names, u.Plural)
+		}
+		if len(names) > 0 {
+			units = append(units, fmt.Sprintf("- %s: %s", d, strings.Join(names, ", ")))
+		}
+	}
+
+	ops := "addition or subtraction of two measurements that use the same unit"
+	opNames := `"addition" or "subtraction"`
+	if policy.Convert {
+		ops = "converting one measurement to another unit, or addition or subtraction of two measurements"
+		opNames = `"convert", "addition" or "subtraction"`
+	}
+	if op := pg.NormalizeOperation(req.Operation); op == pg.OpAddition || op == pg.OpSubtraction || op == pg.OpConvert {
+		ops = "mostly " + op + "; " + ops
+	}
+
+	// The examples follow the policy so they never show a unit or a
+	// conversion the parser would reject. Every grade has cm or inches.
+	base := policy.UnitsOf(pg.Length)[0].Plural
+	long, short := "meter", "centimeters"
+	if policy.System == pg.SystemImperial {
+		long, short = "foot", "inches"
+	}
+	example := fmt.Sprintf(`"text": "%s builds a robot arm that is 1 %s 20 %s long. How many %s long is the arm?",
+    "operation": "convert",
+    "unit": "%s"`, req.Name, long, short, short, short)
+	if !policy.Convert {
+		example = fmt.Sprintf(`"text": "%s builds a robot arm that is 12 %s long and adds a claw that is 5 %s long. How many %s long is the arm now?",
+    "operation": "addition",
+    "unit": "%s"`, req.Name, base, base, base, base)
+	}
+
+	second := fmt.Sprintf(`"text": "%s lines up cookies in a row 15 %s long and then a second row 20 %s long. How long are both rows together?",
+    "operation": "addition",
+    "unit": "%s"`, req.Name, base, base, base)
+	for _, u := range policy.UnitsOf(pg.Time) {
+		if u.Symbol == "min" {
+			second = fmt.Sprintf(`"text": "%s bakes cookies for 15 minutes and then decorates them for 20 minutes. How many minutes does %s spend in all?",
+    "operation": "addition",
+    "unit": "minutes"`, req.Name, req.Name)
+		}
+	}
+
+	topics := append([]string{}, req.LikesNouns...)
+	topics = append(topics, req.LikesVerbs...)
+
+	return fmt.Sprintf(`
+Here's the user's information:
+- Name: %s
+- Pronouns: %s
+- Grade Level: %s
+- Preferred Topics: %s
+- Number of Problems: %d
+
+Please generate %d unique measurement word problems for a %s student that incorporate the user's interests.
+The problems should be about %s.
+
+Only use these units (%s), always written out as words next to their number:
+%s
+
+ **Example Problem Structure:**
+  {
+    "index": 1,
+    "theme": "Robots 🤖",
+    %s
+  },
+  {
+    "index": 2,
+    "theme": "Cookies 🍪",
+    %s
+  }
+ **Remember to:**
+*   Write every measurement as a number followed by its unit, like "3 meters" or "2 hours 15 minutes".
+
+*   Only mention the measurements needed to solve the problem.
+
+*   Set "operation" to %s.
+
+*   Set "unit" to the unit the question asks for.
+
+*   Add an emoji of the topic of the question next to the interest.
+
+*   Do not generate markdown blocks, only the JSON array.
+     `,
+		req.Name, pronounsOf(req), req.GradeLevel, strings.Join(topics, ", "), req.NumProblems,
+		req.NumProblems, req.GradeLevel, ops, policy.System, strings.Join(units, "\n"),
+		example, second, opNames,
+	)
+}
@@ -0,0 +1,71 @@
+package prompts
+
+import (
+	"fmt"
+	"strings"
+
+	pg "github.com/qjs/mathgen_gemma/server/problem_generator"
+	pb "github.com/qjs/mathgen_gemma/server/proto"
+)
+
+// missingNumberUser builds the user prompt for missing-number stories, where
+// the unknown can be the starting amount, the change or the result.
+func missingNumberUser(req *pb.GenerateRequest) string {
+	topics := append([]string{}, req.LikesNouns...)
+	topics = append(topics, req.LikesVerbs...)
+	op := strings.ToLower(req.Operation)
+	symbol, only := pg.OpSymbol(op), "Only use the "+op+" operation."
+	if op == pg.OpMixed {
+		symbol, only = "op", "Use the operation listed for each problem below."
+	}
+
+	return fmt.Sprintf(`
+Here's the user's information:
+- Name: %s
+- Pronouns: %s
+- Grade Level: %s
+- Preferred Topics: %s
+- Math Operation: %s
+- Number of Problems: %d
+
+Please generate %d unique "missing number" word %s problems for a %s student that incorporate the user's interests.
+Each story is a number sentence "start %s change = result" with one part unknown. Set "unknown" to:
+- "start" when the story does not say how many there were at the beginning
+- "change" when the story does not say how many were added, taken away or grouped
+- "result" when the story asks how many there are at the end
+
+ **Example Problem Structure:**
+  {
+    "index": 1,
+    "theme": "Seashells 🐚",
+    "text": "%s had some seashells. %s found 4 more and now has 11 seashells. How many seashells did %s have at the start?",
+    "operation": "addition",
+    "unknown": "start"
+  },
+  {
+    "index": 2,
+    "theme": "Robots 🤖",
+    "text": "%s had 9 robot parts. After using some, %s has 3 left. How many parts did %s use?",
+    "operation": "subtraction",
+    "unknown": "change"
+  }
+ **Remember to:**
+*   Mix all three kinds of unknown, with mostly "start" and "change".
+
+*   Write exactly the two known numbers as digits, in the order start, change, result, leaving out the unknown one.
+
+*   Use words like "some" for the unknown amount, never a number.
+
+*   %s
+
+*   Add an emoji of the topic of the question next to the interest.
+
+*   Do not generate markdown blocks, only the JSON array.
+     `,
+		req.Name, pronounsOf(req), req.GradeLevel, strings.Join(topics, ", "), req.Operation, req.NumProblems,
+		req.NumProblems, op, req.GradeLevel, symbol,
+		req.Name, req.Name, req.Name,
+		req.Name, req.Name, req.Name,
+		only,
+	)
+}
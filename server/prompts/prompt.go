@@ -2,8 +2,11 @@ package prompts
 
 import (
 	"fmt"
+	"hash/fnv"
+	"strconv"
 	"strings"
 
+	pg "github.com/qjs/mathgen_gemma/server/problem_generator"
 	pb "github.com/qjs/mathgen_gemma/server/proto"
 )
 
@@ -29,6 +32,10 @@ const (
 	StyleVerbose
 	StyleProblemset
 	StyleProblemsetJSON
+	StyleMeasurementJSON
+	StyleComparisonJSON
+	StyleMissingNumberJSON
+	StyleHybridJSON
 )
 
 // Builder holds configuration for generating prompts.
@@ -36,6 +43,14 @@ const (
 type Builder struct {
 	Style Style
 	Model string
+	Plans []pg.Plan // StyleHybridJSON: the math each story is written around
+
+	Interests []string // the interest each problem is about, in order; see pg.AssignInterests
+
+	// Only asks for just these problems of the set, as when some of an
+	// earlier reply could not be read; Written are the texts kept from it.
+	Only    []int
+	Written []string
 }
 
 type Prompt struct {
@@ -63,7 +78,7 @@ func (b Builder) Build(req *pb.GenerateRequest) (Prompt, error) {
 		prompt.User = fmt.Sprintf(`
 Here's the user's information:
 - Name: %s
-- Gender: %s
+- Pronouns: %s
 - Grade Level: %s
 - Preferred Topics: %s
 - Math Operation: %s
@@ -72,9 +87,9 @@ Here's the user's information:
 Please generate %d unique word %s problems that incorporate elements from the user's interests and are solvable using the specified math operation. The problems should be written in clear, engaging language suitable for a %s student. 
 
  **Example Problem Structure (Please aim for similar complexity and style):** **Scenario:** [briefly describe a scenario related to the user's interests] **Problem:** [state the math problem clearly] 
- **"Problem 1","Dinosaur 🦖","Imagine Amelia is exploring a land filled with dino-sauruses! She sees 12 Stegosauruses and 9 Brachiosauruses. How many dinosaurs does Amelia see in all?",”addition”,9,12** 
- **"Problem 2","Space 🚀","Amelia is counting stars in the night sky. She spots 17 blue stars and 6 yellow stars. What is the total number of stars Amelia counts?",”addition”,17,6** 
- **"Problem 3","Unicorn 🦄","Princess Amelia has 11 sparkling unicorn charms and 7 rainbow unicorn stickers. How many unicorn goodies does she have altogether?",”addition”,11,7** 
+ **"Problem 1","Dinosaur 🦖","Imagine {name} is exploring a land filled with dino-sauruses! {name} sees 12 Stegosauruses and 9 Brachiosauruses. How many dinosaurs does {name} see in all?",”addition”,9,12** 
+ **"Problem 2","Space 🚀","{name} is counting stars in the night sky. {name} spots 17 blue stars and 6 yellow stars. What is the total number of stars {name} counts?",”addition”,17,6** 
+ **"Problem 3","Unicorn 🦄","{name} has 11 sparkling unicorn charms and 7 rainbow unicorn stickers. How many unicorn goodies does {name} have altogether?",”addition”,11,7** 
  **"Problem 4","Volcano 🌋","At the volcano, there are 15 red rocks and 8 black rocks. How many rocks are there in total around the volcano?",”addition”,15,8** 
  **Remember to:**
 *   Vary the scenarios and the specific numbers used in the problems.
@@ -100,7 +115,7 @@ Please generate %d unique word %s problems that incorporate elements from the us
 *   If the operation is Subtraction or Division ensure that num1, num2 are in the order of the operation (avoid illogical operations based on the problem text
 *   Do not generate csv markdown blocks, only the contents of the csv
      `,
-			req.Name, req.Gender, req.GradeLevel, topicsLine, req.Operation, req.NumProblems,
+			req.Name, pronounsOf(req), req.GradeLevel, topicsLine, req.Operation, req.NumProblems,
 			req.NumProblems, strings.ToLower(req.Operation), req.GradeLevel,
 		)
 
@@ -113,7 +128,7 @@ Please generate %d unique word %s problems that incorporate elements from the us
 		prompt.User = fmt.Sprintf(`
 Here's the user's information:
 - Name: %s
-- Gender: %s
+- Pronouns: %s
 - Grade Level: %s
 - Preferred Topics: %s
 - Math Operation: %s
@@ -125,19 +140,19 @@ Please generate %d unique word %s problems that incorporate elements from the us
   {
     "index": 1,
     "theme": "Dinosaur 🦖",
-    "Text": "Imagine Amelia is exploring a land filled with dino-sauruses! She sees %%d Stegosauruses and %%d Brachiosauruses. How many dinosaurs does Amelia see in all?",
+    "Text": "Imagine {name} is exploring a land filled with dino-sauruses! {name} sees %%d Stegosauruses and %%d Brachiosauruses. How many dinosaurs does {name} see in all?",
     "operation": "addition",
   },
   {
     "index": 2,
     "theme": "Space 🚀",
-    "text": "Amelia is counting stars in the night sky. She spots %%d blue stars and %%d yellow stars. What is the total number of stars Amelia counts?",
+    "text": "{name} is counting stars in the night sky. {name} spots %%d blue stars and %%d yellow stars. What is the total number of stars {name} counts?",
     "operation": "addition",
   },
   {
     "index": 3,
     "theme": "Unicorn 🦄",
-    "text": "Princess Amelia has %%d sparkling unicorn charms and %%d rainbow unicorn stickers. How many unicorn goodies does she have altogether?",
+    "text": "{name} has %%d sparkling unicorn charms and %%d rainbow unicorn stickers. How many unicorn goodies does {name} have altogether?",
     "operation": "addition",
 
   },
@@ -170,9 +185,20 @@ Please generate %d unique word %s problems that incorporate elements from the us
 
 *   The problem should be consistent with the requested operation. If the operation is division it ONLY should be division. If Operation is Subtraction ONLY subtraction. If Operation is Multiplication ONLY multiplication. 
      `,
-			req.Name, req.Gender, req.GradeLevel, topicsLine, req.Operation, req.NumProblems,
+			req.Name, pronounsOf(req), req.GradeLevel, topicsLine, req.Operation, req.NumProblems,
 			req.NumProblems, strings.ToLower(req.Operation), req.GradeLevel,
 		)
+	case StyleMeasurementJSON:
+		prompt.User = measurementUser(req)
+	case StyleComparisonJSON:
+		prompt.User = comparisonUser(req)
+	case StyleMissingNumberJSON:
+		prompt.User = missingNumberUser(req)
+	case StyleHybridJSON:
+		if len(b.Plans) == 0 {
+			return prompt, fmt.Errorf("hybrid prompt needs problem plans")
+		}
+		prompt.User = hybridUser(req, b.Plans)
 	case StyleVerbose:
 		fallthrough // default falls back to verbose
 
@@ -182,7 +208,161 @@ Please generate %d unique word %s problems that incorporate elements from the us
 			req.NumProblems, req.Operation, req.GradeLevel, req.LikesNouns, req.LikesVerbs,
 		)
 	}
+	prompt.User += languageSection(req)
+	prompt.User = strings.ReplaceAll(prompt.User, "{name}", exampleName(req))
+	prompt.User = strings.ReplaceAll(prompt.User, "{friend}", friendName(req))
+	prompt.User += pronounRule(req)
+	prompt.User += interestSlots(b.Interests)
+	if b.Style != StyleHybridJSON { // hybrid plans carry their own operations and numbers
+		ops, err := pg.OperationSlots(req)
+		if err != nil {
+			return prompt, err
+		}
+		if len(req.Operations) > 0 {
+			prompt.User += operationSlots(ops)
+		}
+		if kind := pg.NormalizeKind(req.Kind); kind == pg.KindArithmetic || kind == pg.KindMissing {
+			prompt.User += difficultySlots(ops, req)
+			prompt.User += regroupingRule(ops, req)
+		}
+	}
+	prompt.User += onlySection(b.Only, b.Written)
 	return prompt, nil
 }
 
+// pronounsOf is the student's pronouns, as the prompts spell them.
+func pronounsOf(req *pb.GenerateRequest) string {
+	return pg.NormalizePronouns(req.Pronouns, req.Gender)
+}
+
+// exampleName is the name the prompt examples use: the student's own, so
+// the model has no other name to copy.
+func exampleName(req *pb.GenerateRequest) string {
+	if name := strings.TrimSpace(req.Name); name != "" {
+		return name
+	}
+	return "Alex"
+}
+
+// friendNames are the names the examples give a second character. None is
+// in pg.ExampleNames, so Personalize never takes one for the student.
+var friendNames = []string{"Jordan", "Priya", "Mateo", "Keiko", "Omar", "Lena"}
+
+// friendName picks the examples' second character for req: one of
+// friendNames, chosen by the student's name so different students see
+// different friends, and never the student's own name.
+func friendName(req *pb.GenerateRequest) string {
+	h := fnv.New32a()
+	h.Write([]byte(strings.ToLower(strings.TrimSpace(req.Name))))
+	i := int(h.Sum32() % uint32(len(friendNames)))
+	if strings.EqualFold(friendNames[i], exampleName(req)) {
+		i = (i + 1) % len(friendNames)
+	}
+	return friendNames[i]
+}
+
+// pronounRule tells the model which pronouns to use for the student.
+func pronounRule(req *pb.GenerateRequest) string {
+	if strings.TrimSpace(req.Name) == "" {
+		return ""
+	}
+	p := pg.PronounsFor(req.Pronouns, req.Gender)
+	return fmt.Sprintf("\n **Pronouns:** when a problem refers to %s without the name, use %s/%s/%s only.\n",
+		req.Name, p.Subject, p.Object, p.Possessive)
+}
+
+// interestSlots tells the model which interest each problem is about, so
+// the set does not use the first interest for everything.
+func interestSlots(interests []string) string {
+	if len(interests) == 0 {
+		return ""
+	}
+	var list strings.Builder
+	list.WriteString("\n **Interest for each problem:**\n")
+	for i, interest := range interests {
+		fmt.Fprintf(&list, "%d. %s\n", i+1, interest)
+	}
+	list.WriteString("\nWrite each problem about its interest above and name the interest in the problem text, so every interest gets its turn.\n")
+	return list.String()
+}
+
+// operationSlots tells the model the operation of each problem of a mixed
+// set.
+func operationSlots(ops []string) string {
+	var list strings.Builder
+	list.WriteString("\n **Operation for each problem:**\n")
+	for i, op := range ops {
+		fmt.Fprintf(&list, "%d. %s\n", i+1, op)
+	}
+	list.WriteString("\nThis is a mixed set: write each problem for its operation above, and set \"operation\" to it.\n")
+	return list.String()
+}
+
+// difficultySlots tells the model how big the numbers of each problem
+// should be, so the set follows the requested progression.
+func difficultySlots(ops []string, req *pb.GenerateRequest) string {
+	levels := pg.DifficultyLevels(len(ops), req.Progression)
+	if levels == nil {
+		return ""
+	}
+	grade := pg.GradeNumber(req.GradeLevel)
+	var list strings.Builder
+	list.WriteString("\n **Difficulty for each problem:**\n")
+	for i, level := range levels {
+		lo, hi := pg.LevelRange(ops[i], grade, level)
+		switch level {
+		case 1:
+			fmt.Fprintf(&list, "%d. warm-up: numbers up to %d, no carrying or borrowing\n", i+1, hi)
+		case 2:
+			fmt.Fprintf(&list, "%d. medium: numbers from %d to %d\n", i+1, lo, hi)
+		default:
+			fmt.Fprintf(&list, "%d. challenge: numbers from %d to %d, carrying or borrowing welcome\n", i+1, lo, hi)
+		}
+	}
+	list.WriteString("\nKeep each problem's numbers in its range above.\n")
+	return list.String()
+}
+
+// regroupingRule tells the model which problems may carry or borrow.
+func regroupingRule(ops []string, req *pb.GenerateRequest) string {
+	rule, err := pg.ParseRegrouping(req.Regrouping)
+	if err != nil || rule == "" {
+		return ""
+	}
+	var list strings.Builder
+	list.WriteString("\n **Carrying and borrowing:**\n")
+	for i, op := range ops {
+		if op == pg.OpAddition || op == pg.OpSubtraction {
+			fmt.Fprintf(&list, "%d. %s\n", i+1, rule.ForSlot(i+1).Describe(op))
+		}
+	}
+	list.WriteString("\nPick each problem's numbers so the column math works out this way.\n")
+	return list.String()
+}
+
+// onlySection asks for just the problems with the given indexes, and shows
+// the ones already written so they are not repeated.
+func onlySection(only []int, written []string) string {
+	if len(only) == 0 {
+		return ""
+	}
+	nums := make([]string, len(only))
+	for i, n := range only {
+		nums[i] = strconv.Itoa(n)
+	}
+	which := "problem " + nums[0]
+	if len(nums) > 1 {
+		which = "problems " + strings.Join(nums, ", ")
+	}
+	var s strings.Builder
+	fmt.Fprintf(&s, "\n **Only these problems:** write %s only, each with its own index, following everything above for it. Leave out every other problem.\n", which)
+	if len(written) > 0 {
+		s.WriteString("\nThese problems are already written. Do not repeat their stories or numbers:\n")
+		for _, text := range written {
+			fmt.Fprintf(&s, "- %s\n", text)
+		}
+	}
+	return s.String()
+}
+
 // NewBuilder creates a new prompt builder with the specified style.
@@ -0,0 +1,20 @@
+package prompts
+
+import "fmt"
+
+// SafetyCheck builds the prompt that asks the local model whether a problem
+// is fit for a child's worksheet. pg.ParseSafetyVerdict reads the reply.
+func SafetyCheck(text, gradeLevel string) Prompt {
+	return Prompt{
+		System: "You review math word problems before they are printed on a young child's worksheet. You are careful but not fussy: ordinary play, animals, food, sports and make-believe are fine.",
+		User: fmt.Sprintf(`
+Is this word problem right for a %s student?
+
+%s
+
+Answer UNSAFE if it has violence, weapons, injury, death, scary or upsetting situations, danger to a child, unkind words, adult topics, or anything a parent would not want their child to read. Otherwise answer SAFE.
+
+Reply with one line: SAFE, or UNSAFE: followed by a short reason.
+     `, gradeLevel, text),
+	}
+}
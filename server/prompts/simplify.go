@@ -0,0 +1,34 @@
+package prompts
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Simplify builds the prompt that rewrites one story so a student in
+// gradeLevel can read it alone. The numbers and the question must not
+// change, since the answer key is already worked out; hard lists the words
+// the student is not expected to know.
+func Simplify(text, gradeLevel string, hard []string) Prompt {
+	avoid := ""
+	if len(hard) > 0 {
+		avoid = fmt.Sprintf("\n*   Replace these words with easier ones: %s.\n", strings.Join(hard, ", "))
+	}
+	return Prompt{
+		System: "You rewrite math word problems so young children can read them on their own. You only change the wording, never the math.",
+		User: fmt.Sprintf(`
+Rewrite this word problem so a %s student can read it alone:
+
+%s
+
+ **Remember to:**
+*   Use short sentences and simple, everyday words.
+%s
+*   Keep every number exactly as written, as digits, in the same order, and add no new numbers.
+
+*   Keep the same people, things and question, and end with the question.
+
+*   Reply with only the new problem text: no quotes, no markdown, no notes.
+     `, gradeLevel, text, avoid),
+	}
+}
@@ -0,0 +1,33 @@
+package prompts
+
+import (
+	"fmt"
+
+	pg "github.com/qjs/mathgen_gemma/server/problem_generator"
+)
+
+// Translate builds the prompt that translates one problem's theme and text
+// from one language to another. Like Simplify it must not touch the math:
+// the numbers, the people and the question stay as they are.
+func Translate(p pg.Problem, from, to string) Prompt {
+	src, dst := pg.LanguageOf(pg.NormalizeLanguage(from)), pg.LanguageOf(pg.NormalizeLanguage(to))
+	return Prompt{
+		System: "You translate math word problems for children between languages. You only change the language, never the math.",
+		User: fmt.Sprintf(`
+Translate this %s math problem into %s (%s):
+
+{"theme": %q, "text": %q}
+
+ **Remember to:**
+*   Keep every number exactly as written, as digits without thousands separators, in the same order, and add no new numbers.
+
+*   Keep the names of people as they are, the same things happening and the same question, and end with the question.
+
+*   Translate the theme into a word or two and keep its emoji.
+
+*   Write simple words a child who reads %s can read.
+
+*   Reply with only JSON: {"theme": "...", "text": "..."}, no markdown, no notes.
+     `, src.Name, dst.Name, dst.Native, p.Theme, p.Text, dst.Name),
+	}
+}
@@ -28,13 +28,31 @@ type GenerateRequest struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Name        string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	Gender      string   `protobuf:"bytes,2,opt,name=gender,proto3" json:"gender,omitempty"`
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Deprecated: Marked as deprecated in server/proto/problem_gen.proto.
+	Gender      string   `protobuf:"bytes,2,opt,name=gender,proto3" json:"gender,omitempty"` // use pronouns
 	Operation   string   `protobuf:"bytes,3,opt,name=operation,proto3" json:"operation,omitempty"`
 	NumProblems int32    `protobuf:"varint,4,opt,name=num_problems,json=numProblems,proto3" json:"num_problems,omitempty"`
 	GradeLevel  string   `protobuf:"bytes,5,opt,name=grade_level,json=gradeLevel,proto3" json:"grade_level,omitempty"`
 	LikesNouns  []string `protobuf:"bytes,6,rep,name=likes_nouns,json=likesNouns,proto3" json:"likes_nouns,omitempty"`
 	LikesVerbs  []string `protobuf:"bytes,7,rep,name=likes_verbs,json=likesVerbs,proto3" json:"likes_verbs,omitempty"`
+	// arithmetic | measurement | comparison | missing_number | equation |
+	// pattern | chart | place_value | rounding | estimation
+	Kind            string `protobuf:"bytes,8,opt,name=kind,proto3" json:"kind,omitempty"`
+	UnitSystem      string `protobuf:"bytes,9,opt,name=unit_system,json=unitSystem,proto3" json:"unit_system,omitempty"`                 // metric | imperial
+	InterestBalance string `protobuf:"bytes,10,opt,name=interest_balance,json=interestBalance,proto3" json:"interest_balance,omitempty"` // round_robin | weighted
+	Pronouns        string `protobuf:"bytes,11,opt,name=pronouns,proto3" json:"pronouns,omitempty"`                                      // she/her | he/him | they/them
+	// Mixed sets: several operations with their share of the problems.
+	// When given, operation is "mixed" and, for counts, num_problems is
+	// their total.
+	Operations  []*OperationShare `protobuf:"bytes,12,rep,name=operations,proto3" json:"operations,omitempty"`
+	Arrange     string            `protobuf:"bytes,13,opt,name=arrange,proto3" json:"arrange,omitempty"`         // interleaved | grouped | shuffled
+	Progression string            `protobuf:"bytes,14,opt,name=progression,proto3" json:"progression,omitempty"` // ramp | uniform | spiral; empty leaves difficulty to chance
+	// Carrying and borrowing in addition and subtraction: none | some | all,
+	// or the places that must regroup, such as "tens" or "ones,tens".
+	Regrouping string `protobuf:"bytes,15,opt,name=regrouping,proto3" json:"regrouping,omitempty"`
+	Language   string `protobuf:"bytes,16,opt,name=language,proto3" json:"language,omitempty"` // en | es | fr | vi, or a tag such as es-MX; empty is English
+	Agent      string `protobuf:"bytes,17,opt,name=agent,proto3" json:"agent,omitempty"`       // json | csv | templates; empty is the server's -agent
 }
 
 func (x *GenerateRequest) Reset() {
@@ -74,6 +92,7 @@ func (x *GenerateRequest) GetName() string {
 	return ""
 }
 
+// Deprecated: Marked as deprecated in server/proto/problem_gen.proto.
 func (x *GenerateRequest) GetGender() string {
 	if x != nil {
 		return x.Gender
@@ -116,23 +135,171 @@ func (x *GenerateRequest) GetLikesVerbs() []string {
 	return nil
 }
 
+func (x *GenerateRequest) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *GenerateRequest) GetUnitSystem() string {
+	if x != nil {
+		return x.UnitSystem
+	}
+	return ""
+}
+
+func (x *GenerateRequest) GetInterestBalance() string {
+	if x != nil {
+		return x.InterestBalance
+	}
+	return ""
+}
+
+func (x *GenerateRequest) GetPronouns() string {
+	if x != nil {
+		return x.Pronouns
+	}
+	return ""
+}
+
+func (x *GenerateRequest) GetOperations() []*OperationShare {
+	if x != nil {
+		return x.Operations
+	}
+	return nil
+}
+
+func (x *GenerateRequest) GetArrange() string {
+	if x != nil {
+		return x.Arrange
+	}
+	return ""
+}
+
+func (x *GenerateRequest) GetProgression() string {
+	if x != nil {
+		return x.Progression
+	}
+	return ""
+}
+
+func (x *GenerateRequest) GetRegrouping() string {
+	if x != nil {
+		return x.Regrouping
+	}
+	return ""
+}
+
+func (x *GenerateRequest) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *GenerateRequest) GetAgent() string {
+	if x != nil {
+		return x.Agent
+	}
+	return ""
+}
+
+// How much of a mixed set uses one operation: a count or a percentage.
+type OperationShare struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Operation string `protobuf:"bytes,1,opt,name=operation,proto3" json:"operation,omitempty"`
+	Count     int32  `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	Percent   int32  `protobuf:"varint,3,opt,name=percent,proto3" json:"percent,omitempty"`
+}
+
+func (x *OperationShare) Reset() {
+	*x = OperationShare{}
+	mi := &file_server_proto_problem_gen_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OperationShare) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OperationShare) ProtoMessage() {}
+
+func (x *OperationShare) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_problem_gen_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OperationShare.ProtoReflect.Descriptor instead.
+func (*OperationShare) Descriptor() ([]byte, []int) {
+	return file_server_proto_problem_gen_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *OperationShare) GetOperation() string {
+	if x != nil {
+		return x.Operation
+	}
+	return ""
+}
+
+func (x *OperationShare) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *OperationShare) GetPercent() int32 {
+	if x != nil {
+		return x.Percent
+	}
+	return 0
+}
+
 // Single math problem
 type Problem struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Index     int32   `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
-	Theme     string  `protobuf:"bytes,2,opt,name=theme,proto3" json:"theme,omitempty"`
-	Text      string  `protobuf:"bytes,3,opt,name=text,proto3" json:"text,omitempty"`
-	Numbers   []int32 `protobuf:"varint,4,rep,packed,name=numbers,proto3" json:"numbers,omitempty"`
-	Operation string  `protobuf:"bytes,5,opt,name=operation,proto3" json:"operation,omitempty"`
-	Answer    string  `protobuf:"bytes,6,opt,name=answer,proto3" json:"answer,omitempty"`
+	Index        int32     `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	Theme        string    `protobuf:"bytes,2,opt,name=theme,proto3" json:"theme,omitempty"`
+	Text         string    `protobuf:"bytes,3,opt,name=text,proto3" json:"text,omitempty"`
+	Numbers      []int32   `protobuf:"varint,4,rep,packed,name=numbers,proto3" json:"numbers,omitempty"`
+	Operation    string    `protobuf:"bytes,5,opt,name=operation,proto3" json:"operation,omitempty"`
+	Answer       string    `protobuf:"bytes,6,opt,name=answer,proto3" json:"answer,omitempty"`
+	Kind         string    `protobuf:"bytes,7,opt,name=kind,proto3" json:"kind,omitempty"`
+	Accept       []string  `protobuf:"bytes,8,rep,name=accept,proto3" json:"accept,omitempty"`                                    // equivalent spellings of answer, e.g. "230 cm"
+	Choices      []string  `protobuf:"bytes,9,rep,name=choices,proto3" json:"choices,omitempty"`                                  // options for pick-one answers, e.g. "<", "=", ">"
+	Unknown      string    `protobuf:"bytes,10,opt,name=unknown,proto3" json:"unknown,omitempty"`                                 // missing slot: a | b | result
+	Equation     string    `protobuf:"bytes,11,opt,name=equation,proto3" json:"equation,omitempty"`                               // "? + 4 = 9", "?" marks the unknown slot
+	Sequence     []string  `protobuf:"bytes,12,rep,name=sequence,proto3" json:"sequence,omitempty"`                               // pattern terms, "?" marks the one to find
+	Rule         string    `protobuf:"bytes,13,opt,name=rule,proto3" json:"rule,omitempty"`                                       // pattern rule, e.g. "add 3"
+	Expanded     string    `protobuf:"bytes,15,opt,name=expanded,proto3" json:"expanded,omitempty"`                               // expanded form of the number, "300 + 40 + 7"
+	ReadingGrade float64   `protobuf:"fixed64,16,opt,name=reading_grade,json=readingGrade,proto3" json:"reading_grade,omitempty"` // Flesch-Kincaid grade of the text
+	Interest     string    `protobuf:"bytes,17,opt,name=interest,proto3" json:"interest,omitempty"`                               // the student's interest the problem was written about
+	Difficulty   int32     `protobuf:"varint,18,opt,name=difficulty,proto3" json:"difficulty,omitempty"`                          // 1 (warm-up) to 3 (challenge) stars; 0 when not scored
+	SourceText   string    `protobuf:"bytes,19,opt,name=source_text,json=sourceText,proto3" json:"source_text,omitempty"`         // text before translation, kept for dual-language worksheets
+	Solution     *Solution `protobuf:"bytes,20,opt,name=solution,proto3" json:"solution,omitempty"`                               // how to work out the answer; unset for kinds without worked steps
+	Hints        []string  `protobuf:"bytes,21,rep,name=hints,proto3" json:"hints,omitempty"`                                     // up to three, gentlest first; none gives the answer away
+	ChartData    *Chart    `protobuf:"bytes,22,opt,name=chart_data,json=chartData,proto3" json:"chart_data,omitempty"`            // the data a chart question is about; drawn wherever it is shown
+	Slot         int32     `protobuf:"varint,23,opt,name=slot,proto3" json:"slot,omitempty"`                                      // index the problem was planned as before the set was reordered; 0 when it was not
 }
 
 func (x *Problem) Reset() {
 	*x = Problem{}
-	mi := &file_server_proto_problem_gen_proto_msgTypes[1]
+	mi := &file_server_proto_problem_gen_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -144,7 +311,7 @@ func (x *Problem) String() string {
 func (*Problem) ProtoMessage() {}
 
 func (x *Problem) ProtoReflect() protoreflect.Message {
-	mi := &file_server_proto_problem_gen_proto_msgTypes[1]
+	mi := &file_server_proto_problem_gen_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -157,7 +324,7 @@ func (x *Problem) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Problem.ProtoReflect.Descriptor instead.
 func (*Problem) Descriptor() ([]byte, []int) {
-	return file_server_proto_problem_gen_proto_rawDescGZIP(), []int{1}
+	return file_server_proto_problem_gen_proto_rawDescGZIP(), []int{2}
 }
 
 func (x *Problem) GetIndex() int32 {
@@ -202,19 +369,354 @@ func (x *Problem) GetAnswer() string {
 	return ""
 }
 
+func (x *Problem) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *Problem) GetAccept() []string {
+	if x != nil {
+		return x.Accept
+	}
+	return nil
+}
+
+func (x *Problem) GetChoices() []string {
+	if x != nil {
+		return x.Choices
+	}
+	return nil
+}
+
+func (x *Problem) GetUnknown() string {
+	if x != nil {
+		return x.Unknown
+	}
+	return ""
+}
+
+func (x *Problem) GetEquation() string {
+	if x != nil {
+		return x.Equation
+	}
+	return ""
+}
+
+func (x *Problem) GetSequence() []string {
+	if x != nil {
+		return x.Sequence
+	}
+	return nil
+}
+
+func (x *Problem) GetRule() string {
+	if x != nil {
+		return x.Rule
+	}
+	return ""
+}
+
+func (x *Problem) GetExpanded() string {
+	if x != nil {
+		return x.Expanded
+	}
+	return ""
+}
+
+func (x *Problem) GetReadingGrade() float64 {
+	if x != nil {
+		return x.ReadingGrade
+	}
+	return 0
+}
+
+func (x *Problem) GetInterest() string {
+	if x != nil {
+		return x.Interest
+	}
+	return ""
+}
+
+func (x *Problem) GetDifficulty() int32 {
+	if x != nil {
+		return x.Difficulty
+	}
+	return 0
+}
+
+func (x *Problem) GetSourceText() string {
+	if x != nil {
+		return x.SourceText
+	}
+	return ""
+}
+
+func (x *Problem) GetSolution() *Solution {
+	if x != nil {
+		return x.Solution
+	}
+	return nil
+}
+
+func (x *Problem) GetHints() []string {
+	if x != nil {
+		return x.Hints
+	}
+	return nil
+}
+
+func (x *Problem) GetChartData() *Chart {
+	if x != nil {
+		return x.ChartData
+	}
+	return nil
+}
+
+func (x *Problem) GetSlot() int32 {
+	if x != nil {
+		return x.Slot
+	}
+	return 0
+}
+
+// The table a chart question is drawn from, and how it is drawn
+type Chart struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Style  string   `protobuf:"bytes,1,opt,name=style,proto3" json:"style,omitempty"` // bar | pictograph | tally
+	Title  string   `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Labels []string `protobuf:"bytes,3,rep,name=labels,proto3" json:"labels,omitempty"`
+	Values []int32  `protobuf:"varint,4,rep,packed,name=values,proto3" json:"values,omitempty"` // one per label
+	Icons  []string `protobuf:"bytes,5,rep,name=icons,proto3" json:"icons,omitempty"`           // one picture per label, used by pictographs
+	Scale  int32    `protobuf:"varint,6,opt,name=scale,proto3" json:"scale,omitempty"`          // values are multiples of scale; one picture stands for scale
+}
+
+func (x *Chart) Reset() {
+	*x = Chart{}
+	mi := &file_server_proto_problem_gen_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Chart) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Chart) ProtoMessage() {}
+
+func (x *Chart) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_problem_gen_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Chart.ProtoReflect.Descriptor instead.
+func (*Chart) Descriptor() ([]byte, []int) {
+	return file_server_proto_problem_gen_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Chart) GetStyle() string {
+	if x != nil {
+		return x.Style
+	}
+	return ""
+}
+
+func (x *Chart) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Chart) GetLabels() []string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *Chart) GetValues() []int32 {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+func (x *Chart) GetIcons() []string {
+	if x != nil {
+		return x.Icons
+	}
+	return nil
+}
+
+func (x *Chart) GetScale() int32 {
+	if x != nil {
+		return x.Scale
+	}
+	return 0
+}
+
+// Worked solution of a problem, for the answer key
+type Solution struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Equation    string   `protobuf:"bytes,1,opt,name=equation,proto3" json:"equation,omitempty"` // "47 + 25 = 72"
+	Steps       []string `protobuf:"bytes,2,rep,name=steps,proto3" json:"steps,omitempty"`
+	Explanation string   `protobuf:"bytes,3,opt,name=explanation,proto3" json:"explanation,omitempty"` // the model's kid-friendly explanation, checked against the answer
+}
+
+func (x *Solution) Reset() {
+	*x = Solution{}
+	mi := &file_server_proto_problem_gen_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Solution) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Solution) ProtoMessage() {}
+
+func (x *Solution) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_problem_gen_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Solution.ProtoReflect.Descriptor instead.
+func (*Solution) Descriptor() ([]byte, []int) {
+	return file_server_proto_problem_gen_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Solution) GetEquation() string {
+	if x != nil {
+		return x.Equation
+	}
+	return ""
+}
+
+func (x *Solution) GetSteps() []string {
+	if x != nil {
+		return x.Steps
+	}
+	return nil
+}
+
+func (x *Solution) GetExplanation() string {
+	if x != nil {
+		return x.Explanation
+	}
+	return ""
+}
+
+// Something validation found in a problem set
+type Issue struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Index    int32  `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`      // problem index, 0 for the whole set
+	Check    string `protobuf:"bytes,2,opt,name=check,proto3" json:"check,omitempty"`       // validator name, e.g. "range"
+	Severity string `protobuf:"bytes,3,opt,name=severity,proto3" json:"severity,omitempty"` // info | warning | error
+	Message  string `protobuf:"bytes,4,opt,name=message,proto3" json:"message,omitempty"`
+}
+
+func (x *Issue) Reset() {
+	*x = Issue{}
+	mi := &file_server_proto_problem_gen_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Issue) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Issue) ProtoMessage() {}
+
+func (x *Issue) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_problem_gen_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Issue.ProtoReflect.Descriptor instead.
+func (*Issue) Descriptor() ([]byte, []int) {
+	return file_server_proto_problem_gen_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Issue) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *Issue) GetCheck() string {
+	if x != nil {
+		return x.Check
+	}
+	return ""
+}
+
+func (x *Issue) GetSeverity() string {
+	if x != nil {
+		return x.Severity
+	}
+	return ""
+}
+
+func (x *Issue) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
 // Set of problems plus original request
 type ProblemSet struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Problems []*Problem       `protobuf:"bytes,1,rep,name=problems,proto3" json:"problems,omitempty"`
-	Meta     *GenerateRequest `protobuf:"bytes,2,opt,name=meta,proto3" json:"meta,omitempty"`
+	Problems       []*Problem       `protobuf:"bytes,1,rep,name=problems,proto3" json:"problems,omitempty"`
+	Meta           *GenerateRequest `protobuf:"bytes,2,opt,name=meta,proto3" json:"meta,omitempty"`
+	Issues         []*Issue         `protobuf:"bytes,3,rep,name=issues,proto3" json:"issues,omitempty"`
+	Diversity      float64          `protobuf:"fixed64,4,opt,name=diversity,proto3" json:"diversity,omitempty"` // 0 when every problem is alike, 1 when none are
+	Coverage       []*Coverage      `protobuf:"bytes,5,rep,name=coverage,proto3" json:"coverage,omitempty"`
+	SourceLanguage string           `protobuf:"bytes,6,opt,name=source_language,json=sourceLanguage,proto3" json:"source_language,omitempty"` // the language a translated set was written in
+	Id             string           `protobuf:"bytes,7,opt,name=id,proto3" json:"id,omitempty"`                                               // of the set's provenance record, see GetProvenance
 }
 
 func (x *ProblemSet) Reset() {
 	*x = ProblemSet{}
-	mi := &file_server_proto_problem_gen_proto_msgTypes[2]
+	mi := &file_server_proto_problem_gen_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -226,7 +728,7 @@ func (x *ProblemSet) String() string {
 func (*ProblemSet) ProtoMessage() {}
 
 func (x *ProblemSet) ProtoReflect() protoreflect.Message {
-	mi := &file_server_proto_problem_gen_proto_msgTypes[2]
+	mi := &file_server_proto_problem_gen_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -239,7 +741,7 @@ func (x *ProblemSet) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ProblemSet.ProtoReflect.Descriptor instead.
 func (*ProblemSet) Descriptor() ([]byte, []int) {
-	return file_server_proto_problem_gen_proto_rawDescGZIP(), []int{2}
+	return file_server_proto_problem_gen_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *ProblemSet) GetProblems() []*Problem {
@@ -256,6 +758,265 @@ func (x *ProblemSet) GetMeta() *GenerateRequest {
 	return nil
 }
 
+func (x *ProblemSet) GetIssues() []*Issue {
+	if x != nil {
+		return x.Issues
+	}
+	return nil
+}
+
+func (x *ProblemSet) GetDiversity() float64 {
+	if x != nil {
+		return x.Diversity
+	}
+	return 0
+}
+
+func (x *ProblemSet) GetCoverage() []*Coverage {
+	if x != nil {
+		return x.Coverage
+	}
+	return nil
+}
+
+func (x *ProblemSet) GetSourceLanguage() string {
+	if x != nil {
+		return x.SourceLanguage
+	}
+	return ""
+}
+
+func (x *ProblemSet) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+// Which problems of a set are about one of the student's interests
+type Coverage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Interest string  `protobuf:"bytes,1,opt,name=interest,proto3" json:"interest,omitempty"`
+	Problems []int32 `protobuf:"varint,2,rep,packed,name=problems,proto3" json:"problems,omitempty"` // indexes of the problems that mention it
+}
+
+func (x *Coverage) Reset() {
+	*x = Coverage{}
+	mi := &file_server_proto_problem_gen_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Coverage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Coverage) ProtoMessage() {}
+
+func (x *Coverage) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_problem_gen_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Coverage.ProtoReflect.Descriptor instead.
+func (*Coverage) Descriptor() ([]byte, []int) {
+	return file_server_proto_problem_gen_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Coverage) GetInterest() string {
+	if x != nil {
+		return x.Interest
+	}
+	return ""
+}
+
+func (x *Coverage) GetProblems() []int32 {
+	if x != nil {
+		return x.Problems
+	}
+	return nil
+}
+
+// A problem set to translate and the language to translate it into
+type TranslateRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ProblemSet   *ProblemSet `protobuf:"bytes,1,opt,name=problem_set,json=problemSet,proto3" json:"problem_set,omitempty"`
+	Language     string      `protobuf:"bytes,2,opt,name=language,proto3" json:"language,omitempty"`                              // en | es | fr | vi, or a tag such as es-MX
+	DualLanguage bool        `protobuf:"varint,3,opt,name=dual_language,json=dualLanguage,proto3" json:"dual_language,omitempty"` // keep the original text for a side-by-side PDF
+}
+
+func (x *TranslateRequest) Reset() {
+	*x = TranslateRequest{}
+	mi := &file_server_proto_problem_gen_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TranslateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TranslateRequest) ProtoMessage() {}
+
+func (x *TranslateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_problem_gen_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TranslateRequest.ProtoReflect.Descriptor instead.
+func (*TranslateRequest) Descriptor() ([]byte, []int) {
+	return file_server_proto_problem_gen_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *TranslateRequest) GetProblemSet() *ProblemSet {
+	if x != nil {
+		return x.ProblemSet
+	}
+	return nil
+}
+
+func (x *TranslateRequest) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *TranslateRequest) GetDualLanguage() bool {
+	if x != nil {
+		return x.DualLanguage
+	}
+	return false
+}
+
+// Which provenance record to fetch
+type ProvenanceRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+}
+
+func (x *ProvenanceRequest) Reset() {
+	*x = ProvenanceRequest{}
+	mi := &file_server_proto_problem_gen_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProvenanceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProvenanceRequest) ProtoMessage() {}
+
+func (x *ProvenanceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_problem_gen_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProvenanceRequest.ProtoReflect.Descriptor instead.
+func (*ProvenanceRequest) Descriptor() ([]byte, []int) {
+	return file_server_proto_problem_gen_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ProvenanceRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+// Everything that went into one problem set
+type ProvenanceRecord struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id         string      `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Json       string      `protobuf:"bytes,2,opt,name=json,proto3" json:"json,omitempty"`                               // the whole record, versioned JSON
+	ProblemSet *ProblemSet `protobuf:"bytes,3,opt,name=problem_set,json=problemSet,proto3" json:"problem_set,omitempty"` // the set that came out, to render again; unset if none did
+}
+
+func (x *ProvenanceRecord) Reset() {
+	*x = ProvenanceRecord{}
+	mi := &file_server_proto_problem_gen_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProvenanceRecord) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProvenanceRecord) ProtoMessage() {}
+
+func (x *ProvenanceRecord) ProtoReflect() protoreflect.Message {
+	mi := &file_server_proto_problem_gen_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProvenanceRecord.ProtoReflect.Descriptor instead.
+func (*ProvenanceRecord) Descriptor() ([]byte, []int) {
+	return file_server_proto_problem_gen_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ProvenanceRecord) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ProvenanceRecord) GetJson() string {
+	if x != nil {
+		return x.Json
+	}
+	return ""
+}
+
+func (x *ProvenanceRecord) GetProblemSet() *ProblemSet {
+	if x != nil {
+		return x.ProblemSet
+	}
+	return nil
+}
+
 // Response containing generated PDF bytes
 type PDFResponse struct {
 	state         protoimpl.MessageState
@@ -268,7 +1029,7 @@ type PDFResponse struct {
 
 func (x *PDFResponse) Reset() {
 	*x = PDFResponse{}
-	mi := &file_server_proto_problem_gen_proto_msgTypes[3]
+	mi := &file_server_proto_problem_gen_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -280,7 +1041,7 @@ func (x *PDFResponse) String() string {
 func (*PDFResponse) ProtoMessage() {}
 
 func (x *PDFResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_server_proto_problem_gen_proto_msgTypes[3]
+	mi := &file_server_proto_problem_gen_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -293,7 +1054,7 @@ func (x *PDFResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PDFResponse.ProtoReflect.Descriptor instead.
 func (*PDFResponse) Descriptor() ([]byte, []int) {
-	return file_server_proto_problem_gen_proto_rawDescGZIP(), []int{3}
+	return file_server_proto_problem_gen_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *PDFResponse) GetPdf() []byte {
@@ -315,54 +1076,177 @@ var File_server_proto_problem_gen_proto protoreflect.FileDescriptor
 var file_server_proto_problem_gen_proto_rawDesc = []byte{
 	0x0a, 0x1e, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x70,
 	0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x5f, 0x67, 0x65, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x12, 0x0a, 0x70, 0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x67, 0x65, 0x6e, 0x22, 0xe1, 0x01, 0x0a,
+	0x12, 0x0a, 0x70, 0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x67, 0x65, 0x6e, 0x22, 0xab, 0x04, 0x0a,
 	0x0f, 0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
 	0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
-	0x6e, 0x61, 0x6d, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x67, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x67, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x12, 0x1c, 0x0a, 0x09,
-	0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x09, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x21, 0x0a, 0x0c, 0x6e, 0x75,
-	0x6d, 0x5f, 0x70, 0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05,
-	0x52, 0x0b, 0x6e, 0x75, 0x6d, 0x50, 0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x73, 0x12, 0x1f, 0x0a,
-	0x0b, 0x67, 0x72, 0x61, 0x64, 0x65, 0x5f, 0x6c, 0x65, 0x76, 0x65, 0x6c, 0x18, 0x05, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x0a, 0x67, 0x72, 0x61, 0x64, 0x65, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x12, 0x1f,
-	0x0a, 0x0b, 0x6c, 0x69, 0x6b, 0x65, 0x73, 0x5f, 0x6e, 0x6f, 0x75, 0x6e, 0x73, 0x18, 0x06, 0x20,
-	0x03, 0x28, 0x09, 0x52, 0x0a, 0x6c, 0x69, 0x6b, 0x65, 0x73, 0x4e, 0x6f, 0x75, 0x6e, 0x73, 0x12,
-	0x1f, 0x0a, 0x0b, 0x6c, 0x69, 0x6b, 0x65, 0x73, 0x5f, 0x76, 0x65, 0x72, 0x62, 0x73, 0x18, 0x07,
-	0x20, 0x03, 0x28, 0x09, 0x52, 0x0a, 0x6c, 0x69, 0x6b, 0x65, 0x73, 0x56, 0x65, 0x72, 0x62, 0x73,
-	0x22, 0x99, 0x01, 0x0a, 0x07, 0x50, 0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x12, 0x14, 0x0a, 0x05,
-	0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x69, 0x6e, 0x64,
-	0x65, 0x78, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x68, 0x65, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x05, 0x74, 0x68, 0x65, 0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x65, 0x78, 0x74,
-	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x65, 0x78, 0x74, 0x12, 0x18, 0x0a, 0x07,
-	0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x05, 0x52, 0x07, 0x6e,
-	0x75, 0x6d, 0x62, 0x65, 0x72, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74,
-	0x69, 0x6f, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6f, 0x70, 0x65, 0x72, 0x61,
-	0x74, 0x69, 0x6f, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x61, 0x6e, 0x73, 0x77, 0x65, 0x72, 0x18, 0x06,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x61, 0x6e, 0x73, 0x77, 0x65, 0x72, 0x22, 0x6e, 0x0a, 0x0a,
-	0x50, 0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x53, 0x65, 0x74, 0x12, 0x2f, 0x0a, 0x08, 0x70, 0x72,
-	0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x70,
-	0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x67, 0x65, 0x6e, 0x2e, 0x50, 0x72, 0x6f, 0x62, 0x6c, 0x65,
-	0x6d, 0x52, 0x08, 0x70, 0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x73, 0x12, 0x2f, 0x0a, 0x04, 0x6d,
-	0x65, 0x74, 0x61, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x70, 0x72, 0x6f, 0x62,
-	0x6c, 0x65, 0x6d, 0x67, 0x65, 0x6e, 0x2e, 0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x52,
-	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x52, 0x04, 0x6d, 0x65, 0x74, 0x61, 0x22, 0x3b, 0x0a, 0x0b,
-	0x50, 0x44, 0x46, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x70,
-	0x64, 0x66, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x03, 0x70, 0x64, 0x66, 0x12, 0x1a, 0x0a,
-	0x08, 0x66, 0x69, 0x6c, 0x65, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x08, 0x66, 0x69, 0x6c, 0x65, 0x6e, 0x61, 0x6d, 0x65, 0x32, 0xa0, 0x01, 0x0a, 0x09, 0x47, 0x65,
-	0x6e, 0x65, 0x72, 0x61, 0x74, 0x6f, 0x72, 0x12, 0x49, 0x0a, 0x12, 0x47, 0x65, 0x6e, 0x65, 0x72,
-	0x61, 0x74, 0x65, 0x50, 0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x53, 0x65, 0x74, 0x12, 0x1b, 0x2e,
-	0x70, 0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x67, 0x65, 0x6e, 0x2e, 0x47, 0x65, 0x6e, 0x65, 0x72,
-	0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x70, 0x72, 0x6f,
-	0x62, 0x6c, 0x65, 0x6d, 0x67, 0x65, 0x6e, 0x2e, 0x50, 0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x53,
-	0x65, 0x74, 0x12, 0x48, 0x0a, 0x15, 0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x50, 0x72,
-	0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x53, 0x65, 0x74, 0x50, 0x44, 0x46, 0x12, 0x16, 0x2e, 0x70, 0x72,
+	0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1a, 0x0a, 0x06, 0x67, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x42, 0x02, 0x18, 0x01, 0x52, 0x06, 0x67, 0x65, 0x6e, 0x64, 0x65, 0x72,
+	0x12, 0x1c, 0x0a, 0x09, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x09, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x21,
+	0x0a, 0x0c, 0x6e, 0x75, 0x6d, 0x5f, 0x70, 0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x73, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x0b, 0x6e, 0x75, 0x6d, 0x50, 0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d,
+	0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x67, 0x72, 0x61, 0x64, 0x65, 0x5f, 0x6c, 0x65, 0x76, 0x65, 0x6c,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x67, 0x72, 0x61, 0x64, 0x65, 0x4c, 0x65, 0x76,
+	0x65, 0x6c, 0x12, 0x1f, 0x0a, 0x0b, 0x6c, 0x69, 0x6b, 0x65, 0x73, 0x5f, 0x6e, 0x6f, 0x75, 0x6e,
+	0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0a, 0x6c, 0x69, 0x6b, 0x65, 0x73, 0x4e, 0x6f,
+	0x75, 0x6e, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x6c, 0x69, 0x6b, 0x65, 0x73, 0x5f, 0x76, 0x65, 0x72,
+	0x62, 0x73, 0x18, 0x07, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0a, 0x6c, 0x69, 0x6b, 0x65, 0x73, 0x56,
+	0x65, 0x72, 0x62, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x6b, 0x69, 0x6e, 0x64, 0x18, 0x08, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x04, 0x6b, 0x69, 0x6e, 0x64, 0x12, 0x1f, 0x0a, 0x0b, 0x75, 0x6e, 0x69, 0x74,
+	0x5f, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x75,
+	0x6e, 0x69, 0x74, 0x53, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x12, 0x29, 0x0a, 0x10, 0x69, 0x6e, 0x74,
+	0x65, 0x72, 0x65, 0x73, 0x74, 0x5f, 0x62, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x18, 0x0a, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x65, 0x73, 0x74, 0x42, 0x61, 0x6c,
+	0x61, 0x6e, 0x63, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x72, 0x6f, 0x6e, 0x6f, 0x75, 0x6e, 0x73,
+	0x18, 0x0b, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70, 0x72, 0x6f, 0x6e, 0x6f, 0x75, 0x6e, 0x73,
+	0x12, 0x3a, 0x0a, 0x0a, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x0c,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x70, 0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x67, 0x65,
+	0x6e, 0x2e, 0x4f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x53, 0x68, 0x61, 0x72, 0x65,
+	0x52, 0x0a, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x18, 0x0a, 0x07,
+	0x61, 0x72, 0x72, 0x61, 0x6e, 0x67, 0x65, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x61,
+	0x72, 0x72, 0x61, 0x6e, 0x67, 0x65, 0x12, 0x20, 0x0a, 0x0b, 0x70, 0x72, 0x6f, 0x67, 0x72, 0x65,
+	0x73, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x70, 0x72, 0x6f,
+	0x67, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x1e, 0x0a, 0x0a, 0x72, 0x65, 0x67, 0x72,
+	0x6f, 0x75, 0x70, 0x69, 0x6e, 0x67, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x72, 0x65,
+	0x67, 0x72, 0x6f, 0x75, 0x70, 0x69, 0x6e, 0x67, 0x12, 0x1a, 0x0a, 0x08, 0x6c, 0x61, 0x6e, 0x67,
+	0x75, 0x61, 0x67, 0x65, 0x18, 0x10, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x6c, 0x61, 0x6e, 0x67,
+	0x75, 0x61, 0x67, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x18, 0x11, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x05, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x22, 0x5e, 0x0a, 0x0e, 0x4f, 0x70,
+	0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x53, 0x68, 0x61, 0x72, 0x65, 0x12, 0x1c, 0x0a, 0x09,
+	0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x09, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x6f,
+	0x75, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74,
+	0x12, 0x18, 0x0a, 0x07, 0x70, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x07, 0x70, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74, 0x22, 0xfe, 0x04, 0x0a, 0x07, 0x50,
+	0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x12, 0x14, 0x0a, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x14, 0x0a, 0x05,
+	0x74, 0x68, 0x65, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x74, 0x68, 0x65,
+	0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x65, 0x78, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x74, 0x65, 0x78, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72,
+	0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x05, 0x52, 0x07, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x73,
+	0x12, 0x1c, 0x0a, 0x09, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x09, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x16,
+	0x0a, 0x06, 0x61, 0x6e, 0x73, 0x77, 0x65, 0x72, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06,
+	0x61, 0x6e, 0x73, 0x77, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x6b, 0x69, 0x6e, 0x64, 0x18, 0x07,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6b, 0x69, 0x6e, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x61, 0x63,
+	0x63, 0x65, 0x70, 0x74, 0x18, 0x08, 0x20, 0x03, 0x28, 0x09, 0x52, 0x06, 0x61, 0x63, 0x63, 0x65,
+	0x70, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x68, 0x6f, 0x69, 0x63, 0x65, 0x73, 0x18, 0x09, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x07, 0x63, 0x68, 0x6f, 0x69, 0x63, 0x65, 0x73, 0x12, 0x18, 0x0a, 0x07,
+	0x75, 0x6e, 0x6b, 0x6e, 0x6f, 0x77, 0x6e, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x75,
+	0x6e, 0x6b, 0x6e, 0x6f, 0x77, 0x6e, 0x12, 0x1a, 0x0a, 0x08, 0x65, 0x71, 0x75, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x65, 0x71, 0x75, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x12, 0x1a, 0x0a, 0x08, 0x73, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x0c,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x08, 0x73, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x63, 0x65, 0x12, 0x12,
+	0x0a, 0x04, 0x72, 0x75, 0x6c, 0x65, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x72, 0x75,
+	0x6c, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x65, 0x78, 0x70, 0x61, 0x6e, 0x64, 0x65, 0x64, 0x18, 0x0f,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x65, 0x78, 0x70, 0x61, 0x6e, 0x64, 0x65, 0x64, 0x12, 0x23,
+	0x0a, 0x0d, 0x72, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x5f, 0x67, 0x72, 0x61, 0x64, 0x65, 0x18,
+	0x10, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0c, 0x72, 0x65, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x47, 0x72,
+	0x61, 0x64, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x65, 0x73, 0x74, 0x18,
+	0x11, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x65, 0x73, 0x74, 0x12,
+	0x1e, 0x0a, 0x0a, 0x64, 0x69, 0x66, 0x66, 0x69, 0x63, 0x75, 0x6c, 0x74, 0x79, 0x18, 0x12, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x0a, 0x64, 0x69, 0x66, 0x66, 0x69, 0x63, 0x75, 0x6c, 0x74, 0x79, 0x12,
+	0x1f, 0x0a, 0x0b, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x5f, 0x74, 0x65, 0x78, 0x74, 0x18, 0x13,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x54, 0x65, 0x78, 0x74,
+	0x12, 0x30, 0x0a, 0x08, 0x73, 0x6f, 0x6c, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x14, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x14, 0x2e, 0x70, 0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x67, 0x65, 0x6e, 0x2e,
+	0x53, 0x6f, 0x6c, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x08, 0x73, 0x6f, 0x6c, 0x75, 0x74, 0x69,
+	0x6f, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x68, 0x69, 0x6e, 0x74, 0x73, 0x18, 0x15, 0x20, 0x03, 0x28,
+	0x09, 0x52, 0x05, 0x68, 0x69, 0x6e, 0x74, 0x73, 0x12, 0x30, 0x0a, 0x0a, 0x63, 0x68, 0x61, 0x72,
+	0x74, 0x5f, 0x64, 0x61, 0x74, 0x61, 0x18, 0x16, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x70,
+	0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x67, 0x65, 0x6e, 0x2e, 0x43, 0x68, 0x61, 0x72, 0x74, 0x52,
+	0x09, 0x63, 0x68, 0x61, 0x72, 0x74, 0x44, 0x61, 0x74, 0x61, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x6c,
+	0x6f, 0x74, 0x18, 0x17, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x73, 0x6c, 0x6f, 0x74, 0x4a, 0x04,
+	0x08, 0x0e, 0x10, 0x0f, 0x52, 0x05, 0x63, 0x68, 0x61, 0x72, 0x74, 0x22, 0x8f, 0x01, 0x0a, 0x05,
+	0x43, 0x68, 0x61, 0x72, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x74, 0x79, 0x6c, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x73, 0x74, 0x79, 0x6c, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x74,
+	0x69, 0x74, 0x6c, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x74, 0x69, 0x74, 0x6c,
+	0x65, 0x12, 0x16, 0x0a, 0x06, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28,
+	0x09, 0x52, 0x06, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x76, 0x61, 0x6c,
+	0x75, 0x65, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x05, 0x52, 0x06, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x73, 0x12, 0x14, 0x0a, 0x05, 0x69, 0x63, 0x6f, 0x6e, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x05, 0x69, 0x63, 0x6f, 0x6e, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x63, 0x61, 0x6c, 0x65,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x73, 0x63, 0x61, 0x6c, 0x65, 0x22, 0x5e, 0x0a,
+	0x08, 0x53, 0x6f, 0x6c, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1a, 0x0a, 0x08, 0x65, 0x71, 0x75,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x65, 0x71, 0x75,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x74, 0x65, 0x70, 0x73, 0x18, 0x02,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x05, 0x73, 0x74, 0x65, 0x70, 0x73, 0x12, 0x20, 0x0a, 0x0b, 0x65,
+	0x78, 0x70, 0x6c, 0x61, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0b, 0x65, 0x78, 0x70, 0x6c, 0x61, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x69, 0x0a,
+	0x05, 0x49, 0x73, 0x73, 0x75, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x14, 0x0a, 0x05,
+	0x63, 0x68, 0x65, 0x63, 0x6b, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x63, 0x68, 0x65,
+	0x63, 0x6b, 0x12, 0x1a, 0x0a, 0x08, 0x73, 0x65, 0x76, 0x65, 0x72, 0x69, 0x74, 0x79, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x73, 0x65, 0x76, 0x65, 0x72, 0x69, 0x74, 0x79, 0x12, 0x18,
+	0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0xa2, 0x02, 0x0a, 0x0a, 0x50, 0x72, 0x6f,
+	0x62, 0x6c, 0x65, 0x6d, 0x53, 0x65, 0x74, 0x12, 0x2f, 0x0a, 0x08, 0x70, 0x72, 0x6f, 0x62, 0x6c,
+	0x65, 0x6d, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x70, 0x72, 0x6f, 0x62,
+	0x6c, 0x65, 0x6d, 0x67, 0x65, 0x6e, 0x2e, 0x50, 0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x52, 0x08,
+	0x70, 0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x73, 0x12, 0x2f, 0x0a, 0x04, 0x6d, 0x65, 0x74, 0x61,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x70, 0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d,
+	0x67, 0x65, 0x6e, 0x2e, 0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x52, 0x04, 0x6d, 0x65, 0x74, 0x61, 0x12, 0x29, 0x0a, 0x06, 0x69, 0x73, 0x73,
+	0x75, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x70, 0x72, 0x6f, 0x62,
+	0x6c, 0x65, 0x6d, 0x67, 0x65, 0x6e, 0x2e, 0x49, 0x73, 0x73, 0x75, 0x65, 0x52, 0x06, 0x69, 0x73,
+	0x73, 0x75, 0x65, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x64, 0x69, 0x76, 0x65, 0x72, 0x73, 0x69, 0x74,
+	0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x09, 0x64, 0x69, 0x76, 0x65, 0x72, 0x73, 0x69,
+	0x74, 0x79, 0x12, 0x30, 0x0a, 0x08, 0x63, 0x6f, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x18, 0x05,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x70, 0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x67, 0x65,
+	0x6e, 0x2e, 0x43, 0x6f, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x52, 0x08, 0x63, 0x6f, 0x76, 0x65,
+	0x72, 0x61, 0x67, 0x65, 0x12, 0x27, 0x0a, 0x0f, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x5f, 0x6c,
+	0x61, 0x6e, 0x67, 0x75, 0x61, 0x67, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x73,
+	0x6f, 0x75, 0x72, 0x63, 0x65, 0x4c, 0x61, 0x6e, 0x67, 0x75, 0x61, 0x67, 0x65, 0x12, 0x0e, 0x0a,
+	0x02, 0x69, 0x64, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x22, 0x42, 0x0a,
+	0x08, 0x43, 0x6f, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x69, 0x6e, 0x74,
+	0x65, 0x72, 0x65, 0x73, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x69, 0x6e, 0x74,
+	0x65, 0x72, 0x65, 0x73, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d,
+	0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x05, 0x52, 0x08, 0x70, 0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d,
+	0x73, 0x22, 0x8c, 0x01, 0x0a, 0x10, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x6c, 0x61, 0x74, 0x65, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x37, 0x0a, 0x0b, 0x70, 0x72, 0x6f, 0x62, 0x6c, 0x65,
+	0x6d, 0x5f, 0x73, 0x65, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x70, 0x72,
+	0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x67, 0x65, 0x6e, 0x2e, 0x50, 0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d,
+	0x53, 0x65, 0x74, 0x52, 0x0a, 0x70, 0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x53, 0x65, 0x74, 0x12,
+	0x1a, 0x0a, 0x08, 0x6c, 0x61, 0x6e, 0x67, 0x75, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x08, 0x6c, 0x61, 0x6e, 0x67, 0x75, 0x61, 0x67, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x64,
+	0x75, 0x61, 0x6c, 0x5f, 0x6c, 0x61, 0x6e, 0x67, 0x75, 0x61, 0x67, 0x65, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x0c, 0x64, 0x75, 0x61, 0x6c, 0x4c, 0x61, 0x6e, 0x67, 0x75, 0x61, 0x67, 0x65,
+	0x22, 0x23, 0x0a, 0x11, 0x50, 0x72, 0x6f, 0x76, 0x65, 0x6e, 0x61, 0x6e, 0x63, 0x65, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x02, 0x69, 0x64, 0x22, 0x6f, 0x0a, 0x10, 0x50, 0x72, 0x6f, 0x76, 0x65, 0x6e, 0x61,
+	0x6e, 0x63, 0x65, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6a, 0x73, 0x6f,
+	0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6a, 0x73, 0x6f, 0x6e, 0x12, 0x37, 0x0a,
+	0x0b, 0x70, 0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x5f, 0x73, 0x65, 0x74, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x16, 0x2e, 0x70, 0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x67, 0x65, 0x6e, 0x2e,
+	0x50, 0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x53, 0x65, 0x74, 0x52, 0x0a, 0x70, 0x72, 0x6f, 0x62,
+	0x6c, 0x65, 0x6d, 0x53, 0x65, 0x74, 0x22, 0x3b, 0x0a, 0x0b, 0x50, 0x44, 0x46, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x70, 0x64, 0x66, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0c, 0x52, 0x03, 0x70, 0x64, 0x66, 0x12, 0x1a, 0x0a, 0x08, 0x66, 0x69, 0x6c, 0x65, 0x6e,
+	0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x66, 0x69, 0x6c, 0x65, 0x6e,
+	0x61, 0x6d, 0x65, 0x32, 0xbb, 0x02, 0x0a, 0x09, 0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x6f,
+	0x72, 0x12, 0x49, 0x0a, 0x12, 0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x50, 0x72, 0x6f,
+	0x62, 0x6c, 0x65, 0x6d, 0x53, 0x65, 0x74, 0x12, 0x1b, 0x2e, 0x70, 0x72, 0x6f, 0x62, 0x6c, 0x65,
+	0x6d, 0x67, 0x65, 0x6e, 0x2e, 0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x70, 0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x67, 0x65,
+	0x6e, 0x2e, 0x50, 0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x53, 0x65, 0x74, 0x12, 0x48, 0x0a, 0x15,
+	0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x50, 0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x53,
+	0x65, 0x74, 0x50, 0x44, 0x46, 0x12, 0x16, 0x2e, 0x70, 0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x67,
+	0x65, 0x6e, 0x2e, 0x50, 0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x53, 0x65, 0x74, 0x1a, 0x17, 0x2e,
+	0x70, 0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x67, 0x65, 0x6e, 0x2e, 0x50, 0x44, 0x46, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x4b, 0x0a, 0x13, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x6c,
+	0x61, 0x74, 0x65, 0x50, 0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x53, 0x65, 0x74, 0x12, 0x1c, 0x2e,
+	0x70, 0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x67, 0x65, 0x6e, 0x2e, 0x54, 0x72, 0x61, 0x6e, 0x73,
+	0x6c, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x70, 0x72,
 	0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x67, 0x65, 0x6e, 0x2e, 0x50, 0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d,
-	0x53, 0x65, 0x74, 0x1a, 0x17, 0x2e, 0x70, 0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x67, 0x65, 0x6e,
-	0x2e, 0x50, 0x44, 0x46, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x16, 0x5a, 0x14,
-	0x2e, 0x2f, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x3b, 0x70,
-	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x53, 0x65, 0x74, 0x12, 0x4c, 0x0a, 0x0d, 0x47, 0x65, 0x74, 0x50, 0x72, 0x6f, 0x76, 0x65, 0x6e,
+	0x61, 0x6e, 0x63, 0x65, 0x12, 0x1d, 0x2e, 0x70, 0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x67, 0x65,
+	0x6e, 0x2e, 0x50, 0x72, 0x6f, 0x76, 0x65, 0x6e, 0x61, 0x6e, 0x63, 0x65, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x1c, 0x2e, 0x70, 0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x67, 0x65, 0x6e,
+	0x2e, 0x50, 0x72, 0x6f, 0x76, 0x65, 0x6e, 0x61, 0x6e, 0x63, 0x65, 0x52, 0x65, 0x63, 0x6f, 0x72,
+	0x64, 0x42, 0x16, 0x5a, 0x14, 0x2e, 0x2f, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x2f, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x3b, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x33,
 }
 
 var (
@@ -377,25 +1261,44 @@ func file_server_proto_problem_gen_proto_rawDescGZIP() []byte {
 	return file_server_proto_problem_gen_proto_rawDescData
 }
 
-var file_server_proto_problem_gen_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_server_proto_problem_gen_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
 var file_server_proto_problem_gen_proto_goTypes = []any{
-	(*GenerateRequest)(nil), // 0: problemgen.GenerateRequest
-	(*Problem)(nil),         // 1: problemgen.Problem
-	(*ProblemSet)(nil),      // 2: problemgen.ProblemSet
-	(*PDFResponse)(nil),     // 3: problemgen.PDFResponse
+	(*GenerateRequest)(nil),   // 0: problemgen.GenerateRequest
+	(*OperationShare)(nil),    // 1: problemgen.OperationShare
+	(*Problem)(nil),           // 2: problemgen.Problem
+	(*Chart)(nil),             // 3: problemgen.Chart
+	(*Solution)(nil),          // 4: problemgen.Solution
+	(*Issue)(nil),             // 5: problemgen.Issue
+	(*ProblemSet)(nil),        // 6: problemgen.ProblemSet
+	(*Coverage)(nil),          // 7: problemgen.Coverage
+	(*TranslateRequest)(nil),  // 8: problemgen.TranslateRequest
+	(*ProvenanceRequest)(nil), // 9: problemgen.ProvenanceRequest
+	(*ProvenanceRecord)(nil),  // 10: problemgen.ProvenanceRecord
+	(*PDFResponse)(nil),       // 11: problemgen.PDFResponse
 }
 var file_server_proto_problem_gen_proto_depIdxs = []int32{
-	1, // 0: problemgen.ProblemSet.problems:type_name -> problemgen.Problem
-	0, // 1: problemgen.ProblemSet.meta:type_name -> problemgen.GenerateRequest
-	0, // 2: problemgen.Generator.GenerateProblemSet:input_type -> problemgen.GenerateRequest
-	2, // 3: problemgen.Generator.GenerateProblemSetPDF:input_type -> problemgen.ProblemSet
-	2, // 4: problemgen.Generator.GenerateProblemSet:output_type -> problemgen.ProblemSet
-	3, // 5: problemgen.Generator.GenerateProblemSetPDF:output_type -> problemgen.PDFResponse
-	4, // [4:6] is the sub-list for method output_type
-	2, // [2:4] is the sub-list for method input_type
-	2, // [2:2] is the sub-list for extension type_name
-	2, // [2:2] is the sub-list for extension extendee
-	0, // [0:2] is the sub-list for field type_name
+	1,  // 0: problemgen.GenerateRequest.operations:type_name -> problemgen.OperationShare
+	4,  // 1: problemgen.Problem.solution:type_name -> problemgen.Solution
+	3,  // 2: problemgen.Problem.chart_data:type_name -> problemgen.Chart
+	2,  // 3: problemgen.ProblemSet.problems:type_name -> problemgen.Problem
+	0,  // 4: problemgen.ProblemSet.meta:type_name -> problemgen.GenerateRequest
+	5,  // 5: problemgen.ProblemSet.issues:type_name -> problemgen.Issue
+	7,  // 6: problemgen.ProblemSet.coverage:type_name -> problemgen.Coverage
+	6,  // 7: problemgen.TranslateRequest.problem_set:type_name -> problemgen.ProblemSet
+	6,  // 8: problemgen.ProvenanceRecord.problem_set:type_name -> problemgen.ProblemSet
+	0,  // 9: problemgen.Generator.GenerateProblemSet:input_type -> problemgen.GenerateRequest
+	6,  // 10: problemgen.Generator.GenerateProblemSetPDF:input_type -> problemgen.ProblemSet
+	8,  // 11: problemgen.Generator.TranslateProblemSet:input_type -> problemgen.TranslateRequest
+	9,  // 12: problemgen.Generator.GetProvenance:input_type -> problemgen.ProvenanceRequest
+	6,  // 13: problemgen.Generator.GenerateProblemSet:output_type -> problemgen.ProblemSet
+	11, // 14: problemgen.Generator.GenerateProblemSetPDF:output_type -> problemgen.PDFResponse
+	6,  // 15: problemgen.Generator.TranslateProblemSet:output_type -> problemgen.ProblemSet
+	10, // 16: problemgen.Generator.GetProvenance:output_type -> problemgen.ProvenanceRecord
+	13, // [13:17] is the sub-list for method output_type
+	9,  // [9:13] is the sub-list for method input_type
+	9,  // [9:9] is the sub-list for extension type_name
+	9,  // [9:9] is the sub-list for extension extendee
+	0,  // [0:9] is the sub-list for field type_name
 }
 
 func init() { file_server_proto_problem_gen_proto_init() }
@@ -409,7 +1312,7 @@ func file_server_proto_problem_gen_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_server_proto_problem_gen_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   4,
+			NumMessages:   12,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
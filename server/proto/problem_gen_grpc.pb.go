@@ -23,6 +23,8 @@ const _ = grpc.SupportPackageIsVersion9
 const (
 	Generator_GenerateProblemSet_FullMethodName    = "/problemgen.Generator/GenerateProblemSet"
 	Generator_GenerateProblemSetPDF_FullMethodName = "/problemgen.Generator/GenerateProblemSetPDF"
+	Generator_TranslateProblemSet_FullMethodName   = "/problemgen.Generator/TranslateProblemSet"
+	Generator_GetProvenance_FullMethodName         = "/problemgen.Generator/GetProvenance"
 )
 
 // GeneratorClient is the client API for Generator service.
@@ -35,6 +37,10 @@ type GeneratorClient interface {
 	GenerateProblemSet(ctx context.Context, in *GenerateRequest, opts ...grpc.CallOption) (*ProblemSet, error)
 	// Generate both problems and a PDF file containing them
 	GenerateProblemSetPDF(ctx context.Context, in *ProblemSet, opts ...grpc.CallOption) (*PDFResponse, error)
+	// Translate the text and themes of a problem set, keeping its math
+	TranslateProblemSet(ctx context.Context, in *TranslateRequest, opts ...grpc.CallOption) (*ProblemSet, error)
+	// Fetch the provenance record of a set by its id
+	GetProvenance(ctx context.Context, in *ProvenanceRequest, opts ...grpc.CallOption) (*ProvenanceRecord, error)
 }
 
 type generatorClient struct {
@@ -65,6 +71,26 @@ func (c *generatorClient) GenerateProblemSetPDF(ctx context.Context, in *Problem
 	return out, nil
 }
 
+func (c *generatorClient) TranslateProblemSet(ctx context.Context, in *TranslateRequest, opts ...grpc.CallOption) (*ProblemSet, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ProblemSet)
+	err := c.cc.Invoke(ctx, Generator_TranslateProblemSet_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *generatorClient) GetProvenance(ctx context.Context, in *ProvenanceRequest, opts ...grpc.CallOption) (*ProvenanceRecord, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ProvenanceRecord)
+	err := c.cc.Invoke(ctx, Generator_GetProvenance_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // GeneratorServer is the server API for Generator service.
 // All implementations must embed UnimplementedGeneratorServer
 // for forward compatibility.
@@ -75,6 +101,10 @@ type GeneratorServer interface {
 	GenerateProblemSet(context.Context, *GenerateRequest) (*ProblemSet, error)
 	// Generate both problems and a PDF file containing them
 	GenerateProblemSetPDF(context.Context, *ProblemSet) (*PDFResponse, error)
+	// Translate the text and themes of a problem set, keeping its math
+	TranslateProblemSet(context.Context, *TranslateRequest) (*ProblemSet, error)
+	// Fetch the provenance record of a set by its id
+	GetProvenance(context.Context, *ProvenanceRequest) (*ProvenanceRecord, error)
 	mustEmbedUnimplementedGeneratorServer()
 }
 
@@ -91,6 +121,12 @@ func (UnimplementedGeneratorServer) GenerateProblemSet(context.Context, *Generat
 func (UnimplementedGeneratorServer) GenerateProblemSetPDF(context.Context, *ProblemSet) (*PDFResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GenerateProblemSetPDF not implemented")
 }
+func (UnimplementedGeneratorServer) TranslateProblemSet(context.Context, *TranslateRequest) (*ProblemSet, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TranslateProblemSet not implemented")
+}
+func (UnimplementedGeneratorServer) GetProvenance(context.Context, *ProvenanceRequest) (*ProvenanceRecord, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetProvenance not implemented")
+}
 func (UnimplementedGeneratorServer) mustEmbedUnimplementedGeneratorServer() {}
 func (UnimplementedGeneratorServer) testEmbeddedByValue()                   {}
 
@@ -148,6 +184,42 @@ func _Generator_GenerateProblemSetPDF_Handler(srv interface{}, ctx context.Conte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Generator_TranslateProblemSet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TranslateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GeneratorServer).TranslateProblemSet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Generator_TranslateProblemSet_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GeneratorServer).TranslateProblemSet(ctx, req.(*TranslateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Generator_GetProvenance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProvenanceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GeneratorServer).GetProvenance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Generator_GetProvenance_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GeneratorServer).GetProvenance(ctx, req.(*ProvenanceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Generator_ServiceDesc is the grpc.ServiceDesc for Generator service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -163,6 +235,14 @@ var Generator_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GenerateProblemSetPDF",
 			Handler:    _Generator_GenerateProblemSetPDF_Handler,
 		},
+		{
+			MethodName: "TranslateProblemSet",
+			Handler:    _Generator_TranslateProblemSet_Handler,
+		},
+		{
+			MethodName: "GetProvenance",
+			Handler:    _Generator_GetProvenance_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "server/proto/problem_gen.proto",
@@ -15,7 +15,10 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	pg "github.com/qjs/mathgen_gemma/server/problem_generator"
 	pb "github.com/qjs/mathgen_gemma/server/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type WebApp struct {
@@ -23,6 +26,7 @@ type WebApp struct {
 	GRPCClient pb.GeneratorClient
 	Server     *http.Server
 	tempDir    string
+	hints      *pg.HintLog // hint usage from interactive worksheets; nil only logs it
 }
 
 // NewWebApp wires routes, templates, static assets
@@ -34,7 +38,22 @@ func NewWebApp(grpcClient pb.GeneratorClient, outputDir string) *WebApp {
 
 	// templates (includes layout + partials)
 	router.SetFuncMap(template.FuncMap{
-		"now": time.Now,
+		"now":  time.Now,
+		"join": strings.Join,
+		// blank draws an answer box where an equation's unknown goes.
+		"blank": func(eq string) template.HTML {
+			return template.HTML(strings.Replace(template.HTMLEscapeString(eq), pg.Blank, `<span class="blank"></span>`, 1))
+		},
+		"isBlank": func(term string) bool { return term == pg.Blank },
+		// svg draws a chart problem's chart; RenderChart escapes its text.
+		"svg": func(c *pb.Chart) (template.HTML, error) {
+			data := pg.Dataset{Title: c.Title, Labels: c.Labels, Icons: c.Icons, Scale: int(c.Scale)}
+			for _, v := range c.Values {
+				data.Values = append(data.Values, int(v))
+			}
+			s, err := pg.RenderChart(data, c.Style)
+			return template.HTML(s), err
+		},
 	})
 	router.LoadHTMLGlob("server/webapp/template/*")
 
@@ -43,6 +62,11 @@ func NewWebApp(grpcClient pb.GeneratorClient, outputDir string) *WebApp {
 		GRPCClient: grpcClient,
 		tempDir:    outputDir,
 	}
+	if f, err := os.OpenFile(filepath.Join(outputDir, "hints.jsonl"), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644); err != nil {
+		log.Printf("hint log: %v", err)
+	} else {
+		app.hints = pg.NewHintLog(f)
+	}
 	app.setupRoutes()
 	return app
 }
@@ -81,6 +105,9 @@ func (app *WebApp) setupRoutes() {
 	app.Router.POST("/generatePDF", app.generatePDF)
 	app.Router.POST("/generateInteractive", app.generateInteractive)
 	app.Router.GET("/download/:id", app.downloadPDF)
+	app.Router.GET("/provenance/:id", app.provenance)
+	app.Router.GET("/provenance/:id/pdf", app.renderAgain)
+	app.Router.POST("/hints", app.recordHints)
 }
 
 // GET /
@@ -98,7 +125,11 @@ func (app *WebApp) formPage(c *gin.Context) {
 
 // POST /generatePDF  (htmx request)
 func (app *WebApp) generatePDF(c *gin.Context) {
-	req := extractRequestFromForm(c)
+	req, err := extractRequestFromForm(c)
+	if err != nil {
+		c.String(http.StatusBadRequest, "%v", err)
+		return
+	}
 
 	// 2️⃣  Call gRPC → PDF
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 90*time.Second)
@@ -110,6 +141,20 @@ func (app *WebApp) generatePDF(c *gin.Context) {
 		return
 	}
 	fmt.Printf("Generated %d problems\n", len(problemResp.Problems))
+	if second := strings.TrimSpace(c.PostForm("secondLanguage")); second != "" && pg.NormalizeLanguage(second) != pg.NormalizeLanguage(req.Language) {
+		// Bilingual worksheet: the same problems, translated, side by side
+		tctx, cancel := context.WithTimeout(c.Request.Context(), 90*time.Second)
+		defer cancel()
+		problemResp, err = app.GRPCClient.TranslateProblemSet(tctx, &pb.TranslateRequest{
+			ProblemSet:   problemResp,
+			Language:     second,
+			DualLanguage: true,
+		})
+		if err != nil {
+			c.String(http.StatusInternalServerError, "gRPC error: %v", err)
+			return
+		}
+	}
 	pdfResp, err := app.GRPCClient.GenerateProblemSetPDF(ctx, problemResp)
 	if err != nil {
 		fmt.Printf("Failed to generate Problems %v\n", err)
@@ -136,14 +181,21 @@ func (app *WebApp) generatePDF(c *gin.Context) {
 
 	// 4️⃣  Return an HTML snippet (htmx swaps it into #status)
 	c.HTML(http.StatusOK, "snippet_success.tmpl", gin.H{
-		"ID":       id,
-		"Filename": pdfResp.Filename,
+		"ID":           id,
+		"Filename":     pdfResp.Filename,
+		"Issues":       problemResp.Issues,
+		"Coverage":     problemResp.Coverage,
+		"ProvenanceID": problemResp.Id,
 	})
 }
 
 // POST generateInteractive
 func (app *WebApp) generateInteractive(c *gin.Context) {
-	req := extractRequestFromForm(c)
+	req, err := extractRequestFromForm(c)
+	if err != nil {
+		c.String(http.StatusBadRequest, "%v", err)
+		return
+	}
 
 	// 2️⃣  Call gRPC → PDF
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 90*time.Second)
@@ -162,6 +214,12 @@ func (app *WebApp) generateInteractive(c *gin.Context) {
 		"Problems":  problemResp.Problems, // slice of {Text, Answer}
 		"Student":   req.Name,
 		"Operation": req.Operation,
+		"Lang":      pg.NormalizeLanguage(req.Language),
+		"Labels":    pg.Labels(req.Language),
+		"Issues":    problemResp.Issues,
+		"Coverage":  problemResp.Coverage,
+
+		"ProvenanceID": problemResp.Id,
 	})
 }
 
@@ -178,15 +236,82 @@ func (app *WebApp) downloadPDF(c *gin.Context) {
 	c.FileAttachment(filePath, filepath.Base(filePath)[37:]) // strips UUID_
 }
 
+// POST /hints  (hint usage from an interactive worksheet, as JSON)
+func (app *WebApp) recordHints(c *gin.Context) {
+	var u pg.HintUsage
+	if err := c.ShouldBindJSON(&u); err != nil {
+		c.String(http.StatusBadRequest, "%v", err)
+		return
+	}
+	u.Time = time.Now()
+	if app.hints == nil {
+		log.Printf("hints: %s used %+v", u.Student, u.Problems)
+	} else if err := app.hints.Log(u); err != nil {
+		c.String(http.StatusInternalServerError, "hint log: %v", err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GET /provenance/:id  (the set's record, as JSON)
+func (app *WebApp) provenance(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	rec, err := app.GRPCClient.GetProvenance(ctx, &pb.ProvenanceRequest{Id: c.Param("id")})
+	if err != nil {
+		c.String(httpStatus(err), "gRPC error: %v", err)
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", []byte(rec.Json))
+}
+
+// GET /provenance/:id/pdf  (the set of a record, rendered again)
+func (app *WebApp) renderAgain(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 90*time.Second)
+	defer cancel()
+
+	rec, err := app.GRPCClient.GetProvenance(ctx, &pb.ProvenanceRequest{Id: c.Param("id")})
+	if err != nil {
+		c.String(httpStatus(err), "gRPC error: %v", err)
+		return
+	}
+	if rec.ProblemSet == nil {
+		c.String(http.StatusNotFound, "no problem set came out of %s", rec.Id)
+		return
+	}
+	pdfResp, err := app.GRPCClient.GenerateProblemSetPDF(ctx, rec.ProblemSet)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "gRPC error: %v", err)
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", rec.Id+".pdf"))
+	c.Data(http.StatusOK, "application/pdf", pdfResp.Pdf)
+}
+
 /*
 	Helpers
 */
+// httpStatus maps a gRPC error to the HTTP status to answer with.
+func httpStatus(err error) int {
+	switch status.Code(err) {
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.InvalidArgument:
+		return http.StatusBadRequest
+	case codes.FailedPrecondition:
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
+
 // extractRequestFromForm
 
-func extractRequestFromForm(c *gin.Context) *pb.GenerateRequest {
+func extractRequestFromForm(c *gin.Context) (*pb.GenerateRequest, error) {
 	// 1️⃣  Pull values from the HTML form
 	name := strings.TrimSpace(c.PostForm("name"))
-	gender := strings.TrimSpace(c.PostForm("gender"))
+	pronouns := strings.TrimSpace(c.PostForm("pronouns"))     // she/her | he/him | they/them
+	gender := strings.TrimSpace(c.PostForm("gender"))         // older forms
 	operation := strings.TrimSpace(c.PostForm("operation"))   // e.g. add, subtract…
 	numProblems, _ := strconv.Atoi(c.PostForm("numProblems")) // default to 10
 	if numProblems <= 0 {
@@ -194,6 +319,9 @@ func extractRequestFromForm(c *gin.Context) *pb.GenerateRequest {
 	}
 
 	gradeLevel := strings.TrimSpace(c.PostForm("gradeLevel"))
+	kind := strings.TrimSpace(c.PostForm("kind"))               // arithmetic | measurement | comparison | …
+	unitSystem := strings.TrimSpace(c.PostForm("unitSystem"))   // metric | imperial
+	balance := strings.TrimSpace(c.PostForm("interestBalance")) // round_robin | weighted
 
 	likesNouns := splitCSV(c.PostForm("likesNouns")) // helper below
 	likesVerbs := splitCSV(c.PostForm("likesVerbs"))
@@ -203,13 +331,33 @@ func extractRequestFromForm(c *gin.Context) *pb.GenerateRequest {
 	req := &pb.GenerateRequest{
 		Name:        name,
 		Gender:      gender,
+		Pronouns:    pronouns,
 		Operation:   operation,
 		NumProblems: int32(numProblems),
 		GradeLevel:  gradeLevel,
 		LikesNouns:  likesNouns,
 		LikesVerbs:  likesVerbs,
+		Kind:        kind,
+		UnitSystem:  unitSystem,
+
+		InterestBalance: balance,
+		Arrange:         strings.TrimSpace(c.PostForm("arrange")),     // interleaved | grouped | shuffled
+		Progression:     strings.TrimSpace(c.PostForm("progression")), // ramp | uniform | spiral
+		Regrouping:      strings.TrimSpace(c.PostForm("regrouping")),  // none | some | all | places
+		Language:        strings.TrimSpace(c.PostForm("language")),    // en | es | fr | vi
+		Agent:           strings.TrimSpace(c.PostForm("agent")),       // json | csv | templates; empty is the server's
+	}
+	if strings.EqualFold(operation, pg.OpMixed) {
+		shares, err := pg.ParseOperationShares(c.PostForm("operationMix"))
+		if err != nil {
+			return nil, fmt.Errorf("operation mix: %v", err)
+		}
+		if len(shares) == 0 {
+			return nil, fmt.Errorf("operation mix: list the operations to mix, e.g. \"addition 6, subtraction 4\"")
+		}
+		req.Operations = shares
 	}
-	return req
+	return req, nil
 }
 
 // splitCSV turns "cat,  dog,fish " → []string{"cat","dog","fish"}